@@ -0,0 +1,114 @@
+package webapiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// maxErrorBodySize is the maximum number of response body bytes buffered in an APIError.
+const maxErrorBodySize = 64 << 10
+
+// ErrUnexpectedStatusCode is matched by the errors of responses with an unexpected status code.
+var ErrUnexpectedStatusCode = errors.New("unexpected status code")
+
+// ErrUnexpectedContentType is matched by the errors of responses with an unexpected content type.
+var ErrUnexpectedContentType = errors.New("unexpected content type")
+
+// APIError is returned, classified as CategoryHTTPStatus, for responses with an unexpected status code.
+type APIError struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Headers are the headers of the response.
+	Headers http.Header
+	// Body is the beginning of the response body, up to 64 KiB.
+	Body []byte
+	// Method is the method of the request.
+	Method string
+	// URL is the URL of the request, with credentials and sensitive query parameters redacted.
+	URL string
+	// Detail is the Request.ErrorInto the body was unmarshaled into, or nil.
+	Detail any
+	// Err is the error decoded from the body, by Request.ErrorInto when it implements error,
+	// otherwise by an error decoder registered with WithErrorDecoder, or nil.
+	Err error
+}
+
+// Error returns a message with the status code, followed by the decoded error when there is one.
+func (e *APIError) Error() string {
+	message := "unexpected status code: " + strconv.Itoa(e.StatusCode)
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+
+	return message
+}
+
+// Unwrap returns the decoded error.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrUnexpectedStatusCode.
+func (e *APIError) Is(target error) bool {
+	return target == ErrUnexpectedStatusCode //nolint:errorlint
+}
+
+// ContentTypeError is returned, classified as CategoryValidation, for responses with an unexpected content type.
+type ContentTypeError struct {
+	// ContentType is the Content-Type of the response.
+	ContentType string
+}
+
+// Error returns a message with the content type.
+func (e *ContentTypeError) Error() string {
+	return "unexpected content type: " + e.ContentType
+}
+
+// Is reports whether target is ErrUnexpectedContentType.
+func (e *ContentTypeError) Is(target error) bool {
+	return target == ErrUnexpectedContentType //nolint:errorlint
+}
+
+// newAPIError buffers the beginning of the body of httpResponse into an APIError.
+// The bytes read are put back in front of the body, which may still be returned to the caller.
+func (c *client) newAPIError(httpRequest *http.Request, httpResponse *http.Response, request *Request) *APIError {
+	apiError := &APIError{
+		StatusCode: httpResponse.StatusCode,
+		Headers:    httpResponse.Header.Clone(),
+		Method:     httpRequest.Method,
+		URL:        sanitizeURL(httpRequest.URL),
+	}
+
+	var consumed bytes.Buffer
+
+	body, _, err := readPooled(io.TeeReader(httpResponse.Body, &consumed), maxErrorBodySize)
+	httpResponse.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(&consumed, httpResponse.Body), httpResponse.Body}
+
+	if err != nil {
+		return apiError
+	}
+
+	apiError.Body = []byte(body)
+
+	if request.ErrorInto != nil && json.Unmarshal(apiError.Body, request.ErrorInto) == nil {
+		apiError.Detail = request.ErrorInto
+
+		if detail, ok := request.ErrorInto.(error); ok {
+			apiError.Err = detail
+
+			return apiError
+		}
+	}
+
+	apiError.Err = c.decodeError(httpResponse.StatusCode, httpResponse.Header.Get("Content-Type"), apiError.Body)
+
+	return apiError
+}
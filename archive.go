@@ -0,0 +1,126 @@
+package webapiclient
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxZipArchiveSize is the number of bytes of a zip archive buffered by EachArchiveEntry.
+const maxZipArchiveSize = 256 << 20
+
+// ArchiveEntry is an entry of an archive response.
+type ArchiveEntry struct {
+	// Name is the path of the entry inside the archive, as stored; it is not sanitized.
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+	// Body is the content of the entry, valid only until the callback returns.
+	Body io.Reader
+}
+
+// EachArchiveEntry calls fn for every entry of a tar, gzip-compressed tar or zip archive response in order,
+// detecting the format from the content. Tar archives are streamed without buffering;
+// zip archives are buffered in memory because their directory is stored at the end, and fail with a
+// *BodyTooLargeError past 256 MiB or the limit set by WithMaxResponseBodySize, whichever is lower.
+func EachArchiveEntry(response *Response, fn func(entry *ArchiveEntry) error) error {
+	reader := bufio.NewReader(response.Body)
+
+	magic, _ := reader.Peek(4) //nolint:mnd
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")) || bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		return eachZipEntry(reader, maxZipArchiveSize, fn)
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return errors.WithStack(newError(CategoryDecode, err))
+		}
+
+		defer func() {
+			_ = gzipReader.Close()
+		}()
+
+		return eachTarEntry(gzipReader, fn)
+	default:
+		return eachTarEntry(reader, fn)
+	}
+}
+
+func eachTarEntry(reader io.Reader, fn func(entry *ArchiveEntry) error) error {
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.WithStack(newError(CategoryDecode, err))
+		}
+
+		err = fn(&ArchiveEntry{
+			Name:    header.Name,
+			Size:    header.Size,
+			Mode:    header.FileInfo().Mode(),
+			ModTime: header.ModTime,
+			Body:    tarReader,
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
+
+func eachZipEntry(reader io.Reader, limit int64, fn func(entry *ArchiveEntry) error) error {
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return errors.WithStack(classifyTransportError(err))
+	}
+
+	if int64(len(body)) > limit {
+		return errors.WithStack(newError(CategoryValidation, &BodyTooLargeError{Limit: limit}))
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return errors.WithStack(newError(CategoryDecode, err))
+	}
+
+	for _, file := range zipReader.File {
+		err := eachZipFile(file, fn)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func eachZipFile(file *zip.File, fn func(entry *ArchiveEntry) error) error {
+	body, err := file.Open()
+	if err != nil {
+		return errors.WithStack(newError(CategoryDecode, err))
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	return errors.WithStack(fn(&ArchiveEntry{
+		Name:    file.Name,
+		Size:    int64(file.UncompressedSize64), //nolint:gosec
+		Mode:    file.Mode(),
+		ModTime: file.Modified,
+		Body:    body,
+	}))
+}
@@ -0,0 +1,147 @@
+package webapiclient
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEachArchiveEntry(t *testing.T) {
+	t.Parallel()
+
+	files := []struct{ name, content string }{
+		{name: "a.txt", content: "alpha"},
+		{name: "dir/b.txt", content: "bravo"},
+	}
+
+	newTar := func(t *testing.T) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+		writer := tar.NewWriter(&buf)
+		for _, file := range files {
+			require.NoError(t, writer.WriteHeader(&tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.content))}))
+			_, err := writer.Write([]byte(file.content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		return buf.Bytes()
+	}
+
+	newTarGzip := func(t *testing.T) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		_, err := writer.Write(newTar(t))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		return buf.Bytes()
+	}
+
+	newZip := func(t *testing.T) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+		writer := zip.NewWriter(&buf)
+		for _, file := range files {
+			w, err := writer.Create(file.name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(file.content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		archive func(t *testing.T) []byte
+	}{
+		{name: "success: tar", archive: newTar},
+		{name: "success: tar.gz", archive: newTarGzip},
+		{name: "success: zip", archive: newZip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response := &Response{Body: io.NopCloser(bytes.NewReader(tt.archive(t)))}
+
+			got := map[string]string{}
+			err := EachArchiveEntry(response, func(entry *ArchiveEntry) error {
+				content, err := io.ReadAll(entry.Body)
+				if err != nil {
+					return err
+				}
+
+				assert.Equal(t, int64(len(content)), entry.Size)
+				got[entry.Name] = string(content)
+
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"a.txt": "alpha", "dir/b.txt": "bravo"}, got)
+		})
+	}
+
+	t.Run("failure: callback error", func(t *testing.T) {
+		t.Parallel()
+
+		errStop := errors.New("stop")
+		response := &Response{Body: io.NopCloser(bytes.NewReader(newTar(t)))}
+
+		calls := 0
+		err := EachArchiveEntry(response, func(_ *ArchiveEntry) error {
+			calls++
+
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("failure: zip larger than the limit", func(t *testing.T) {
+		t.Parallel()
+
+		err := eachZipEntry(bytes.NewReader(newZip(t)), 16, func(_ *ArchiveEntry) error {
+			return nil
+		})
+		require.ErrorIs(t, err, ErrBodyTooLarge)
+		assert.Equal(t, CategoryValidation, ClassifyError(err))
+	})
+
+	t.Run("failure: zip larger than the response body limit", func(t *testing.T) {
+		t.Parallel()
+
+		response := &Response{Body: &limitedBody{body: io.NopCloser(bytes.NewReader(newZip(t))), remaining: 16, limit: 16}}
+
+		err := EachArchiveEntry(response, func(_ *ArchiveEntry) error {
+			return nil
+		})
+		require.ErrorIs(t, err, ErrBodyTooLarge)
+		assert.Equal(t, CategoryValidation, ClassifyError(err))
+	})
+
+	t.Run("failure: malformed archive", func(t *testing.T) {
+		t.Parallel()
+
+		response := &Response{Body: io.NopCloser(bytes.NewReader([]byte("not an archive, just some text that is long enough")))}
+
+		err := EachArchiveEntry(response, func(_ *ArchiveEntry) error {
+			return nil
+		})
+		require.Error(t, err)
+		assert.Equal(t, CategoryDecode, ClassifyError(err))
+	})
+}
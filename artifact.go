@@ -0,0 +1,250 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxArtifactBodySize is the maximum number of response body bytes kept in a failure artifact.
+const maxArtifactBodySize = 64 << 10
+
+// redactedValue replaces the values of sensitive headers and query parameters in failure artifacts.
+const redactedValue = "REDACTED"
+
+// Compile-time check to ensure DirArtifactSink implements ArtifactSink interface.
+var _ ArtifactSink = (*DirArtifactSink)(nil)
+
+// sensitiveHeaders are the headers whose values are redacted in failure artifacts.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// sensitiveQueryParams are the query parameters whose values are redacted in failure artifacts.
+var sensitiveQueryParams = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"key",
+	"password",
+	"secret",
+	"signature",
+	"token",
+}
+
+// Artifact is the evidence captured for a response rejected by the client.
+type Artifact struct {
+	ID              string              `json:"id"`
+	Time            time.Time           `json:"time"`
+	Error           string              `json:"error"`
+	Method          string              `json:"method"`
+	URL             string              `json:"url"`
+	RequestHeaders  map[string][]string `json:"request_headers"`  //nolint:tagliatelle
+	StatusCode      int                 `json:"status_code"`      //nolint:tagliatelle
+	ResponseHeaders map[string][]string `json:"response_headers"` //nolint:tagliatelle
+	Body            string              `json:"body"`
+	// BodyTruncated reports whether Body holds only the first part of the response body.
+	BodyTruncated bool `json:"body_truncated"` //nolint:tagliatelle
+	// Meta is the call metadata of the request restricted to the allowlist of WithCallMetaAllowlist.
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// ArtifactRedaction extends the redaction of failure artifacts beyond the built-in sensitive headers
+// and query parameters.
+type ArtifactRedaction struct {
+	// Headers are the names of additional headers whose values are redacted, such as the header of a KeyRotation.
+	// A name ending in "*" matches every header starting with the rest of it, for example "X-Auth-*".
+	Headers []string
+	// QueryParams are the names of additional query parameters whose values are redacted, ignoring case.
+	QueryParams []string
+	// Body, when set, returns the response body to store in the artifact in place of body,
+	// for example with echoed tokens masked.
+	Body func(body string) string
+}
+
+// WithArtifactRedaction redacts the headers, query parameters and body content described by redaction
+// from failure artifacts, in addition to the built-in sensitive headers and query parameters.
+func WithArtifactRedaction(redaction ArtifactRedaction) Option {
+	return func(c *client) {
+		c.artifactRedaction = redaction
+	}
+}
+
+// ArtifactSink persists failure artifacts.
+type ArtifactSink interface {
+	// SaveArtifact persists artifact, which is identified by its ID.
+	SaveArtifact(ctx context.Context, artifact *Artifact) error
+}
+
+// WithArtifactSink makes the client capture a sanitized artifact of responses rejected by validation
+// and save it to sink. The reference ID of the artifact is included in the returned error.
+func WithArtifactSink(sink ArtifactSink) Option {
+	return func(c *client) {
+		c.artifactSink = sink
+	}
+}
+
+// DirArtifactSink is an ArtifactSink writing each artifact as a JSON file named after its ID.
+type DirArtifactSink struct {
+	dir string
+}
+
+// NewDirArtifactSink creates a new DirArtifactSink writing to dir, which must exist.
+func NewDirArtifactSink(dir string) *DirArtifactSink {
+	return &DirArtifactSink{
+		dir: dir,
+	}
+}
+
+// SaveArtifact writes artifact to <dir>/<id>.json.
+func (s *DirArtifactSink) SaveArtifact(_ context.Context, artifact *Artifact) error {
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = os.WriteFile(filepath.Join(s.dir, filepath.Base(artifact.ID)+".json"), data, 0o600) //nolint:mnd
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// captureArtifact saves an artifact of httpResponse when an ArtifactSink is configured and
+// returns err annotated with its reference ID. err is returned unchanged when nothing was saved.
+func (c *client) captureArtifact(httpRequest *http.Request, httpResponse *http.Response, err error) error {
+	var clientError *Error
+	if c.artifactSink == nil || !c.enabled(httpRequest.Context(), FlagArtifactCapture) || !errors.As(err, &clientError) {
+		return err
+	}
+
+	// The bytes read are put back in front of the body, which may still be returned to the caller.
+	var consumed bytes.Buffer
+
+	body, truncated, readErr := readPooled(io.TeeReader(httpResponse.Body, &consumed), maxArtifactBodySize)
+	httpResponse.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(&consumed, httpResponse.Body), httpResponse.Body}
+
+	id, idErr := newArtifactID(time.Now())
+	if idErr != nil {
+		return err
+	}
+
+	redaction := c.artifactRedaction
+	if redaction.Body != nil {
+		body = redaction.Body(body)
+	}
+
+	artifact := &Artifact{
+		ID:              id,
+		Time:            time.Now(),
+		Error:           err.Error(),
+		Method:          httpRequest.Method,
+		URL:             redactURL(httpRequest.URL, redaction.QueryParams),
+		RequestHeaders:  sanitizeHeaders(httpRequest.Header, redaction.Headers),
+		StatusCode:      httpResponse.StatusCode,
+		ResponseHeaders: sanitizeHeaders(httpResponse.Header, redaction.Headers),
+		Body:            body,
+		BodyTruncated:   truncated || readErr != nil,
+		Meta:            allowedCallMeta(httpRequest.Context(), c.callMetaAllowlist),
+	}
+
+	saveErr := c.artifactSink.SaveArtifact(context.WithoutCancel(httpRequest.Context()), artifact)
+	if saveErr != nil {
+		return err
+	}
+
+	annotated := *clientError
+	annotated.artifactID = id
+
+	return &annotated
+}
+
+// newArtifactID returns a unique, time-ordered artifact reference ID.
+func newArtifactID(now time.Time) (string, error) {
+	random := make([]byte, 8) //nolint:mnd
+
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(random), nil
+}
+
+// sanitizeHeaders returns a copy of header with the values of the sensitive headers and of the extra ones redacted.
+func sanitizeHeaders(header http.Header, extra []string) map[string][]string {
+	sanitized := header.Clone()
+	if sanitized == nil {
+		return map[string][]string{}
+	}
+
+	for name := range sanitized {
+		if isSensitiveHeader(name, extra) {
+			sanitized[name] = []string{redactedValue}
+		}
+	}
+
+	return sanitized
+}
+
+func isSensitiveHeader(name string, extra []string) bool {
+	name = http.CanonicalHeaderKey(name)
+
+	if slices.Contains(sensitiveHeaders, name) {
+		return true
+	}
+
+	for _, sensitive := range extra {
+		prefix, wildcard := strings.CutSuffix(sensitive, "*")
+		if wildcard && strings.HasPrefix(name, http.CanonicalHeaderKey(prefix)) ||
+			!wildcard && name == http.CanonicalHeaderKey(sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sanitizeURL(requestURL *url.URL) string {
+	return redactURL(requestURL, nil)
+}
+
+// redactURL returns requestURL without user information and with the values of the sensitive query parameters
+// and of the extra ones redacted.
+func redactURL(requestURL *url.URL, extra []string) string {
+	sanitized := *requestURL
+	sanitized.User = nil
+
+	query := sanitized.Query()
+	for name := range query {
+		for _, sensitive := range slices.Concat(sensitiveQueryParams, extra) {
+			if strings.EqualFold(name, sensitive) {
+				query[name] = []string{redactedValue}
+			}
+		}
+	}
+
+	sanitized.RawQuery = query.Encode()
+
+	return sanitized.String()
+}
@@ -0,0 +1,124 @@
+// Package backoff provides the exponential backoff calculations used by the retries of webapiclient,
+// for retry loops outside the client that must share the same timing behavior.
+package backoff
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// defaultMultiplier is the multiplier used when Policy.Multiplier is not greater than one.
+const defaultMultiplier = 2
+
+// decorrelatedMultiplier bounds a decorrelated jitter delay by this many times the previous delay.
+const decorrelatedMultiplier = 3
+
+// Jitter selects how a delay is randomized.
+type Jitter int
+
+const (
+	// NoJitter uses the exponential delay as is.
+	NoJitter Jitter = iota
+	// FullJitter picks a delay uniformly between zero and the exponential delay.
+	FullJitter
+	// EqualJitter keeps half of the exponential delay and picks the other half uniformly.
+	EqualJitter
+	// DecorrelatedJitter picks a delay uniformly between the initial delay and three times the previous delay.
+	DecorrelatedJitter
+)
+
+// Policy describes an exponential backoff.
+type Policy struct {
+	// Initial is the delay after the first attempt.
+	Initial time.Duration
+	// Max caps the delay; zero means no cap.
+	Max time.Duration
+	// Multiplier is the factor applied to the delay after every attempt; values not greater than one mean 2.
+	// It is not used by DecorrelatedJitter.
+	Multiplier float64
+	// Jitter selects how the delay is randomized.
+	Jitter Jitter
+	// Rand returns a pseudo-random number in [0.0, 1.0); nil means math/rand/v2.Float64.
+	Rand func() float64
+}
+
+// Exponential returns the delay after the attempt-th attempt, counted from zero, without jitter.
+func (p Policy) Exponential(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 1 {
+		multiplier = defaultMultiplier
+	}
+
+	return p.capped(float64(p.Initial) * math.Pow(multiplier, float64(attempt)))
+}
+
+// Delay returns the delay after the attempt-th attempt, counted from zero. previous is the delay returned
+// for the attempt before, which is only used by DecorrelatedJitter; zero means the initial delay.
+func (p Policy) Delay(attempt int, previous time.Duration) time.Duration {
+	switch p.Jitter {
+	case FullJitter:
+		return p.capped(p.random() * float64(p.Exponential(attempt)))
+	case EqualJitter:
+		half := float64(p.Exponential(attempt)) / 2 //nolint:mnd
+
+		return p.capped(half + p.random()*half)
+	case DecorrelatedJitter:
+		upper := float64(max(previous, p.Initial)) * decorrelatedMultiplier
+
+		return p.capped(float64(p.Initial) + p.random()*(upper-float64(p.Initial)))
+	case NoJitter:
+	}
+
+	return p.Exponential(attempt)
+}
+
+func (p Policy) random() float64 {
+	if p.Rand == nil {
+		return rand.Float64() //nolint:gosec
+	}
+
+	return p.Rand()
+}
+
+// capped converts delay to a duration no greater than Max.
+func (p Policy) capped(delay float64) time.Duration {
+	if p.Max > 0 && delay > float64(p.Max) {
+		return p.Max
+	}
+
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(delay)
+}
+
+// Backoff yields the successive delays of a Policy for a retry loop. It is not safe for concurrent use.
+type Backoff struct {
+	policy   Policy
+	attempt  int
+	previous time.Duration
+}
+
+// New creates a new Backoff following policy.
+func New(policy Policy) *Backoff {
+	return &Backoff{
+		policy: policy,
+	}
+}
+
+// Next returns the delay before the next attempt.
+func (b *Backoff) Next() time.Duration {
+	delay := b.policy.Delay(b.attempt, b.previous)
+	b.attempt++
+	b.previous = delay
+
+	return delay
+}
+
+// Reset makes the next delay the one after the first attempt again.
+func (b *Backoff) Reset() {
+	b.attempt = 0
+	b.previous = 0
+}
@@ -0,0 +1,174 @@
+package backoff
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedRand(value float64) func() float64 {
+	return func() float64 {
+		return value
+	}
+}
+
+func TestPolicy_Exponential(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		attempt int
+		want    time.Duration
+	}{
+		{
+			name:    "success: first attempt",
+			policy:  Policy{Initial: 100 * time.Millisecond},
+			attempt: 0,
+			want:    100 * time.Millisecond,
+		},
+		{
+			name:    "success: default multiplier",
+			policy:  Policy{Initial: 100 * time.Millisecond},
+			attempt: 3,
+			want:    800 * time.Millisecond,
+		},
+		{
+			name:    "success: custom multiplier",
+			policy:  Policy{Initial: 100 * time.Millisecond, Multiplier: 1.5},
+			attempt: 2,
+			want:    225 * time.Millisecond,
+		},
+		{
+			name:    "success: capped",
+			policy:  Policy{Initial: time.Second, Max: 5 * time.Second},
+			attempt: 10,
+			want:    5 * time.Second,
+		},
+		{
+			name:    "success: overflow",
+			policy:  Policy{Initial: time.Second},
+			attempt: 100,
+			want:    time.Duration(math.MaxInt64),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.policy.Exponential(tt.attempt))
+		})
+	}
+}
+
+func TestPolicy_Delay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		attempt  int
+		previous time.Duration
+		want     time.Duration
+	}{
+		{
+			name:    "success: no jitter",
+			policy:  Policy{Initial: 100 * time.Millisecond, Rand: fixedRand(0.5)},
+			attempt: 2,
+			want:    400 * time.Millisecond,
+		},
+		{
+			name:    "success: full jitter",
+			policy:  Policy{Initial: 100 * time.Millisecond, Jitter: FullJitter, Rand: fixedRand(0.25)},
+			attempt: 2,
+			want:    100 * time.Millisecond,
+		},
+		{
+			name:    "success: full jitter lower bound",
+			policy:  Policy{Initial: 100 * time.Millisecond, Jitter: FullJitter, Rand: fixedRand(0)},
+			attempt: 2,
+			want:    0,
+		},
+		{
+			name:    "success: equal jitter",
+			policy:  Policy{Initial: 100 * time.Millisecond, Jitter: EqualJitter, Rand: fixedRand(0.5)},
+			attempt: 2,
+			want:    300 * time.Millisecond,
+		},
+		{
+			name:    "success: equal jitter lower bound",
+			policy:  Policy{Initial: 100 * time.Millisecond, Jitter: EqualJitter, Rand: fixedRand(0)},
+			attempt: 2,
+			want:    200 * time.Millisecond,
+		},
+		{
+			name:     "success: decorrelated jitter",
+			policy:   Policy{Initial: 100 * time.Millisecond, Jitter: DecorrelatedJitter, Rand: fixedRand(0.5)},
+			attempt:  3,
+			previous: 300 * time.Millisecond,
+			want:     500 * time.Millisecond,
+		},
+		{
+			name:    "success: decorrelated jitter without previous delay",
+			policy:  Policy{Initial: 100 * time.Millisecond, Jitter: DecorrelatedJitter, Rand: fixedRand(0.5)},
+			attempt: 0,
+			want:    200 * time.Millisecond,
+		},
+		{
+			name:     "success: decorrelated jitter capped",
+			policy:   Policy{Initial: 100 * time.Millisecond, Max: time.Second, Jitter: DecorrelatedJitter, Rand: fixedRand(0.9)},
+			attempt:  5,
+			previous: time.Second,
+			want:     time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.policy.Delay(tt.attempt, tt.previous))
+		})
+	}
+}
+
+func TestPolicy_Delay_DefaultRand(t *testing.T) {
+	t.Parallel()
+
+	policy := Policy{Initial: 100 * time.Millisecond, Jitter: FullJitter}
+
+	for range 100 {
+		delay := policy.Delay(3, 0)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, 800*time.Millisecond)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: exponential", func(t *testing.T) {
+		t.Parallel()
+
+		b := New(Policy{Initial: 100 * time.Millisecond, Max: 500 * time.Millisecond})
+
+		got := []time.Duration{b.Next(), b.Next(), b.Next(), b.Next()}
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond}, got)
+
+		b.Reset()
+		assert.Equal(t, 100*time.Millisecond, b.Next())
+	})
+
+	t.Run("success: decorrelated jitter uses the previous delay", func(t *testing.T) {
+		t.Parallel()
+
+		b := New(Policy{Initial: 100 * time.Millisecond, Jitter: DecorrelatedJitter, Rand: fixedRand(0.5)})
+
+		got := []time.Duration{b.Next(), b.Next(), b.Next()}
+		assert.Equal(t, []time.Duration{200 * time.Millisecond, 350 * time.Millisecond, 575 * time.Millisecond}, got)
+
+		b.Reset()
+		assert.Equal(t, 200*time.Millisecond, b.Next())
+	})
+}
@@ -0,0 +1,157 @@
+package webapiclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BatchFunc sends items as a single batch call and returns one result per item, in the same order.
+type BatchFunc[T any, R any] func(ctx context.Context, items []T) ([]R, error)
+
+type batchResult[R any] struct {
+	value R
+	err   error
+}
+
+type batchItem[T any, R any] struct {
+	ctx    context.Context //nolint:containedctx
+	item   T
+	result chan batchResult[R]
+}
+
+// batch is a set of items sent with a single batch call. When results are delivered in order,
+// the results of a batch are delivered after previous is closed, and then done is closed.
+type batch[T any, R any] struct {
+	items    []batchItem[T, R]
+	previous <-chan struct{}
+	done     chan struct{}
+}
+
+// Batcher coalesces individual writes into batch calls, flushing when maxSize items are buffered
+// or maxDelay has elapsed since the first buffered item, and maps each result back to its caller.
+type Batcher[T any, R any] struct {
+	send     BatchFunc[T, R]
+	maxSize  int
+	maxDelay time.Duration
+
+	mu        sync.Mutex
+	pending   []batchItem[T, R]
+	timer     *time.Timer
+	ordered   bool
+	lastBatch chan struct{}
+}
+
+// NewBatcher creates a new Batcher sending batches of up to maxSize items with send.
+func NewBatcher[T any, R any](send BatchFunc[T, R], maxSize int, maxDelay time.Duration) *Batcher[T, R] {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+
+	return &Batcher[T, R]{
+		send:     send,
+		maxSize:  maxSize,
+		maxDelay: maxDelay,
+	}
+}
+
+// SetOrdered sets whether results are delivered in submission order. Batches are still sent concurrently,
+// but the results of a batch are held back until the results of every earlier batch have been delivered,
+// so that the result of a later Submit is never available before that of an earlier one.
+// It must be called before the first Submit.
+func (b *Batcher[T, R]) SetOrdered(ordered bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.ordered = ordered
+}
+
+// Submit buffers item and waits for its result.
+// When ctx is done first, Submit returns the context error but the item may still be sent.
+func (b *Batcher[T, R]) Submit(ctx context.Context, item T) (R, error) {
+	result := make(chan batchResult[R], 1)
+
+	b.mu.Lock()
+	b.pending = append(b.pending, batchItem[T, R]{ctx: ctx, item: item, result: result})
+
+	switch {
+	case len(b.pending) >= b.maxSize:
+		go b.sendBatch(b.takeLocked())
+	case len(b.pending) == 1:
+		b.timer = time.AfterFunc(b.maxDelay, b.Flush)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		var zero R
+
+		return zero, errors.WithStack(ctx.Err())
+	case r := <-result:
+		return r.value, r.err
+	}
+}
+
+// Flush sends the buffered items immediately.
+func (b *Batcher[T, R]) Flush() {
+	b.mu.Lock()
+	taken := b.takeLocked()
+	b.mu.Unlock()
+
+	if len(taken.items) > 0 {
+		b.sendBatch(taken)
+	}
+}
+
+func (b *Batcher[T, R]) takeLocked() batch[T, R] {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	taken := batch[T, R]{items: b.pending}
+	b.pending = nil
+
+	if b.ordered && len(taken.items) > 0 {
+		taken.previous = b.lastBatch
+		taken.done = make(chan struct{})
+		b.lastBatch = taken.done
+	}
+
+	return taken
+}
+
+func (b *Batcher[T, R]) sendBatch(taken batch[T, R]) {
+	items := taken.items
+
+	values := make([]T, 0, len(items))
+	for _, item := range items {
+		values = append(values, item.item)
+	}
+
+	// The batch outlives the callers that may give up waiting, so only the values of the first context are kept.
+	results, err := b.send(context.WithoutCancel(items[0].ctx), values)
+	if err == nil && len(results) != len(items) {
+		err = errors.Errorf("batch returned %d results for %d items", len(results), len(items))
+	}
+
+	if taken.previous != nil {
+		<-taken.previous
+	}
+
+	if taken.done != nil {
+		defer close(taken.done)
+	}
+
+	for i, item := range items {
+		if err != nil {
+			item.result <- batchResult[R]{err: errors.WithStack(err)}
+
+			continue
+		}
+
+		item.result <- batchResult[R]{value: results[i]}
+	}
+}
@@ -0,0 +1,165 @@
+package webapiclient
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatcher_Submit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: flushed by size", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var batches [][]int
+		batcher := NewBatcher(func(ctx context.Context, items []int) ([]string, error) {
+			mu.Lock()
+			batches = append(batches, items)
+			mu.Unlock()
+
+			results := make([]string, 0, len(items))
+			for _, item := range items {
+				results = append(results, "item-"+strconv.Itoa(item))
+			}
+			return results, nil
+		}, 3, time.Hour)
+
+		var wg sync.WaitGroup
+		got := make([]string, 3)
+		for i := range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				result, err := batcher.Submit(context.Background(), i)
+				assert.NoError(t, err)
+				got[i] = result
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, []string{"item-0", "item-1", "item-2"}, got)
+		require.Len(t, batches, 1)
+		assert.ElementsMatch(t, []int{0, 1, 2}, batches[0])
+	})
+
+	t.Run("success: flushed by delay", func(t *testing.T) {
+		t.Parallel()
+
+		batcher := NewBatcher(func(ctx context.Context, items []int) ([]int, error) {
+			results := make([]int, 0, len(items))
+			for _, item := range items {
+				results = append(results, item*10)
+			}
+			return results, nil
+		}, 100, 10*time.Millisecond)
+
+		got, err := batcher.Submit(context.Background(), 4)
+		require.NoError(t, err)
+		assert.Equal(t, 40, got)
+	})
+
+	t.Run("failure: batch error is returned to every item", func(t *testing.T) {
+		t.Parallel()
+
+		want := errors.New("quota exceeded")
+		batcher := NewBatcher(func(ctx context.Context, items []int) ([]int, error) {
+			return nil, want
+		}, 2, time.Hour)
+
+		var wg sync.WaitGroup
+		for i := range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, err := batcher.Submit(context.Background(), i)
+				assert.ErrorIs(t, err, want)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("failure: result count mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		batcher := NewBatcher(func(ctx context.Context, items []int) ([]int, error) {
+			return []int{}, nil
+		}, 1, time.Hour)
+
+		_, err := batcher.Submit(context.Background(), 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("failure: context canceled while waiting", func(t *testing.T) {
+		t.Parallel()
+
+		batcher := NewBatcher(func(ctx context.Context, items []int) ([]int, error) {
+			return items, nil
+		}, 100, time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := batcher.Submit(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+		batcher.Flush()
+	})
+}
+
+func TestBatcher_SetOrdered(t *testing.T) {
+	t.Parallel()
+
+	for _, ordered := range []bool{true, false} {
+		t.Run("ordered="+strconv.FormatBool(ordered), func(t *testing.T) {
+			t.Parallel()
+
+			sent := make(chan int)
+			release := make(chan struct{})
+			batcher := NewBatcher(func(ctx context.Context, items []int) ([]int, error) {
+				sent <- items[0]
+				if items[0] == 0 {
+					<-release
+				}
+
+				return items, nil
+			}, 1, time.Hour)
+			batcher.SetOrdered(ordered)
+
+			returned := make(chan int, 2)
+			for i := range 2 {
+				go func() {
+					result, err := batcher.Submit(context.Background(), i)
+					assert.NoError(t, err)
+					returned <- result
+				}()
+				assert.Equal(t, i, <-sent)
+			}
+
+			if ordered {
+				select {
+				case result := <-returned:
+					t.Errorf("result %d delivered before the result of an earlier batch", result)
+				case <-time.After(50 * time.Millisecond):
+				}
+
+				close(release)
+				assert.ElementsMatch(t, []int{0, 1}, []int{<-returned, <-returned})
+
+				return
+			}
+
+			assert.Equal(t, 1, <-returned)
+			close(release)
+			assert.Equal(t, 0, <-returned)
+		})
+	}
+}
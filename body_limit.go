@@ -0,0 +1,89 @@
+package webapiclient
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBodyTooLarge is matched by the errors of responses whose body exceeds the configured size limit.
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// BodyTooLargeError is returned, classified as CategoryValidation, when a response declares a Content-Length
+// exceeding the size limit, and by reads of a body going past the limit.
+type BodyTooLargeError struct {
+	// Limit is the maximum number of body bytes allowed.
+	Limit int64
+}
+
+// Error returns a message with the limit.
+func (e *BodyTooLargeError) Error() string {
+	return "response body too large: limit is " + strconv.FormatInt(e.Limit, 10) + " bytes"
+}
+
+// Is reports whether target is ErrBodyTooLarge.
+func (e *BodyTooLargeError) Is(target error) bool {
+	return target == ErrBodyTooLarge //nolint:errorlint
+}
+
+// WithMaxResponseBodySize limits the body of every response to limit bytes, so that a misbehaving server cannot
+// make a caller reading the whole body run out of memory. Request.MaxResponseBodySize overrides it.
+func WithMaxResponseBodySize(limit int64) Option {
+	return func(c *client) {
+		c.maxResponseBodySize = limit
+	}
+}
+
+// limitBody makes the body of httpResponse fail with a *BodyTooLargeError past the limit of request or
+// the client, and returns the error right away when the declared Content-Length exceeds it.
+func (c *client) limitBody(httpResponse *http.Response, request *Request) error {
+	limit := c.maxResponseBodySize
+	if request.MaxResponseBodySize != 0 {
+		limit = request.MaxResponseBodySize
+	}
+
+	if limit <= 0 {
+		return nil
+	}
+
+	if httpResponse.ContentLength > limit {
+		return newError(CategoryValidation, &BodyTooLargeError{Limit: limit})
+	}
+
+	httpResponse.Body = &limitedBody{body: httpResponse.Body, remaining: limit, limit: limit}
+
+	return nil
+}
+
+// limitedBody reads at most limit bytes of body and fails when there are more.
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, &BodyTooLargeError{Limit: b.limit}
+	}
+
+	// One byte more than allowed is read to tell a body of exactly limit bytes from a larger one.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+
+	if b.remaining < 0 {
+		return n + int(b.remaining), &BodyTooLargeError{Limit: b.limit}
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close() //nolint:wrapcheck
+}
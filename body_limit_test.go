@@ -0,0 +1,138 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientImpl_Do_MaxResponseBodySize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		options       []Option
+		request       *Request
+		body          string
+		contentLength int64
+		wantDoErr     bool
+		wantReadErr   bool
+	}{
+		{
+			name:          "success: no limit",
+			request:       &Request{Path: "/"},
+			body:          strings.Repeat("x", 100),
+			contentLength: -1,
+		},
+		{
+			name:          "success: body of exactly the limit",
+			options:       []Option{WithMaxResponseBodySize(100)},
+			request:       &Request{Path: "/"},
+			body:          strings.Repeat("x", 100),
+			contentLength: -1,
+		},
+		{
+			name:          "success: request disables the limit",
+			options:       []Option{WithMaxResponseBodySize(10)},
+			request:       &Request{Path: "/", MaxResponseBodySize: -1},
+			body:          strings.Repeat("x", 100),
+			contentLength: 100,
+		},
+		{
+			name:          "failure: declared Content-Length exceeds the limit",
+			options:       []Option{WithMaxResponseBodySize(10)},
+			request:       &Request{Path: "/"},
+			body:          strings.Repeat("x", 100),
+			contentLength: 100,
+			wantDoErr:     true,
+		},
+		{
+			name:          "failure: streamed body exceeds the limit",
+			options:       []Option{WithMaxResponseBodySize(10)},
+			request:       &Request{Path: "/"},
+			body:          strings.Repeat("x", 100),
+			contentLength: -1,
+			wantReadErr:   true,
+		},
+		{
+			name:          "failure: request overrides the limit",
+			options:       []Option{WithMaxResponseBodySize(1000)},
+			request:       &Request{Path: "/", MaxResponseBodySize: 99},
+			body:          strings.Repeat("x", 100),
+			contentLength: -1,
+			wantReadErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			body := &closeRecorder{Reader: strings.NewReader(tt.body)}
+			options := append([]Option{WithDoFunc(func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, ContentLength: tt.contentLength, Body: body}, nil
+			})}, tt.options...)
+			client := NewClient("http://example.com", options...)
+
+			response, err := client.Do(context.Background(), tt.request, nil)
+			if tt.wantDoErr {
+				require.ErrorIs(t, err, ErrBodyTooLarge)
+				assert.Equal(t, CategoryValidation, ClassifyError(err))
+				assert.Nil(t, response)
+				assert.True(t, body.closed)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			var got string
+
+			err = response.DecodeJSON(&got)
+			if tt.wantReadErr {
+				var tooLarge *BodyTooLargeError
+				require.ErrorAs(t, err, &tooLarge)
+				assert.Equal(t, CategoryValidation, ClassifyError(err))
+
+				return
+			}
+
+			require.Error(t, err, "the body is not JSON")
+			assert.Equal(t, CategoryDecode, ClassifyError(err))
+		})
+	}
+}
+
+func TestLimitedBody_Read(t *testing.T) {
+	t.Parallel()
+
+	body := &limitedBody{body: io.NopCloser(strings.NewReader("0123456789")), remaining: 4, limit: 4}
+
+	buffer := make([]byte, 3)
+	n, err := body.Read(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, "012", string(buffer[:n]))
+
+	n, err = body.Read(buffer)
+	require.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, "3", string(buffer[:n]))
+
+	n, err = body.Read(buffer)
+	require.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Zero(t, n)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+
+	return nil
+}
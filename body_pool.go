@@ -0,0 +1,72 @@
+package webapiclient
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// maxPooledBufferSize is the capacity above which buffers are dropped instead of being returned to the pool,
+// so that a few large bodies do not pin memory.
+const maxPooledBufferSize = 1 << 20
+
+// bodyBufferPool holds the buffers used to read response bodies.
+var bodyBufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBodyBuffer() *bytes.Buffer {
+	buffer, _ := bodyBufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+
+	return buffer
+}
+
+func putBodyBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() > maxPooledBufferSize {
+		return
+	}
+
+	bodyBufferPool.Put(buffer)
+}
+
+// ConsumeBody reads the whole body of response into a pooled buffer, closes it and calls fn with its content,
+// avoiding a new allocation per call for high-throughput callers.
+// The slice is reused after fn returns; fn must copy anything it keeps.
+func ConsumeBody(response *Response, fn func(body []byte) error) error {
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	buffer := getBodyBuffer()
+	defer putBodyBuffer(buffer)
+
+	_, err := buffer.ReadFrom(response.Body)
+	if err != nil {
+		return errors.WithStack(classifyTransportError(err))
+	}
+
+	return errors.WithStack(fn(buffer.Bytes()))
+}
+
+// readPooled reads at most limit bytes of r into a pooled buffer and returns them as a string,
+// together with whether r had more bytes.
+func readPooled(r io.Reader, limit int64) (string, bool, error) {
+	buffer := getBodyBuffer()
+	defer putBodyBuffer(buffer)
+
+	_, err := buffer.ReadFrom(io.LimitReader(r, limit+1))
+	if err != nil {
+		return "", false, errors.WithStack(err)
+	}
+
+	if int64(buffer.Len()) > limit {
+		return string(buffer.Bytes()[:limit]), true, nil
+	}
+
+	return buffer.String(), false, nil
+}
@@ -0,0 +1,109 @@
+package webapiclient
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeBody(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: body passed and closed", func(t *testing.T) {
+		t.Parallel()
+
+		body := &trackingBody{Reader: strings.NewReader("payload"), closed: make(chan struct{})}
+
+		var got string
+		err := ConsumeBody(&Response{Body: body}, func(b []byte) error {
+			got = string(b)
+
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "payload", got)
+
+		select {
+		case <-body.closed:
+		default:
+			t.Error("body not closed")
+		}
+	})
+
+	t.Run("failure: callback error", func(t *testing.T) {
+		t.Parallel()
+
+		errDecode := errors.New("decode")
+		body := &trackingBody{Reader: strings.NewReader("payload"), closed: make(chan struct{})}
+
+		err := ConsumeBody(&Response{Body: body}, func(_ []byte) error {
+			return errDecode
+		})
+		assert.ErrorIs(t, err, errDecode)
+
+		select {
+		case <-body.closed:
+		default:
+			t.Error("body not closed")
+		}
+	})
+}
+
+func TestReadPooled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		body          string
+		limit         int64
+		want          string
+		wantTruncated bool
+	}{
+		{name: "success: within limit", body: "abc", limit: 3, want: "abc"},
+		{name: "success: truncated", body: "abcdef", limit: 4, want: "abcd", wantTruncated: true},
+		{name: "success: empty", body: "", limit: 4, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, truncated, err := readPooled(bytes.NewReader([]byte(tt.body)), tt.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantTruncated, truncated)
+		})
+	}
+}
+
+func BenchmarkConsumeBody(b *testing.B) {
+	body := bytes.Repeat([]byte("x"), 4096)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		response := &Response{Body: io.NopCloser(bytes.NewReader(body))}
+
+		err := ConsumeBody(response, func([]byte) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadPooled(b *testing.B) {
+	body := bytes.Repeat([]byte("x"), 4096)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_, _, err := readPooled(bytes.NewReader(body), 1024)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package webapiclient
+
+import (
+	"context"
+	"time"
+)
+
+// callInfoContextKey is the context key of the CallInfo of a request sent by Do.
+type callInfoContextKey struct{}
+
+// CallInfo describes the progress of a call to Do. It is carried by the context of the requests passed to
+// edit functions and middleware, so that signing functions can set accurate timestamps and expiries.
+// Edit functions run once before the first attempt; middleware runs again for every retry attempt.
+type CallInfo struct {
+	// Start is the time the call started.
+	Start time.Time
+	// Attempt is the number of the attempt made by the retry policy, starting at 1.
+	Attempt int
+	// Deadline is the deadline of the context of the call, or the zero time when it has none.
+	Deadline time.Time
+}
+
+// Elapsed returns the time elapsed since the call started.
+func (i CallInfo) Elapsed() time.Duration {
+	return time.Since(i.Start)
+}
+
+// Remaining returns the time left until the deadline of the call, and whether the call has a deadline.
+func (i CallInfo) Remaining() (time.Duration, bool) {
+	if i.Deadline.IsZero() {
+		return 0, false
+	}
+
+	return time.Until(i.Deadline), true
+}
+
+// CallInfoFromContext returns the CallInfo carried by the context of a request sent by Do.
+func CallInfoFromContext(ctx context.Context) (CallInfo, bool) {
+	info, ok := ctx.Value(callInfoContextKey{}).(*CallInfo)
+	if !ok {
+		return CallInfo{}, false
+	}
+
+	return *info, true
+}
+
+// withCallInfo returns a copy of ctx carrying the CallInfo of a call starting now.
+func withCallInfo(ctx context.Context) context.Context {
+	deadline, _ := ctx.Deadline()
+
+	return context.WithValue(ctx, callInfoContextKey{}, &CallInfo{Start: time.Now(), Attempt: 1, Deadline: deadline})
+}
+
+// withAttempt returns a copy of ctx whose CallInfo, if any, is for attempt.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	info, ok := ctx.Value(callInfoContextKey{}).(*CallInfo)
+	if !ok {
+		return ctx
+	}
+
+	next := *info
+	next.Attempt = attempt
+
+	return context.WithValue(ctx, callInfoContextKey{}, &next)
+}
@@ -0,0 +1,89 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientImpl_Do_CallInfo(t *testing.T) {
+	t.Parallel()
+
+	var (
+		editInfo CallInfo
+		attempts []int
+		starts   []time.Time
+	)
+
+	client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		status := http.StatusServiceUnavailable
+		if len(attempts) == 3 {
+			status = http.StatusOK
+		}
+
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}))
+	client.Use(func(next DoFunc) DoFunc {
+		return func(httpRequest *http.Request) (*http.Response, error) {
+			info, ok := CallInfoFromContext(httpRequest.Context())
+			require.True(t, ok)
+
+			attempts = append(attempts, info.Attempt)
+			starts = append(starts, info.Start)
+
+			return next(httpRequest)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+
+	response, err := client.Do(ctx, &Request{
+		Path:        "/",
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}, func(httpRequest *http.Request) error {
+		var ok bool
+
+		editInfo, ok = CallInfoFromContext(httpRequest.Context())
+		require.True(t, ok)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+
+	assert.Equal(t, 1, editInfo.Attempt)
+	assert.Equal(t, deadline, editInfo.Deadline)
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+	assert.Equal(t, []time.Time{editInfo.Start, editInfo.Start, editInfo.Start}, starts)
+}
+
+func TestCallInfo_Remaining(t *testing.T) {
+	t.Parallel()
+
+	remaining, ok := CallInfo{Start: time.Now()}.Remaining()
+	assert.False(t, ok)
+	assert.Zero(t, remaining)
+
+	remaining, ok = CallInfo{Start: time.Now(), Deadline: time.Now().Add(time.Hour)}.Remaining()
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour, remaining, float64(time.Minute))
+
+	assert.GreaterOrEqual(t, CallInfo{Start: time.Now().Add(-time.Second)}.Elapsed(), time.Second)
+}
+
+func TestCallInfoFromContext(t *testing.T) {
+	t.Parallel()
+
+	_, ok := CallInfoFromContext(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, context.Background(), withAttempt(context.Background(), 2))
+}
@@ -0,0 +1,75 @@
+package webapiclient
+
+import (
+	"context"
+	"maps"
+)
+
+// callMetaContextKey is the context key of the metadata attached with WithCallMeta.
+type callMetaContextKey struct{}
+
+// WithCallMeta returns a copy of ctx carrying caller metadata, such as a job ID or user ID,
+// for logs, metrics, spans and audit records of requests sent with it.
+// Metadata already attached to ctx is kept unless meta overrides the same key.
+func WithCallMeta(ctx context.Context, meta map[string]string) context.Context {
+	merged := CallMetaFromContext(ctx)
+	maps.Copy(merged, meta)
+
+	return context.WithValue(ctx, callMetaContextKey{}, merged)
+}
+
+// CallMetaFromContext returns a copy of the metadata attached to ctx with WithCallMeta.
+// It returns an empty map when ctx carries no metadata.
+func CallMetaFromContext(ctx context.Context) map[string]string {
+	meta, _ := ctx.Value(callMetaContextKey{}).(map[string]string)
+	if meta == nil {
+		return map[string]string{}
+	}
+
+	return maps.Clone(meta)
+}
+
+// CallMetaLabels returns the metadata attached to ctx restricted to allowlist, for use as metric labels.
+// Every allowlisted key is present so that label sets stay fixed; missing values are empty.
+func CallMetaLabels(ctx context.Context, allowlist ...string) map[string]string {
+	meta, _ := ctx.Value(callMetaContextKey{}).(map[string]string)
+
+	labels := make(map[string]string, len(allowlist))
+	for _, key := range allowlist {
+		labels[key] = meta[key]
+	}
+
+	return labels
+}
+
+// WithCallMetaAllowlist makes the client copy the call metadata with the keys in allowlist into its
+// RequestStarted and RetryScheduled events and its failure artifacts. Other keys are left out, since they
+// may hold personal data.
+func WithCallMetaAllowlist(allowlist ...string) Option {
+	return func(c *client) {
+		c.callMetaAllowlist = allowlist
+	}
+}
+
+// allowedCallMeta returns the metadata attached to ctx restricted to allowlist, skipping missing keys.
+// It returns nil when no allowlisted key is attached.
+func allowedCallMeta(ctx context.Context, allowlist []string) map[string]string {
+	meta, _ := ctx.Value(callMetaContextKey{}).(map[string]string)
+
+	var allowed map[string]string
+
+	for _, key := range allowlist {
+		value, ok := meta[key]
+		if !ok {
+			continue
+		}
+
+		if allowed == nil {
+			allowed = map[string]string{}
+		}
+
+		allowed[key] = value
+	}
+
+	return allowed
+}
@@ -0,0 +1,114 @@
+package webapiclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCallMeta(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: no metadata", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, map[string]string{}, CallMetaFromContext(context.Background()))
+	})
+
+	t.Run("success: merged with outer metadata", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := WithCallMeta(context.Background(), map[string]string{"job_id": "j1", "user_id": "u1"})
+		ctx = WithCallMeta(ctx, map[string]string{"user_id": "u2", "step": "import"})
+
+		assert.Equal(t, map[string]string{"job_id": "j1", "user_id": "u2", "step": "import"}, CallMetaFromContext(ctx))
+	})
+
+	t.Run("success: isolated from caller and outer contexts", func(t *testing.T) {
+		t.Parallel()
+
+		meta := map[string]string{"job_id": "j1"}
+		outer := WithCallMeta(context.Background(), meta)
+		meta["job_id"] = "changed"
+
+		inner := WithCallMeta(outer, map[string]string{"job_id": "j2"})
+		got := CallMetaFromContext(outer)
+		got["job_id"] = "changed"
+
+		assert.Equal(t, map[string]string{"job_id": "j1"}, CallMetaFromContext(outer))
+		assert.Equal(t, map[string]string{"job_id": "j2"}, CallMetaFromContext(inner))
+	})
+}
+
+func TestCallMetaLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx := WithCallMeta(context.Background(), map[string]string{"job_id": "j1", "user_id": "u1"})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		allowlist []string
+		want      map[string]string
+	}{
+		{
+			name:      "success: restricted to allowlist",
+			ctx:       ctx,
+			allowlist: []string{"job_id", "tenant"},
+			want:      map[string]string{"job_id": "j1", "tenant": ""},
+		},
+		{
+			name:      "success: no metadata",
+			ctx:       context.Background(),
+			allowlist: []string{"job_id"},
+			want:      map[string]string{"job_id": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, CallMetaLabels(tt.ctx, tt.allowlist...))
+		})
+	}
+}
+
+func TestAllowedCallMeta(t *testing.T) {
+	t.Parallel()
+
+	ctx := WithCallMeta(context.Background(), map[string]string{"job_id": "j1", "user_id": "u1"})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		allowlist []string
+		want      map[string]string
+	}{
+		{
+			name:      "success: restricted to allowlist without missing keys",
+			ctx:       ctx,
+			allowlist: []string{"job_id", "tenant"},
+			want:      map[string]string{"job_id": "j1"},
+		},
+		{
+			name:      "success: no allowlist",
+			ctx:       ctx,
+			allowlist: nil,
+			want:      nil,
+		},
+		{
+			name:      "success: no metadata",
+			ctx:       context.Background(),
+			allowlist: []string{"job_id"},
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, allowedCallMeta(tt.ctx, tt.allowlist))
+		})
+	}
+}
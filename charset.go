@@ -0,0 +1,137 @@
+package webapiclient
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// CharsetFunc returns a reader decoding text in a charset read from r into UTF-8.
+type CharsetFunc func(r io.Reader) io.Reader
+
+// windows1252 maps the bytes 0x80 to 0x9F of windows-1252 to the runes they encode.
+// The other bytes encode the same runes as in ISO-8859-1.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+var (
+	charsetsMu sync.RWMutex
+	charsets   = map[string]CharsetFunc{
+		"utf-8":        func(r io.Reader) io.Reader { return r },
+		"us-ascii":     func(r io.Reader) io.Reader { return r },
+		"iso-8859-1":   func(r io.Reader) io.Reader { return newSingleByteReader(r, nil) },
+		"latin1":       func(r io.Reader) io.Reader { return newSingleByteReader(r, nil) },
+		"windows-1252": func(r io.Reader) io.Reader { return newSingleByteReader(r, &windows1252) },
+	}
+)
+
+// RegisterCharset registers charset for decoding text in the charset name, replacing any charset registered
+// before. UTF-8, US-ASCII, ISO-8859-1 and windows-1252 are registered by default; other charsets can be
+// registered using golang.org/x/text/encoding, for example with charmap.ISO8859_15.NewDecoder().Reader.
+func RegisterCharset(name string, charset CharsetFunc) {
+	charsetsMu.Lock()
+	defer charsetsMu.Unlock()
+
+	charsets[strings.ToLower(name)] = charset
+}
+
+// charsetReader returns a reader decoding input from the charset label into UTF-8.
+// Its signature matches xml.Decoder.CharsetReader.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	charsetsMu.RLock()
+	charset, ok := charsets[strings.ToLower(strings.TrimSpace(label))]
+	charsetsMu.RUnlock()
+
+	if !ok {
+		return nil, errors.Errorf("unsupported charset: %s", label)
+	}
+
+	return charset(input), nil
+}
+
+// contentTypeCharset returns the charset parameter of the Content-Type header of headers, or an empty string.
+func contentTypeCharset(headers map[string][]string) string {
+	for name, values := range headers {
+		if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+			_, params, _ := mime.ParseMediaType(values[0])
+
+			return params["charset"]
+		}
+	}
+
+	return ""
+}
+
+// newXMLDecoder returns a decoder reading XML from r. The charset of the Content-Type header, when given,
+// takes precedence over the encoding declaration of the document, as specified by RFC 7303.
+func newXMLDecoder(r io.Reader, charset string) (*xml.Decoder, error) {
+	if charset == "" {
+		decoder := xml.NewDecoder(r)
+		decoder.CharsetReader = charsetReader
+
+		return decoder, nil
+	}
+
+	utf8Reader, err := charsetReader(charset, r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	decoder := xml.NewDecoder(utf8Reader)
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	return decoder, nil
+}
+
+// singleByteReader decodes a single-byte charset matching ISO-8859-1 except for the bytes 0x80 to 0x9F,
+// which are decoded with high when not nil.
+type singleByteReader struct {
+	r       *bufio.Reader
+	high    *[32]rune
+	pending []byte
+}
+
+func newSingleByteReader(r io.Reader, high *[32]rune) *singleByteReader {
+	return &singleByteReader{r: bufio.NewReader(r), high: high}
+}
+
+// Read fills p with UTF-8 encoded text, holding back the bytes of a rune that do not fit for the next call.
+func (s *singleByteReader) Read(p []byte) (int, error) {
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+
+	for n < len(p) {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+
+			return 0, err //nolint:wrapcheck
+		}
+
+		r := rune(b)
+		if s.high != nil && b >= 0x80 && b <= 0x9F {
+			r = s.high[b-0x80]
+		}
+
+		var encoded [utf8.UTFMax]byte
+
+		size := utf8.EncodeRune(encoded[:], r)
+		copied := copy(p[n:], encoded[:size])
+		s.pending = append(s.pending, encoded[copied:size]...)
+		n += copied
+	}
+
+	return n, nil
+}
@@ -0,0 +1,114 @@
+package webapiclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharsetReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		label   string
+		input   []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "success: UTF-8", label: "UTF-8", input: []byte("café"), want: "café"},
+		{name: "success: ISO-8859-1", label: "ISO-8859-1", input: []byte{'c', 'a', 'f', 0xE9}, want: "café"},
+		{name: "success: windows-1252", label: "windows-1252", input: []byte{0x80, ' ', 0x93, 'q', 0x94, ' ', 0xE9}, want: "€ “q” é"},
+		{name: "failure: unsupported charset", label: "shift_jis", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader, err := charsetReader(tt.label, bytes.NewReader(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			// One byte reads make the reader hold back the bytes of multi-byte runes.
+			got, err := io.ReadAll(iotest.OneByteReader(reader))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRegisterCharset(t *testing.T) {
+	t.Parallel()
+
+	RegisterCharset("X-Upper", func(r io.Reader) io.Reader {
+		body, _ := io.ReadAll(r)
+
+		return strings.NewReader(strings.ToUpper(string(body)))
+	})
+
+	reader, err := charsetReader("x-upper", strings.NewReader("abc"))
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", string(got))
+}
+
+func TestResponse_DecodeXML_Charset(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string `xml:"name"`
+	}
+
+	latin1 := func(s string) []byte {
+		return append([]byte(s), 0xE9, '<', '/', 'n', 'a', 'm', 'e', '>', '<', '/', 'i', 't', 'e', 'm', '>')
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{
+			name:        "success: encoding declaration",
+			contentType: "application/xml",
+			body:        latin1(`<?xml version="1.0" encoding="ISO-8859-1"?><item><name>caf`),
+		},
+		{
+			name:        "success: Content-Type charset takes precedence",
+			contentType: "text/xml; charset=iso-8859-1",
+			body:        latin1(`<?xml version="1.0" encoding="UTF-8"?><item><name>caf`),
+		},
+		{
+			name:        "success: Content-Type charset without declaration",
+			contentType: "text/xml; charset=windows-1252",
+			body:        latin1(`<item><name>caf`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response := &Response{
+				StatusCode: http.StatusOK,
+				Headers:    map[string][]string{"Content-Type": {tt.contentType}},
+				Body:       io.NopCloser(bytes.NewReader(tt.body)),
+			}
+
+			var got item
+			require.NoError(t, response.DecodeXML(&got))
+			assert.Equal(t, "café", got.Name)
+		})
+	}
+}
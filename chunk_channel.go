@@ -0,0 +1,117 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Chunk is a piece of a response body delivered by Client.DoToChannel.
+// The final chunk carries Err when reading the body failed.
+type Chunk struct {
+	Data []byte
+	Err  error
+}
+
+// DoToChannel executes an HTTP request and streams the response body as chunks of up to chunkSize bytes.
+// The channel holds at most one chunk, so the body is read only as fast as the consumer receives,
+// and it is closed after the last chunk. The response body is closed when the stream ends or ctx is done;
+// a truncated body or the end of ctx is reported by a last chunk carrying the error.
+func (c *client) DoToChannel(
+	ctx context.Context,
+	request *Request,
+	edit EditRequestFunc,
+	chunkSize int,
+) (<-chan Chunk, error) {
+	if chunkSize <= 0 {
+		return nil, errors.WithStack(newError(CategoryValidation, errors.Errorf("invalid chunk size: %d", chunkSize)))
+	}
+
+	response, err := c.Do(ctx, request, edit)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	chunks := make(chan Chunk, 1)
+
+	go func() {
+		defer close(chunks)
+		defer func() {
+			_ = response.Body.Close()
+		}()
+
+		for {
+			buffer := make([]byte, chunkSize)
+
+			n, err := readChunk(response.Body, buffer)
+			if n > 0 && !sendChunk(ctx, chunks, Chunk{Data: buffer[:n]}) {
+				sendLastChunk(chunks, Chunk{Err: errors.WithStack(ctx.Err())})
+
+				return
+			}
+
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				if !sendChunk(ctx, chunks, Chunk{Err: errors.WithStack(err)}) {
+					sendLastChunk(chunks, Chunk{Err: errors.WithStack(ctx.Err())})
+				}
+
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// readChunk fills buffer from body. Unlike io.ReadFull, it returns io.EOF with the short last read of the body,
+// and returns the io.ErrUnexpectedEOF of a truncated body as is.
+func readChunk(body io.Reader, buffer []byte) (int, error) {
+	n := 0
+
+	for n < len(buffer) {
+		m, err := body.Read(buffer[n:])
+		n += m
+
+		if err != nil {
+			return n, err //nolint:wrapcheck
+		}
+	}
+
+	return n, nil
+}
+
+func sendChunk(ctx context.Context, chunks chan<- Chunk, chunk Chunk) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case chunks <- chunk:
+		return true
+	}
+}
+
+// sendLastChunk delivers the error chunk ending a stream canceled by ctx without blocking, replacing a chunk
+// not received yet, so that consumers can tell cancellation from completion even when they stopped receiving.
+func sendLastChunk(chunks chan Chunk, chunk Chunk) {
+	select {
+	case chunks <- chunk:
+		return
+	default:
+	}
+
+	// The goroutine of DoToChannel is the only sender, so the channel has room once the pending chunk is dropped.
+	select {
+	case <-chunks:
+	default:
+	}
+
+	chunks <- chunk
+}
@@ -0,0 +1,128 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type trackingBody struct {
+	io.Reader
+
+	closed chan struct{}
+}
+
+func (b *trackingBody) Close() error {
+	close(b.closed)
+
+	return nil
+}
+
+func TestClientImpl_DoToChannel(t *testing.T) {
+	t.Parallel()
+
+	newClient := func(body io.ReadCloser) Client {
+		return NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+		}))
+	}
+
+	t.Run("success: chunks in order", func(t *testing.T) {
+		t.Parallel()
+
+		body := &trackingBody{Reader: bytes.NewReader([]byte("abcdefghij")), closed: make(chan struct{})}
+		chunks, err := newClient(body).DoToChannel(context.Background(), &Request{Method: http.MethodGet, Path: "/"}, nil, 4)
+		require.NoError(t, err)
+
+		var got []string
+		for chunk := range chunks {
+			require.NoError(t, chunk.Err)
+			got = append(got, string(chunk.Data))
+		}
+		assert.Equal(t, []string{"abcd", "efgh", "ij"}, got)
+		<-body.closed
+	})
+
+	t.Run("failure: read error delivered as last chunk", func(t *testing.T) {
+		t.Parallel()
+
+		want := errors.New("connection lost")
+		body := &trackingBody{
+			Reader: io.MultiReader(bytes.NewReader([]byte("abcd")), &failingReader{err: want}),
+			closed: make(chan struct{}),
+		}
+		chunks, err := newClient(body).DoToChannel(context.Background(), &Request{Method: http.MethodGet, Path: "/"}, nil, 4)
+		require.NoError(t, err)
+
+		first := <-chunks
+		assert.Equal(t, []byte("abcd"), first.Data)
+		last := <-chunks
+		assert.ErrorIs(t, last.Err, want)
+		_, ok := <-chunks
+		assert.False(t, ok)
+	})
+
+	t.Run("success: stops when context is done", func(t *testing.T) {
+		t.Parallel()
+
+		body := &trackingBody{Reader: bytes.NewReader(bytes.Repeat([]byte("x"), 1024)), closed: make(chan struct{})}
+		ctx, cancel := context.WithCancel(context.Background())
+		chunks, err := newClient(body).DoToChannel(ctx, &Request{Method: http.MethodGet, Path: "/"}, nil, 1)
+		require.NoError(t, err)
+
+		<-chunks
+		cancel()
+		<-body.closed
+
+		var last Chunk
+		for chunk := range chunks {
+			last = chunk
+		}
+		assert.ErrorIs(t, last.Err, context.Canceled)
+	})
+
+	t.Run("failure: truncated body delivered as last chunk", func(t *testing.T) {
+		t.Parallel()
+
+		body := &trackingBody{
+			Reader: io.MultiReader(bytes.NewReader([]byte("abcdef")), &failingReader{err: io.ErrUnexpectedEOF}),
+			closed: make(chan struct{}),
+		}
+		chunks, err := newClient(body).DoToChannel(context.Background(), &Request{Method: http.MethodGet, Path: "/"}, nil, 4)
+		require.NoError(t, err)
+
+		var got []string
+
+		var last Chunk
+		for chunk := range chunks {
+			if chunk.Data != nil {
+				got = append(got, string(chunk.Data))
+			}
+			last = chunk
+		}
+		assert.Equal(t, []string{"abcd", "ef"}, got)
+		assert.ErrorIs(t, last.Err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("failure: invalid chunk size", func(t *testing.T) {
+		t.Parallel()
+
+		body := &trackingBody{Reader: bytes.NewReader(nil), closed: make(chan struct{})}
+		_, err := newClient(body).DoToChannel(context.Background(), &Request{Method: http.MethodGet, Path: "/"}, nil, 0)
+		assert.Equal(t, CategoryValidation, ClassifyError(err))
+	})
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read(_ []byte) (int, error) {
+	return 0, r.err
+}
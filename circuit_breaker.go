@@ -0,0 +1,191 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is matched by the errors of requests rejected by an open CircuitBreaker.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitOpenError is returned for requests rejected by an open CircuitBreaker.
+type CircuitOpenError struct {
+	// Key is the circuit that rejected the request.
+	Key string
+	// RetryAt is the time at which a probe request will be let through.
+	RetryAt time.Time
+}
+
+// Error returns a message naming the open circuit.
+func (e *CircuitOpenError) Error() string {
+	return "circuit open: " + e.Key
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen //nolint:errorlint
+}
+
+// CircuitState is the state of a circuit.
+type CircuitState string
+
+const (
+	// CircuitClosed lets requests through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects requests until the cooldown has passed.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe request through to decide whether to close the circuit again.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreaker stops sending requests to a host or endpoint after consecutive failures, rejecting them
+// with a *CircuitOpenError until a cooldown has passed and a probe request succeeds.
+// Transport errors and 5xx responses are failures.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	key       ResourceKeyFunc
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	retryAt  time.Time
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker opening a circuit after threshold consecutive failures
+// for cooldown. Circuits are keyed by key, or by host when key is nil.
+func NewCircuitBreaker(threshold int, cooldown time.Duration, key ResourceKeyFunc) *CircuitBreaker {
+	if key == nil {
+		key = func(httpRequest *http.Request) string {
+			return httpRequest.URL.Host
+		}
+	}
+
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		key:       key,
+		circuits:  map[string]*circuit{},
+	}
+}
+
+// State returns the state of the circuit for key.
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return CircuitClosed
+	}
+
+	if c.state == CircuitOpen && !time.Now().Before(c.retryAt) {
+		return CircuitHalfOpen
+	}
+
+	return c.state
+}
+
+// Wrap wraps do so that requests to open circuits are rejected without being sent.
+func (b *CircuitBreaker) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		key := b.key(httpRequest)
+
+		err := b.allow(key, time.Now())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		httpResponse, err := do(httpRequest)
+		if err != nil {
+			if httpRequest.Context().Err() != nil {
+				b.abandon(key)
+			} else {
+				b.record(httpRequest.Context(), key, false)
+			}
+
+			return nil, errors.WithStack(err)
+		}
+
+		b.record(httpRequest.Context(), key, httpResponse.StatusCode < http.StatusInternalServerError)
+
+		return httpResponse, nil
+	}
+}
+
+func (b *CircuitBreaker) allow(key string, now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		b.circuits[key] = c
+	}
+
+	switch c.state {
+	case CircuitClosed:
+		return nil
+	case CircuitOpen:
+		if now.Before(c.retryAt) {
+			return &CircuitOpenError{Key: key, RetryAt: c.retryAt}
+		}
+
+		c.state = CircuitHalfOpen
+
+		return nil
+	default:
+		// A probe is in flight.
+		return &CircuitOpenError{Key: key, RetryAt: c.retryAt}
+	}
+}
+
+// record updates the circuit of key with the outcome of a request, and publishes CircuitOpened on the EventBus
+// of ctx when the circuit opens.
+func (b *CircuitBreaker) record(ctx context.Context, key string, success bool) {
+	b.mu.Lock()
+
+	c := b.circuits[key]
+
+	if success {
+		c.state = CircuitClosed
+		c.failures = 0
+		b.mu.Unlock()
+
+		return
+	}
+
+	c.failures++
+
+	opened := c.state != CircuitOpen && (c.state == CircuitHalfOpen || c.failures >= b.threshold)
+	if opened {
+		c.state = CircuitOpen
+		c.retryAt = time.Now().Add(b.cooldown)
+	}
+
+	retryAt := c.retryAt
+	b.mu.Unlock()
+
+	if opened {
+		PublishEvent(ctx, CircuitOpened{Key: key, RetryAt: retryAt})
+	}
+}
+
+// abandon releases a probe canceled by its caller without deciding the state of the circuit.
+func (b *CircuitBreaker) abandon(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[key]
+	if c.state == CircuitHalfOpen {
+		c.state = CircuitOpen
+	}
+}
@@ -0,0 +1,173 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker_Wrap(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		status int
+		err    error
+	}
+
+	newDo := func(results *[]result, calls *int) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			r := (*results)[*calls]
+			*calls++
+
+			if r.err != nil {
+				return nil, r.err
+			}
+
+			return &http.Response{StatusCode: r.status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+	}
+
+	send := func(t *testing.T, do DoFunc, url string) error {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		resp, err := do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+
+		return err
+	}
+
+	t.Run("success: opens after consecutive failures and closes after a probe", func(t *testing.T) {
+		t.Parallel()
+
+		results := []result{
+			{status: http.StatusInternalServerError},
+			{err: syscall.ECONNREFUSED},
+			{status: http.StatusOK},
+		}
+		calls := 0
+		breaker := NewCircuitBreaker(2, 20*time.Millisecond, nil)
+		do := breaker.Wrap(newDo(&results, &calls))
+
+		_ = send(t, do, "http://a.example.com/x")
+		assert.Equal(t, CircuitClosed, breaker.State("a.example.com"))
+		_ = send(t, do, "http://a.example.com/x")
+		assert.Equal(t, CircuitOpen, breaker.State("a.example.com"))
+
+		err := send(t, do, "http://a.example.com/x")
+		require.ErrorIs(t, err, ErrCircuitOpen)
+
+		var openError *CircuitOpenError
+		require.ErrorAs(t, err, &openError)
+		assert.Equal(t, "a.example.com", openError.Key)
+		assert.Equal(t, 2, calls)
+
+		time.Sleep(25 * time.Millisecond)
+		assert.Equal(t, CircuitHalfOpen, breaker.State("a.example.com"))
+
+		require.NoError(t, send(t, do, "http://a.example.com/x"))
+		assert.Equal(t, CircuitClosed, breaker.State("a.example.com"))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("success: failed probe reopens", func(t *testing.T) {
+		t.Parallel()
+
+		results := []result{
+			{status: http.StatusServiceUnavailable},
+			{status: http.StatusServiceUnavailable},
+		}
+		calls := 0
+		breaker := NewCircuitBreaker(1, 10*time.Millisecond, nil)
+		do := breaker.Wrap(newDo(&results, &calls))
+
+		_ = send(t, do, "http://a.example.com/x")
+		time.Sleep(15 * time.Millisecond)
+		_ = send(t, do, "http://a.example.com/x")
+
+		assert.Equal(t, CircuitOpen, breaker.State("a.example.com"))
+		assert.ErrorIs(t, send(t, do, "http://a.example.com/x"), ErrCircuitOpen)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("success: successes reset the failure count", func(t *testing.T) {
+		t.Parallel()
+
+		results := []result{
+			{status: http.StatusBadGateway},
+			{status: http.StatusNotFound},
+			{status: http.StatusBadGateway},
+		}
+		calls := 0
+		breaker := NewCircuitBreaker(2, time.Hour, nil)
+		do := breaker.Wrap(newDo(&results, &calls))
+
+		for range results {
+			_ = send(t, do, "http://a.example.com/x")
+		}
+		assert.Equal(t, CircuitClosed, breaker.State("a.example.com"))
+	})
+
+	t.Run("success: circuits are keyed", func(t *testing.T) {
+		t.Parallel()
+
+		results := []result{
+			{status: http.StatusInternalServerError},
+			{status: http.StatusOK},
+		}
+		calls := 0
+		breaker := NewCircuitBreaker(1, time.Hour, PathResourceKey)
+		do := breaker.Wrap(newDo(&results, &calls))
+
+		_ = send(t, do, "http://a.example.com/x")
+		assert.ErrorIs(t, send(t, do, "http://a.example.com/x"), ErrCircuitOpen)
+		require.NoError(t, send(t, do, "http://a.example.com/y"))
+	})
+}
+
+func TestClientImpl_Do_CircuitOpenNotRetried(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	breaker := NewCircuitBreaker(1, time.Hour, nil)
+	client := NewClient("http://example.com",
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		WithDoFunc(breaker.Wrap(func(_ *http.Request) (*http.Response, error) {
+			calls++
+
+			return nil, syscall.ECONNREFUSED
+		})),
+	)
+	request := &Request{Method: http.MethodGet, Path: "/"}
+
+	// The first attempt opens the circuit, and the retries are rejected by it without being sent.
+	budget := NewRetryBudget(10, time.Minute)
+	_, err := client.Do(WithRetryBudget(context.Background(), budget), request, nil)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 1, calls)
+
+	retries, _ := budget.Used()
+	assert.Equal(t, 1, retries, "only the retry of the transport error is taken from the budget")
+
+	_, err = client.Do(WithRetryBudget(context.Background(), budget), request, nil)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 1, calls)
+
+	retries, _ = budget.Used()
+	assert.Equal(t, 1, retries, "requests rejected by the open circuit are not retried")
+
+	var clientError *Error
+	require.ErrorAs(t, err, &clientError)
+	assert.False(t, clientError.Temporary())
+}
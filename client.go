@@ -2,12 +2,16 @@
 package webapiclient
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"io"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -19,6 +23,16 @@ var _ Client = (*client)(nil)
 type Client interface {
 	// Do executes an HTTP request with optional request editing and returns the response.
 	Do(ctx context.Context, request *Request, edit EditRequestFunc) (*Response, error)
+	// Go executes an HTTP request asynchronously and returns a Future for its result.
+	Go(ctx context.Context, request *Request, edit EditRequestFunc) *Future
+	// DoToChannel executes an HTTP request and streams the response body as chunks with backpressure.
+	DoToChannel(ctx context.Context, request *Request, edit EditRequestFunc, chunkSize int) (<-chan Chunk, error)
+	// DryRun validates, builds and edits an HTTP request exactly as Do would, and returns it without sending it.
+	DryRun(ctx context.Context, request *Request, edit EditRequestFunc) (*http.Request, error)
+	// Use adds middlewares around the DoFunc of the client, the first one being the outermost.
+	Use(middlewares ...Middleware)
+	// RateLimit returns the rate limit last reported by the responses of host.
+	RateLimit(host string) (RateLimitState, bool)
 }
 
 // Request represents an HTTP request to be made by the client.
@@ -29,6 +43,33 @@ type Request struct {
 	Body                 io.Reader
 	ExpectedStatusCodes  []int
 	ExpectedContentTypes []string
+	// Query holds query parameters that are encoded and added to the query of Path.
+	Query map[string][]string
+	// JSON is marshaled as the body, with the Content-Type header defaulting to application/json.
+	// It must not be set together with Body.
+	JSON any
+	// XML is marshaled as the body, with the Content-Type header defaulting to application/xml.
+	// It must not be set together with Body or JSON.
+	XML any
+	// Form is encoded as the body, with the Content-Type header defaulting to application/x-www-form-urlencoded.
+	// It must not be set together with Body, JSON or XML.
+	Form map[string][]string
+	// Multipart is streamed as the body, with the Content-Type header defaulting to multipart/form-data.
+	// It must not be set together with Body, JSON, XML or Form.
+	Multipart *MultipartBody
+	// ContentEncoding compresses Body with the codec registered for the encoding, such as "gzip",
+	// and sets the Content-Encoding header accordingly.
+	ContentEncoding string
+	// RetryPolicy overrides the retry policy of the client for this request; use MaxAttempts 1 to disable retries.
+	RetryPolicy *RetryPolicy
+	// MaxResponseBodySize overrides the response body size limit of the client; negative means no limit.
+	MaxResponseBodySize int64
+	// ErrorInto is a pointer the JSON body of a response with an unexpected status code is unmarshaled into.
+	// It is set as the Detail of the returned *APIError, and as its Err when it implements error.
+	ErrorInto any
+	// Timeout limits the time of the call, including retries and reading the response body, like a context
+	// created with context.WithTimeout; zero means the default timeout of the client.
+	Timeout time.Duration
 }
 
 // Response represents an HTTP response returned by the client.
@@ -36,6 +77,12 @@ type Response struct {
 	StatusCode int
 	Headers    map[string][]string
 	Body       io.ReadCloser
+	// Date is the server time reported by the Date header, or zero when absent.
+	Date time.Time
+	// ClockSkew is the difference between the server time and the local time; positive when the server is ahead.
+	ClockSkew time.Duration
+	// Redirects are the redirects followed before this response, oldest first.
+	Redirects []Hop
 }
 
 // EditRequestFunc is a function type for editing HTTP requests before they are sent.
@@ -46,72 +93,268 @@ type DoFunc func(httpRequest *http.Request) (*http.Response, error)
 
 // client is the default implementation of the Client interface.
 type client struct {
-	do      DoFunc
-	baseURL string
+	do                  DoFunc
+	httpClient          *http.Client
+	freshConnectionDo   DoFunc
+	baseURL             *url.URL
+	baseURLErr          error
+	artifactSink        ArtifactSink
+	artifactRedaction   ArtifactRedaction
+	retryPolicy         *RetryPolicy
+	flags               FlagProvider
+	profile             *Profile
+	profileBaseURL      *url.URL
+	middlewares         []Middleware
+	chained             DoFunc
+	responseHeaders     []string
+	defaultHeaders      http.Header
+	defaultQuery        url.Values
+	urlBuilder          URLBuilder
+	rateLimits          rateLimitStates
+	responseOnError     bool
+	maxResponseBodySize int64
+	errorDecoders       map[string]ErrorDecoderFunc
+	responseValidators  []ResponseValidator
+	eventBus            *EventBus
+	callMetaAllowlist   []string
+	timeout             time.Duration
 }
 
-// NewClient creates a new client instance with the specified DoFunc and base URL.
-func NewClient(do DoFunc, baseURL string) Client {
-	return &client{
-		do:      do,
-		baseURL: baseURL,
+// Option configures a client created by NewClient.
+type Option func(c *client)
+
+// NewClient creates a new client instance with the specified base URL and options.
+// Requests are sent with http.DefaultClient unless WithDoFunc or WithHTTPClient is given.
+// A malformed base URL fails every request with a validation error; use TryNewClient to detect it up front.
+func NewClient(baseURL string, options ...Option) Client {
+	return newClient(baseURL, options...)
+}
+
+// TryNewClient creates a new client instance like NewClient, and returns a *ValidationError
+// when baseURL is not an absolute URL with a host.
+func TryNewClient(baseURL string, options ...Option) (Client, error) {
+	c := newClient(baseURL, options...)
+
+	var reason string
+
+	switch {
+	case c.baseURLErr != nil:
+		reason = "must be a valid URL"
+	case !c.baseURL.IsAbs() || c.baseURL.Host == "":
+		reason = "must be an absolute URL with a host"
+	default:
+		return c, nil
+	}
+
+	return nil, errors.WithStack(&ValidationError{Fields: []FieldError{{Path: "BaseURL", Reason: reason}}})
+}
+
+func newClient(baseURL string, options ...Option) *client {
+	c := &client{
+		do:         http.DefaultClient.Do,
+		httpClient: http.DefaultClient,
+		urlBuilder: URLBuilderFunc(ResolveURL),
 	}
+
+	// The base URL is parsed once here rather than on every request.
+	c.baseURL, c.baseURLErr = url.Parse(baseURL)
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.freshConnectionDo = newFreshConnectionDo(c.httpClient)
+	c.buildChain()
+
+	return c
+}
+
+// buildChain wraps the DoFunc of the client with its middlewares, the rate limit state being updated by every response.
+func (c *client) buildChain() {
+	c.chained = Chain(c.middlewares...)(c.rateLimits.observe(withFreshConnections(c.do, c.freshConnectionDo)))
+}
+
+// NewClientWithDoFunc creates a new client instance with the specified DoFunc, base URL and options.
+//
+// Deprecated: Use NewClient with WithDoFunc instead.
+func NewClientWithDoFunc(do DoFunc, baseURL string, options ...Option) Client {
+	return NewClient(baseURL, append([]Option{WithDoFunc(do)}, options...)...)
 }
 
 // Do executes an HTTP request with optional request editing and returns the response.
 func (c *client) Do(ctx context.Context, request *Request, edit EditRequestFunc) (*Response, error) {
-	httpRequest, err := c.buildHTTPRequest(ctx, request)
+	ctx, cancel := c.withTimeout(ctx, request)
+	if cancel == nil {
+		return c.doRequest(ctx, request, edit)
+	}
+
+	response, err := c.doRequest(ctx, request, edit)
+	if response == nil {
+		cancel()
+
+		return nil, err
+	}
+
+	// The deadline also covers reading the body, so it is released when the body is closed.
+	response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: func(error) { cancel() }}
+
+	return response, err
+}
+
+func (c *client) doRequest(ctx context.Context, request *Request, edit EditRequestFunc) (*Response, error) {
+	httpRequest, err := c.prepareHTTPRequest(ctx, request, edit)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if edit != nil {
-		err := edit(httpRequest)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
+	var retryPolicy *RetryPolicy
+	if c.enabled(ctx, FlagRetry) {
+		retryPolicy = c.retryPolicy
 	}
 
-	httpResponse, err := c.do(httpRequest)
+	if request.RetryPolicy != nil {
+		retryPolicy = request.RetryPolicy
+	}
+
+	meta := allowedCallMeta(ctx, c.callMetaAllowlist)
+
+	if c.eventBus != nil {
+		c.eventBus.Publish(RequestStarted{Method: httpRequest.Method, URL: sanitizeURL(httpRequest.URL), Meta: meta})
+	}
+
+	httpResponse, err := sendWithRetry(c.chained, httpRequest, retryPolicy, meta)
 	if err != nil {
+		return nil, errors.WithStack(classifyTransportError(err))
+	}
+
+	err = c.limitBody(httpResponse, request)
+	if err != nil {
+		_ = httpResponse.Body.Close()
+
 		return nil, errors.WithStack(err)
 	}
 
-	err = c.validateResponse(httpResponse, request)
+	err = c.validateResponse(httpRequest, httpResponse, request)
 	if err != nil {
+		err = c.captureArtifact(httpRequest, httpResponse, err)
+		if c.responseOnError {
+			return c.newResponse(httpResponse), errors.WithStack(err)
+		}
+
 		_ = httpResponse.Body.Close()
 
 		return nil, errors.WithStack(err)
 	}
 
+	return c.newResponse(httpResponse), nil
+}
+
+func (c *client) newResponse(httpResponse *http.Response) *Response {
+	date, clockSkew := parseServerDate(httpResponse.Header, time.Now())
+
 	return &Response{
 		StatusCode: httpResponse.StatusCode,
-		Headers:    httpResponse.Header.Clone(),
+		Headers:    c.copyResponseHeaders(httpResponse.Header),
 		Body:       httpResponse.Body,
-	}, nil
+		Date:       date,
+		ClockSkew:  clockSkew,
+		Redirects:  redirectHistory(httpResponse),
+	}
 }
 
-func (c *client) buildHTTPRequest(ctx context.Context, request *Request) (*http.Request, error) {
-	var requestBody io.Reader
-	if request.Method != http.MethodGet && request.Body != nil {
-		requestBody = request.Body
+// errDryRun is returned to the middlewares in place of a response by DryRun.
+var errDryRun = errors.New("dry run")
+
+// DryRun validates, builds and edits an HTTP request exactly as Do would, and returns it without sending it.
+// The request is passed through the middlewares of the client, and the one they would send is returned.
+func (c *client) DryRun(ctx context.Context, request *Request, edit EditRequestFunc) (*http.Request, error) {
+	httpRequest, err := c.prepareHTTPRequest(ctx, request, edit)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var sent *http.Request
+
+	_, err = Chain(c.middlewares...)(func(httpRequest *http.Request) (*http.Response, error) {
+		if sent == nil {
+			sent = httpRequest
+		}
+
+		return nil, errDryRun
+	})(httpRequest)
+	if sent == nil {
+		if err == nil {
+			err = errors.New("a middleware answered without sending the request")
+		}
+
+		return nil, errors.WithStack(err)
 	}
 
-	baseURL, err := url.Parse(c.baseURL)
+	return sent, nil
+}
+
+func (c *client) prepareHTTPRequest(ctx context.Context, request *Request, edit EditRequestFunc) (*http.Request, error) {
+	err := validateRequest(request)
+	if err != nil {
+		return nil, errors.WithStack(newRequestBuildError(err))
+	}
+
+	httpRequest, err := c.buildHTTPRequest(withCallInfo(c.withEventBus(ctx)), request)
+	if err != nil {
+		return nil, errors.WithStack(newRequestBuildError(err))
+	}
+
+	if edit != nil {
+		err := edit(httpRequest)
+		if err != nil {
+			return nil, errors.WithStack(newRequestBuildError(err))
+		}
+	}
+
+	err = c.checkProfile(httpRequest)
+	if err != nil {
+		return nil, errors.WithStack(newRequestBuildError(err))
+	}
+
+	return httpRequest, nil
+}
+
+func (c *client) buildHTTPRequest(ctx context.Context, request *Request) (*http.Request, error) {
+	requestBody, contentType, err := encodeRequestBody(request)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	requestURL, err := baseURL.Parse(request.Path)
+	if request.ContentEncoding != "" && requestBody != nil {
+		compressedBody, err := compressBody(request.ContentEncoding, requestBody)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		requestBody = compressedBody
+	}
+
+	if c.baseURLErr != nil {
+		return nil, errors.WithStack(c.baseURLErr)
+	}
+
+	requestURL, err := c.urlBuilder.BuildURL(c.baseURL, request)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	httpRequest, err := http.NewRequestWithContext(ctx, request.Method, requestURL.String(), requestBody)
+	c.mergeQuery(requestURL, request)
+
+	// The request is created without a URL so that the resolved one is not formatted and parsed again.
+	httpRequest, err := http.NewRequestWithContext(ctx, request.Method, "", requestBody)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	httpRequest.URL = requestURL
+	httpRequest.Host = requestURL.Host
+	httpRequest.Header = make(http.Header, len(request.Headers)+len(c.defaultHeaders))
+
 	for key, values := range request.Headers {
 		normalizedKey := http.CanonicalHeaderKey(key)
 		for _, value := range values {
@@ -119,12 +362,108 @@ func (c *client) buildHTTPRequest(ctx context.Context, request *Request) (*http.
 		}
 	}
 
+	if contentType != "" && httpRequest.Header.Get("Content-Type") == "" {
+		httpRequest.Header.Set("Content-Type", contentType)
+	}
+
+	for key, values := range c.defaultHeaders {
+		if _, ok := httpRequest.Header[key]; !ok {
+			httpRequest.Header[key] = slices.Clone(values)
+		}
+	}
+
+	if request.ContentEncoding != "" && requestBody != nil {
+		httpRequest.Header.Set("Content-Encoding", request.ContentEncoding)
+	}
+
 	return httpRequest, nil
 }
 
-func (c *client) validateResponse(httpResponse *http.Response, request *Request) error {
-	if len(request.ExpectedStatusCodes) > 0 && !slices.Contains(request.ExpectedStatusCodes, httpResponse.StatusCode) {
-		return errors.Errorf("unexpected status code: %d", httpResponse.StatusCode)
+// encodeRequestBody returns the body of request and the content type it is encoded with, if known.
+// GET requests are sent without a body.
+func encodeRequestBody(request *Request) (io.Reader, string, error) {
+	if request.Method == http.MethodGet {
+		return nil, "", nil
+	}
+
+	if request.JSON != nil {
+		body, err := json.Marshal(request.JSON)
+		if err != nil {
+			return nil, "", errors.WithStack(err)
+		}
+
+		return bytes.NewReader(body), "application/json", nil
+	}
+
+	if request.XML != nil {
+		body, err := xml.Marshal(request.XML)
+		if err != nil {
+			return nil, "", errors.WithStack(err)
+		}
+
+		return bytes.NewReader(append([]byte(xml.Header), body...)), "application/xml; charset=utf-8", nil
+	}
+
+	if request.Form != nil {
+		return strings.NewReader(url.Values(request.Form).Encode()), "application/x-www-form-urlencoded", nil
+	}
+
+	if request.Multipart != nil {
+		return request.Multipart.newReader(), request.Multipart.ContentType(), nil
+	}
+
+	return request.Body, "", nil
+}
+
+// mergeQuery adds the query parameters of request and the default query parameters of the client to requestURL.
+func (c *client) mergeQuery(requestURL *url.URL, request *Request) {
+	if len(request.Query) == 0 && len(c.defaultQuery) == 0 {
+		return
+	}
+
+	query := requestURL.Query()
+	for key, values := range request.Query {
+		query[key] = append(query[key], values...)
+	}
+
+	for key, values := range c.defaultQuery {
+		if !query.Has(key) {
+			query[key] = slices.Clone(values)
+		}
+	}
+
+	requestURL.RawQuery = query.Encode()
+}
+
+// canRewind reports whether httpRequest can be sent again with an identical body.
+func canRewind(httpRequest *http.Request) bool {
+	return httpRequest.Body == nil || httpRequest.Body == http.NoBody || httpRequest.GetBody != nil
+}
+
+// rewindRequest returns a copy of httpRequest with a fresh body so that it can be sent again.
+func rewindRequest(httpRequest *http.Request) (*http.Request, error) {
+	clone := httpRequest.Clone(httpRequest.Context())
+	if httpRequest.Body == nil || httpRequest.Body == http.NoBody {
+		return clone, nil
+	}
+
+	if httpRequest.GetBody == nil {
+		return nil, errors.New("request body cannot be rewound")
+	}
+
+	body, err := httpRequest.GetBody()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	clone.Body = body
+
+	return clone, nil
+}
+
+func (c *client) validateResponse(httpRequest *http.Request, httpResponse *http.Response, request *Request) error {
+	if len(request.ExpectedStatusCodes) > 0 && !expectsStatusCode(request.ExpectedStatusCodes, httpResponse.StatusCode) {
+		return newStatusCodeError(httpResponse.StatusCode, c.newAPIError(httpRequest, httpResponse, request))
 	}
 
 	contentType := httpResponse.Header.Get("Content-Type")
@@ -134,8 +473,8 @@ func (c *client) validateResponse(httpResponse *http.Response, request *Request)
 			return strings.HasPrefix(strings.ToLower(contentType), strings.ToLower(prefix))
 		},
 	) {
-		return errors.Errorf("unexpected content type: %s", contentType)
+		return newError(CategoryValidation, errors.WithStack(&ContentTypeError{ContentType: contentType}))
 	}
 
-	return nil
+	return c.runResponseValidators(httpResponse, request)
 }
@@ -3,8 +3,10 @@ package webapiclient
 import (
 	"bytes"
 	"context"
+	"encoding/xml"
 	"io"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -40,7 +42,7 @@ func TestClientImpl_New(t *testing.T) {
 			},
 			want: &client{
 				do:      mockDoFunc,
-				baseURL: "http://example.com",
+				baseURL: &url.URL{Scheme: "http", Host: "example.com"},
 			},
 		},
 	}
@@ -48,16 +50,195 @@ func TestClientImpl_New(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := NewClient(tt.args.do, tt.args.baseURL)
+			got := NewClient(tt.args.baseURL, WithDoFunc(tt.args.do))
 			require.NotNil(t, got)
-			assertEqual(t, tt.want, got, cmp.AllowUnexported(client{}), cmpopts.IgnoreFields(client{}, "do"))
+			assertEqual(t, tt.want, got, cmp.AllowUnexported(client{}), cmpopts.IgnoreFields(client{}, "do", "chained", "urlBuilder", "rateLimits"))
 
 			clientImpl := got.(*client)
 			assert.NotNil(t, clientImpl.do)
+			assert.NotNil(t, clientImpl.urlBuilder)
+		})
+	}
+}
+
+func TestTryNewClient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		baseURL string
+		want    []FieldError
+	}{
+		{name: "success: absolute URL", baseURL: "https://api.example.com/v1/"},
+		{name: "failure: malformed URL", baseURL: "http://[::1", want: []FieldError{{Path: "BaseURL", Reason: "must be a valid URL"}}},
+		{name: "failure: relative URL", baseURL: "/v1/", want: []FieldError{{Path: "BaseURL", Reason: "must be an absolute URL with a host"}}},
+		{name: "failure: empty URL", baseURL: "", want: []FieldError{{Path: "BaseURL", Reason: "must be an absolute URL with a host"}}},
+		{name: "failure: missing host", baseURL: "http:///v1/", want: []FieldError{{Path: "BaseURL", Reason: "must be an absolute URL with a host"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := TryNewClient(tt.baseURL)
+			if tt.want == nil {
+				require.NoError(t, err)
+				assert.NotNil(t, got)
+
+				return
+			}
+
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			assert.Equal(t, tt.want, validationError.Fields)
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func TestClientImpl_DryRun_Query(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		path  string
+		query map[string][]string
+		want  string
+	}{
+		{
+			name:  "success: query is encoded",
+			path:  "/search",
+			query: map[string][]string{"q": {"a&b c"}, "tag": {"x", "y"}},
+			want:  "http://example.com/search?q=a%26b+c&tag=x&tag=y",
+		},
+		{
+			name:  "success: merged with the query of the path",
+			path:  "/search?tag=x",
+			query: map[string][]string{"tag": {"y"}, "page": {"2"}},
+			want:  "http://example.com/search?page=2&tag=x&tag=y",
+		},
+		{
+			name: "success: no query",
+			path: "/search?b=1&a=2",
+			want: "http://example.com/search?b=1&a=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com")
+
+			got, err := client.DryRun(context.Background(), &Request{Method: http.MethodGet, Path: tt.path, Query: tt.query}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.URL.String())
+		})
+	}
+}
+
+func TestClientImpl_DryRun_Body(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		request         *Request
+		wantBody        string
+		wantContentType string
+		wantErr         bool
+	}{
+		{
+			name:            "success: JSON body",
+			request:         &Request{Method: http.MethodPost, Path: "/users", JSON: map[string]string{"name": "Jane"}},
+			wantBody:        `{"name":"Jane"}`,
+			wantContentType: "application/json",
+		},
+		{
+			name: "success: content type of the request takes precedence",
+			request: &Request{
+				Method:  http.MethodPatch,
+				Path:    "/users/1",
+				Headers: map[string][]string{"content-type": {"application/merge-patch+json"}},
+				JSON:    map[string]any{"name": nil},
+			},
+			wantBody:        `{"name":null}`,
+			wantContentType: "application/merge-patch+json",
+		},
+		{
+			name:    "success: GET request without body",
+			request: &Request{Method: http.MethodGet, Path: "/users", JSON: map[string]string{"name": "Jane"}},
+		},
+		{
+			name: "success: XML body",
+			request: &Request{Method: http.MethodPost, Path: "/users", XML: struct {
+				XMLName struct{} `xml:"user"`
+				Name    string   `xml:"name"`
+			}{Name: "Jane"}},
+			wantBody:        xml.Header + "<user><name>Jane</name></user>",
+			wantContentType: "application/xml; charset=utf-8",
+		},
+		{
+			name: "success: form body",
+			request: &Request{
+				Method: http.MethodPost,
+				Path:   "/token",
+				Form:   url.Values{"grant_type": {"client_credentials"}, "scope": {"read write"}},
+			},
+			wantBody:        "grant_type=client_credentials&scope=read+write",
+			wantContentType: "application/x-www-form-urlencoded",
+		},
+		{
+			name:    "failure: unsupported value",
+			request: &Request{Method: http.MethodPost, Path: "/users", JSON: make(chan int)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewClient("http://example.com").DryRun(context.Background(), tt.request, nil)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, CategoryValidation, ClassifyError(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContentType, got.Header.Get("Content-Type"))
+
+			if tt.wantBody == "" {
+				assert.Nil(t, got.Body)
+
+				return
+			}
+
+			body, err := io.ReadAll(got.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, string(body))
+			assert.NotNil(t, got.GetBody)
 		})
 	}
 }
 
+func TestClientImpl_Do_InvalidBaseURL(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := NewClient("http://[::1", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}))
+
+	for range 2 {
+		_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+		require.Error(t, err)
+		assert.Equal(t, CategoryValidation, ClassifyError(err))
+	}
+
+	assert.Zero(t, calls)
+}
+
 func assertEqual(t *testing.T, want any, got any, options ...cmp.Option) {
 	t.Helper()
 	if diff := cmp.Diff(want, got, options...); diff != "" {
@@ -311,7 +492,7 @@ func TestClientImpl_Do(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			client := NewClient(tt.fields.do, tt.fields.baseURL)
+			client := NewClient(tt.fields.baseURL, WithDoFunc(tt.fields.do))
 
 			got, err := client.Do(tt.args.ctx, tt.args.request, tt.args.edit)
 			if tt.want.err {
@@ -331,3 +512,174 @@ func TestClientImpl_Do(t *testing.T) {
 		})
 	}
 }
+
+func TestClientImpl_DryRun(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		request *Request
+		edit    EditRequestFunc
+	}
+	type want struct {
+		err     bool
+		method  string
+		url     string
+		headers http.Header
+		body    []byte
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "success: POST request",
+			args: args{
+				request: &Request{
+					Method: http.MethodPost,
+					Path:   "/test",
+					Headers: map[string][]string{
+						"content-type": {"application/json"},
+					},
+					Body: bytes.NewReader([]byte(`{"test":"data"}`)),
+				},
+				edit: func(req *http.Request) error {
+					req.Header.Set("X-Signature", "signed")
+					return nil
+				},
+			},
+			want: want{
+				method: http.MethodPost,
+				url:    "http://example.com/test",
+				headers: http.Header{
+					"Content-Type": {"application/json"},
+					"X-Signature":  {"signed"},
+				},
+				body: []byte(`{"test":"data"}`),
+			},
+		},
+		{
+			name: "failure: invalid request",
+			args: args{
+				request: &Request{Method: "GE T", Path: "/test"},
+			},
+			want: want{err: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+				t.Fatal("request must not be sent")
+				return nil, nil
+			}))
+
+			got, err := client.DryRun(context.Background(), tt.args.request, tt.args.edit)
+			if tt.want.err {
+				assert.Equal(t, CategoryValidation, ClassifyError(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.method, got.Method)
+			assert.Equal(t, tt.want.url, got.URL.String())
+			assert.Equal(t, tt.want.headers, got.Header)
+
+			body, err := io.ReadAll(got.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.body, body)
+		})
+	}
+}
+
+func TestClientImpl_DryRun_Middlewares(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("request must not be sent")
+		return nil, nil
+	}))
+	client.Use(func(next DoFunc) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			signed := req.Clone(req.Context())
+			signed.Header.Set("X-Signature", "signed")
+
+			return next(signed)
+		}
+	})
+
+	got, err := client.DryRun(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "signed", got.Header.Get("X-Signature"))
+}
+
+// doAllocationBudget is the maximum number of allocations of a plain Do call, guarding the fast path against regressions.
+const doAllocationBudget = 16
+
+//nolint:paralleltest // allocations are counted process-wide
+func TestClientImpl_Do_AllocationBudget(t *testing.T) {
+	client := NewClient("http://example.com/api/", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), WithUserAgent("bench/1.0"))
+	request := &Request{
+		Method:              http.MethodGet,
+		Path:                "users/1",
+		Headers:             map[string][]string{"Accept": {"application/json"}},
+		ExpectedStatusCodes: []int{http.StatusOK},
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = client.Do(context.Background(), request, nil)
+	})
+	assert.LessOrEqual(t, allocs, float64(doAllocationBudget))
+}
+
+func BenchmarkClientImpl_Do(b *testing.B) {
+	body := []byte(`{"ok":true}`)
+	client := NewClient("http://example.com/api/", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	}), WithUserAgent("bench/1.0"))
+	request := &Request{
+		Method:               http.MethodGet,
+		Path:                 "users/1",
+		Headers:              map[string][]string{"Accept": {"application/json"}},
+		ExpectedStatusCodes:  []int{http.StatusOK},
+		ExpectedContentTypes: []string{"application/json"},
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		response, err := client.Do(ctx, request, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		_ = response.Body.Close()
+	}
+}
+
+func BenchmarkClientImpl_buildHTTPRequest(b *testing.B) {
+	c := NewClient("http://example.com/api/", WithUserAgent("bench/1.0")).(*client)
+	request := &Request{
+		Method:  http.MethodGet,
+		Path:    "users/1",
+		Headers: map[string][]string{"Accept": {"application/json"}},
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_, err := c.buildHTTPRequest(ctx, request)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package webapiclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// parseServerDate parses the Date header and returns the server time together with
+// its skew relative to now. A positive skew means the server clock is ahead.
+func parseServerDate(header http.Header, now time.Time) (time.Time, time.Duration) {
+	value := header.Get("Date")
+	if value == "" {
+		return time.Time{}, 0
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, 0
+	}
+
+	return date, date.Sub(now.Truncate(time.Second))
+}
@@ -0,0 +1,55 @@
+package webapiclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServerDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+
+	type want struct {
+		date time.Time
+		skew time.Duration
+	}
+	tests := []struct {
+		name   string
+		header http.Header
+		want   want
+	}{
+		{
+			name:   "success: server ahead",
+			header: http.Header{"Date": []string{"Mon, 01 Jan 2024 00:00:30 GMT"}},
+			want:   want{date: time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC), skew: 30 * time.Second},
+		},
+		{
+			name:   "success: server behind",
+			header: http.Header{"Date": []string{"Sun, 31 Dec 2023 23:58:00 GMT"}},
+			want:   want{date: time.Date(2023, 12, 31, 23, 58, 0, 0, time.UTC), skew: -2 * time.Minute},
+		},
+		{
+			name:   "success: missing Date header",
+			header: http.Header{},
+			want:   want{},
+		},
+		{
+			name:   "success: malformed Date header",
+			header: http.Header{"Date": []string{"yesterday"}},
+			want:   want{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			date, skew := parseServerDate(tt.header, now)
+			assert.True(t, tt.want.date.Equal(date))
+			assert.Equal(t, tt.want.skew, skew)
+		})
+	}
+}
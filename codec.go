@@ -0,0 +1,75 @@
+package webapiclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// CodecFunc returns a writer compressing everything written to it into w for a Content-Encoding.
+type CodecFunc func(w io.Writer) (io.WriteCloser, error)
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]CodecFunc{
+		"gzip": func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		"deflate": func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+	}
+)
+
+// RegisterCodec registers codec for the Content-Encoding encoding, replacing any codec registered before.
+// gzip and deflate are registered by default; codecs such as zstd, br or snappy can be registered
+// using third party compression packages.
+func RegisterCodec(encoding string, codec CodecFunc) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[strings.ToLower(encoding)] = codec
+}
+
+func lookupCodec(encoding string) (CodecFunc, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	codec, ok := codecs[strings.ToLower(encoding)]
+
+	return codec, ok
+}
+
+// compressBody compresses body with the codec registered for encoding.
+func compressBody(encoding string, body io.Reader) (*bytes.Reader, error) {
+	codec, ok := lookupCodec(encoding)
+	if !ok {
+		return nil, errors.Errorf("unsupported content encoding: %s", encoding)
+	}
+
+	var buffer bytes.Buffer
+
+	writer, err := codec(&buffer)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	_, err = io.Copy(writer, body)
+	if err != nil {
+		_ = writer.Close()
+
+		return nil, errors.WithStack(err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return bytes.NewReader(buffer.Bytes()), nil
+}
@@ -0,0 +1,132 @@
+package webapiclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reverseWriter struct {
+	w      io.Writer
+	buffer []byte
+}
+
+func (r *reverseWriter) Write(p []byte) (int, error) {
+	r.buffer = append(r.buffer, p...)
+
+	return len(p), nil
+}
+
+func (r *reverseWriter) Close() error {
+	reversed := make([]byte, 0, len(r.buffer))
+	for i := len(r.buffer) - 1; i >= 0; i-- {
+		reversed = append(reversed, r.buffer[i])
+	}
+
+	_, err := r.w.Write(reversed)
+
+	return err
+}
+
+func TestRegisterCodec(t *testing.T) {
+	t.Parallel()
+
+	RegisterCodec("X-Reverse", func(w io.Writer) (io.WriteCloser, error) {
+		return &reverseWriter{w: w}, nil
+	})
+
+	codec, ok := lookupCodec("x-reverse")
+	require.True(t, ok)
+
+	var buffer bytes.Buffer
+	writer, err := codec(&buffer)
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	assert.Equal(t, "cba", buffer.String())
+}
+
+func TestClientImpl_Do_ContentEncoding(t *testing.T) {
+	t.Parallel()
+
+	decoders := map[string]func(r io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		"deflate": func(r io.Reader) (io.Reader, error) {
+			return zlib.NewReader(r)
+		},
+	}
+
+	for encoding, decode := range decoders {
+		t.Run("success: "+encoding, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, encoding, req.Header.Get("Content-Encoding"))
+				assert.NotNil(t, req.GetBody)
+
+				reader, err := decode(req.Body)
+				require.NoError(t, err)
+				body, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				assert.Equal(t, []byte(`{"test":"data"}`), body)
+
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+			}))
+
+			response, err := client.Do(context.Background(), &Request{
+				Method:          http.MethodPost,
+				Path:            "/test",
+				Body:            strings.NewReader(`{"test":"data"}`),
+				ContentEncoding: encoding,
+			}, nil)
+			require.NoError(t, err)
+			_ = response.Body.Close()
+		})
+	}
+
+	t.Run("success: no body", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			assert.Empty(t, req.Header.Get("Content-Encoding"))
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}))
+
+		response, err := client.Do(context.Background(), &Request{
+			Method:          http.MethodGet,
+			Path:            "/test",
+			ContentEncoding: "gzip",
+		}, nil)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+	})
+
+	t.Run("failure: unregistered encoding", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("http://example.com")
+
+		_, err := client.Do(context.Background(), &Request{
+			Method:          http.MethodPost,
+			Path:            "/test",
+			Body:            strings.NewReader("data"),
+			ContentEncoding: "unknown",
+		}, nil)
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		assert.Equal(t, []FieldError{{Path: "ContentEncoding", Reason: "must be a registered content encoding"}}, validationError.Fields)
+	})
+}
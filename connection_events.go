@@ -0,0 +1,124 @@
+package webapiclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DialContextFunc is a function type for dialing network connections, as used by http.Transport.DialContext.
+type DialContextFunc func(ctx context.Context, network string, address string) (net.Conn, error)
+
+// ConnectionInfo describes a connection at the socket level.
+type ConnectionInfo struct {
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+	// IdleTime is how long a reused connection was idle.
+	IdleTime time.Duration
+	// TLS is the state of the TLS connection, or nil for plain connections and for closed events.
+	TLS *tls.ConnectionState
+}
+
+// TLSVersion returns the name of the negotiated TLS version, or an empty string for plain connections.
+func (i ConnectionInfo) TLSVersion() string {
+	if i.TLS == nil {
+		return ""
+	}
+
+	return tls.VersionName(i.TLS.Version)
+}
+
+// CipherSuite returns the name of the negotiated cipher suite, or an empty string for plain connections.
+func (i ConnectionInfo) CipherSuite() string {
+	if i.TLS == nil {
+		return ""
+	}
+
+	return tls.CipherSuiteName(i.TLS.CipherSuite)
+}
+
+// ConnectionEvents are callbacks invoked when connections are established, reused and closed.
+// Any callback may be nil.
+type ConnectionEvents struct {
+	OnEstablished func(info ConnectionInfo)
+	OnReused      func(info ConnectionInfo)
+	OnClosed      func(info ConnectionInfo)
+}
+
+// Wrap wraps do so that OnEstablished and OnReused are invoked for the connection used by each request.
+func (e *ConnectionEvents) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		trace := &httptrace.ClientTrace{
+			GotConn: func(gotConnInfo httptrace.GotConnInfo) {
+				info := ConnectionInfo{
+					LocalAddr:  gotConnInfo.Conn.LocalAddr(),
+					RemoteAddr: gotConnInfo.Conn.RemoteAddr(),
+					IdleTime:   gotConnInfo.IdleTime,
+				}
+
+				if tlsConn, ok := gotConnInfo.Conn.(*tls.Conn); ok {
+					state := tlsConn.ConnectionState()
+					info.TLS = &state
+				}
+
+				if gotConnInfo.Reused {
+					if e.OnReused != nil {
+						e.OnReused(info)
+					}
+
+					return
+				}
+
+				if e.OnEstablished != nil {
+					e.OnEstablished(info)
+				}
+			},
+		}
+
+		ctx := httptrace.WithClientTrace(httpRequest.Context(), trace)
+
+		return do(httpRequest.WithContext(ctx))
+	}
+}
+
+// WrapDialContext wraps dial so that OnClosed is invoked when a dialed connection is closed.
+func (e *ConnectionEvents) WrapDialContext(dial DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return &eventConn{Conn: conn, events: e}, nil
+	}
+}
+
+// eventConn is a net.Conn that reports its closure to ConnectionEvents.
+type eventConn struct {
+	net.Conn
+
+	events *ConnectionEvents
+	once   sync.Once
+}
+
+// Close closes the connection and invokes OnClosed once.
+func (c *eventConn) Close() error {
+	err := c.Conn.Close()
+
+	c.once.Do(func() {
+		if c.events.OnClosed != nil {
+			c.events.OnClosed(ConnectionInfo{
+				LocalAddr:  c.Conn.LocalAddr(),
+				RemoteAddr: c.Conn.RemoteAddr(),
+			})
+		}
+	})
+
+	return err //nolint:wrapcheck
+}
@@ -0,0 +1,78 @@
+package webapiclient
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type connectionEventRecorder struct {
+	mu     sync.Mutex
+	events []string
+	infos  []ConnectionInfo
+}
+
+func (r *connectionEventRecorder) record(event string) func(info ConnectionInfo) {
+	return func(info ConnectionInfo) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.events = append(r.events, event)
+		r.infos = append(r.infos, info)
+	}
+}
+
+func TestConnectionEvents(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	recorder := &connectionEventRecorder{}
+	events := &ConnectionEvents{
+		OnEstablished: recorder.record("established"),
+		OnReused:      recorder.record("reused"),
+		OnClosed:      recorder.record("closed"),
+	}
+
+	transport, ok := server.Client().Transport.(*http.Transport)
+	require.True(t, ok)
+	transport = transport.Clone()
+	transport.DialContext = events.WrapDialContext((&net.Dialer{}).DialContext)
+	httpClient := &http.Client{Transport: transport}
+
+	client := NewClient(server.URL, WithDoFunc(events.Wrap(httpClient.Do)))
+	for range 2 {
+		response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/"}, nil)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, response.Body)
+		_ = response.Body.Close()
+	}
+	transport.CloseIdleConnections()
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	require.Equal(t, []string{"established", "reused", "closed"}, recorder.events)
+
+	established := recorder.infos[0]
+	assert.Equal(t, server.Listener.Addr().String(), established.RemoteAddr.String())
+	require.NotNil(t, established.TLS)
+	assert.Equal(t, tls.VersionName(established.TLS.Version), established.TLSVersion())
+	assert.NotEmpty(t, established.CipherSuite())
+
+	assert.Equal(t, server.Listener.Addr().String(), recorder.infos[1].RemoteAddr.String())
+	assert.Equal(t, server.Listener.Addr().String(), recorder.infos[2].RemoteAddr.String())
+	assert.Nil(t, recorder.infos[2].TLS)
+	assert.Empty(t, recorder.infos[2].TLSVersion())
+}
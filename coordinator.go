@@ -0,0 +1,126 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultThrottleDelay is the delay applied after a 429 response without a usable Retry-After header.
+const defaultThrottleDelay = time.Second
+
+// Compile-time check to ensure LocalRateCoordinator implements RateCoordinator interface.
+var _ RateCoordinator = (*LocalRateCoordinator)(nil)
+
+// RateCoordinator coordinates a single rate budget shared by every caller of a vendor.
+//
+// Implementations backed by a shared store (for example a Redis key holding the
+// throttle deadline with a matching expiry) let multiple processes honor the
+// same budget instead of pacing each process independently.
+type RateCoordinator interface {
+	// Acquire blocks until a request for the key may be sent or the context is done.
+	Acquire(ctx context.Context, key string) error
+	// Throttle records that no request for the key should be sent before until.
+	Throttle(ctx context.Context, key string, until time.Time) error
+}
+
+// LocalRateCoordinator is an in-process RateCoordinator.
+type LocalRateCoordinator struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// NewLocalRateCoordinator creates a new in-process RateCoordinator.
+func NewLocalRateCoordinator() *LocalRateCoordinator {
+	return &LocalRateCoordinator{
+		until: map[string]time.Time{},
+	}
+}
+
+// Acquire blocks until the throttle window for the key has passed or the context is done.
+func (c *LocalRateCoordinator) Acquire(ctx context.Context, key string) error {
+	for {
+		c.mu.Lock()
+		wait := time.Until(c.until[key])
+		c.mu.Unlock()
+
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return errors.WithStack(ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Throttle extends the throttle window for the key up to until.
+func (c *LocalRateCoordinator) Throttle(_ context.Context, key string, until time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if until.After(c.until[key]) {
+		c.until[key] = until
+	}
+
+	return nil
+}
+
+// CoordinateRate wraps do so that requests wait for the shared rate budget of their host
+// and requests rejected with 429 Too Many Requests are queued and retried up to maxRetries times.
+func CoordinateRate(do DoFunc, coordinator RateCoordinator, maxRetries int) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		ctx := httpRequest.Context()
+		key := httpRequest.URL.Host
+
+		for attempt := 0; ; attempt++ {
+			err := coordinator.Acquire(ctx, key)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			request := httpRequest
+			if attempt > 0 {
+				request, err = rewindRequest(httpRequest)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
+			}
+
+			httpResponse, err := do(request)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			if httpResponse.StatusCode != http.StatusTooManyRequests {
+				return httpResponse, nil
+			}
+
+			delay, ok := parseRetryAfter(httpResponse.Header.Get("Retry-After"), time.Now())
+			if !ok {
+				delay = defaultThrottleDelay
+			}
+
+			err = coordinator.Throttle(ctx, key, time.Now().Add(delay))
+			if err != nil {
+				_ = httpResponse.Body.Close()
+
+				return nil, errors.WithStack(err)
+			}
+
+			if attempt >= maxRetries || !canRewind(httpRequest) {
+				return httpResponse, nil
+			}
+
+			_ = httpResponse.Body.Close()
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRateCoordinator_Acquire(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: not throttled", func(t *testing.T) {
+		t.Parallel()
+
+		coordinator := NewLocalRateCoordinator()
+		err := coordinator.Acquire(context.Background(), "example.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("success: waits for throttle window", func(t *testing.T) {
+		t.Parallel()
+
+		coordinator := NewLocalRateCoordinator()
+		until := time.Now().Add(20 * time.Millisecond)
+		require.NoError(t, coordinator.Throttle(context.Background(), "example.com", until))
+
+		err := coordinator.Acquire(context.Background(), "example.com")
+		assert.NoError(t, err)
+		assert.False(t, time.Now().Before(until))
+	})
+
+	t.Run("success: other keys are not throttled", func(t *testing.T) {
+		t.Parallel()
+
+		coordinator := NewLocalRateCoordinator()
+		require.NoError(t, coordinator.Throttle(context.Background(), "example.com", time.Now().Add(time.Hour)))
+
+		err := coordinator.Acquire(context.Background(), "example.org")
+		assert.NoError(t, err)
+	})
+
+	t.Run("failure: context canceled", func(t *testing.T) {
+		t.Parallel()
+
+		coordinator := NewLocalRateCoordinator()
+		require.NoError(t, coordinator.Throttle(context.Background(), "example.com", time.Now().Add(time.Hour)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := coordinator.Acquire(ctx, "example.com")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestCoordinateRate(t *testing.T) {
+	t.Parallel()
+
+	newResponse := func(status int, header http.Header) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header:     header,
+			Body:       io.NopCloser(bytes.NewReader([]byte("body"))),
+		}
+	}
+
+	type args struct {
+		body       io.Reader
+		statuses   []int
+		maxRetries int
+	}
+	type want struct {
+		status int
+		calls  int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "success: no throttling",
+			args: args{statuses: []int{http.StatusOK}, maxRetries: 3},
+			want: want{status: http.StatusOK, calls: 1},
+		},
+		{
+			name: "success: retried after 429",
+			args: args{
+				body:       strings.NewReader("payload"),
+				statuses:   []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK},
+				maxRetries: 3,
+			},
+			want: want{status: http.StatusOK, calls: 3},
+		},
+		{
+			name: "success: retries exhausted",
+			args: args{
+				statuses:   []int{http.StatusTooManyRequests, http.StatusTooManyRequests},
+				maxRetries: 1,
+			},
+			want: want{status: http.StatusTooManyRequests, calls: 2},
+		},
+		{
+			name: "success: body cannot be rewound",
+			args: args{
+				body:       io.MultiReader(strings.NewReader("payload")),
+				statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+				maxRetries: 3,
+			},
+			want: want{status: http.StatusTooManyRequests, calls: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			do := func(req *http.Request) (*http.Response, error) {
+				if req.Body != nil {
+					body, err := io.ReadAll(req.Body)
+					require.NoError(t, err)
+					assert.Equal(t, []byte("payload"), body)
+				}
+
+				status := tt.args.statuses[calls]
+				calls++
+
+				return newResponse(status, http.Header{"Retry-After": []string{"0"}}), nil
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com/test", tt.args.body)
+			require.NoError(t, err)
+
+			got, err := CoordinateRate(do, NewLocalRateCoordinator(), tt.args.maxRetries)(req)
+			require.NoError(t, err)
+			defer func() {
+				_ = got.Body.Close()
+			}()
+
+			assert.Equal(t, tt.want.status, got.StatusCode)
+			assert.Equal(t, tt.want.calls, calls)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package webapiclient
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// costKeyContextKey is the context key of the CostKey attached with WithCostKey.
+type costKeyContextKey struct{}
+
+// CostKey identifies the operation and tenant to which the cost of a request is attributed.
+type CostKey struct {
+	Operation string
+	Tenant    string
+}
+
+// WithCostKey returns a copy of ctx that attributes the cost of requests sent with it to key.
+func WithCostKey(ctx context.Context, key CostKey) context.Context {
+	return context.WithValue(ctx, costKeyContextKey{}, key)
+}
+
+// costKeyOf returns the CostKey attached to the context of httpRequest, defaulting the
+// operation to the method and path of the request.
+func costKeyOf(httpRequest *http.Request) CostKey {
+	key, _ := httpRequest.Context().Value(costKeyContextKey{}).(CostKey)
+	if key.Operation == "" {
+		key.Operation = httpRequest.Method + " " + httpRequest.URL.Path
+	}
+
+	return key
+}
+
+// CostFunc returns the cost of a request from the request and its response.
+type CostFunc func(httpRequest *http.Request, httpResponse *http.Response) float64
+
+// HeaderCost returns a CostFunc reading the cost from a numeric response header, such as a vendor billing header.
+// Responses without a valid value cost nothing.
+func HeaderCost(header string) CostFunc {
+	return func(_ *http.Request, httpResponse *http.Response) float64 {
+		cost, err := strconv.ParseFloat(strings.TrimSpace(httpResponse.Header.Get(header)), 64)
+		if err != nil {
+			return 0
+		}
+
+		return cost
+	}
+}
+
+// StaticCost returns a CostFunc looking up the cost of each operation in table.
+// Operations missing from the table cost nothing.
+func StaticCost(table map[string]float64) CostFunc {
+	return func(httpRequest *http.Request, _ *http.Response) float64 {
+		return table[costKeyOf(httpRequest).Operation]
+	}
+}
+
+// CostTotal is the aggregated cost of the requests attributed to a CostKey.
+type CostTotal struct {
+	Requests int64
+	Cost     float64
+}
+
+// CostTracker aggregates the cost of requests per operation and tenant.
+type CostTracker struct {
+	cost CostFunc
+
+	mu     sync.Mutex
+	totals map[CostKey]CostTotal
+}
+
+// NewCostTracker creates a new CostTracker computing the cost of each request with cost.
+func NewCostTracker(cost CostFunc) *CostTracker {
+	return &CostTracker{
+		cost:   cost,
+		totals: map[CostKey]CostTotal{},
+	}
+}
+
+// Wrap wraps do so that the cost of every response is attributed to the CostKey of its request.
+func (t *CostTracker) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		httpResponse, err := do(httpRequest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		key := costKeyOf(httpRequest)
+		cost := t.cost(httpRequest, httpResponse)
+
+		t.mu.Lock()
+		total := t.totals[key]
+		total.Requests++
+		total.Cost += cost
+		t.totals[key] = total
+		t.mu.Unlock()
+
+		return httpResponse, nil
+	}
+}
+
+// Totals returns a snapshot of the aggregated cost per operation and tenant.
+func (t *CostTracker) Totals() map[CostKey]CostTotal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return maps.Clone(t.totals)
+}
@@ -0,0 +1,93 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostTracker_Wrap(t *testing.T) {
+	t.Parallel()
+
+	newDo := func(header http.Header) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		}
+	}
+
+	type call struct {
+		ctx  context.Context
+		path string
+	}
+	tests := []struct {
+		name   string
+		cost   CostFunc
+		header http.Header
+		calls  []call
+		want   map[CostKey]CostTotal
+	}{
+		{
+			name:   "success: header cost per tenant",
+			cost:   HeaderCost("X-Billing-Units"),
+			header: http.Header{"X-Billing-Units": []string{"2.5"}},
+			calls: []call{
+				{ctx: WithCostKey(context.Background(), CostKey{Operation: "search", Tenant: "a"}), path: "/search"},
+				{ctx: WithCostKey(context.Background(), CostKey{Operation: "search", Tenant: "a"}), path: "/search"},
+				{ctx: WithCostKey(context.Background(), CostKey{Operation: "search", Tenant: "b"}), path: "/search"},
+			},
+			want: map[CostKey]CostTotal{
+				{Operation: "search", Tenant: "a"}: {Requests: 2, Cost: 5},
+				{Operation: "search", Tenant: "b"}: {Requests: 1, Cost: 2.5},
+			},
+		},
+		{
+			name:   "success: missing header costs nothing",
+			cost:   HeaderCost("X-Billing-Units"),
+			header: http.Header{},
+			calls:  []call{{ctx: context.Background(), path: "/items"}},
+			want: map[CostKey]CostTotal{
+				{Operation: "GET /items"}: {Requests: 1, Cost: 0},
+			},
+		},
+		{
+			name:   "success: static cost table",
+			cost:   StaticCost(map[string]float64{"GET /items": 0.1, "translate": 3}),
+			header: http.Header{},
+			calls: []call{
+				{ctx: context.Background(), path: "/items"},
+				{ctx: WithCostKey(context.Background(), CostKey{Operation: "translate", Tenant: "t"}), path: "/v2/translate"},
+				{ctx: context.Background(), path: "/unknown"},
+			},
+			want: map[CostKey]CostTotal{
+				{Operation: "GET /items"}:             {Requests: 1, Cost: 0.1},
+				{Operation: "translate", Tenant: "t"}: {Requests: 1, Cost: 3},
+				{Operation: "GET /unknown"}:           {Requests: 1, Cost: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := NewCostTracker(tt.cost)
+			client := NewClient("http://example.com", WithDoFunc(tracker.Wrap(newDo(tt.header))))
+
+			for _, c := range tt.calls {
+				response, err := client.Do(c.ctx, &Request{Method: http.MethodGet, Path: c.path}, nil)
+				require.NoError(t, err)
+				_ = response.Body.Close()
+			}
+
+			assert.Equal(t, tt.want, tracker.Totals())
+		})
+	}
+}
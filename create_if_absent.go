@@ -0,0 +1,71 @@
+package webapiclient
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAlreadyExists is matched by the errors of CreateIfAbsent requests rejected because the resource exists.
+var ErrAlreadyExists = errors.New("already exists")
+
+// AlreadyExistsError is returned by CreateIfAbsent when the server rejected the request with
+// 412 Precondition Failed because the resource already exists.
+type AlreadyExistsError struct {
+	// Path is the path of the request.
+	Path string
+}
+
+// Error returns a message naming the existing resource.
+func (e *AlreadyExistsError) Error() string {
+	return "already exists: " + e.Path
+}
+
+// Is reports whether target is ErrAlreadyExists.
+func (e *AlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists //nolint:errorlint
+}
+
+// CreateIfAbsent sends request with the header If-None-Match: * so that the resource is only created
+// when it does not exist yet, and returns an error matching ErrAlreadyExists, classified as http_status,
+// when the server answers 412 Precondition Failed. The method defaults to PUT; request is not modified.
+func CreateIfAbsent(ctx context.Context, client Client, request *Request) (*Response, error) {
+	createRequest := *request
+	createRequest.Headers = maps.Clone(request.Headers)
+
+	if createRequest.Headers == nil {
+		createRequest.Headers = map[string][]string{}
+	}
+
+	for name := range createRequest.Headers {
+		if http.CanonicalHeaderKey(name) == "If-None-Match" {
+			delete(createRequest.Headers, name)
+		}
+	}
+
+	createRequest.Headers["If-None-Match"] = []string{"*"}
+
+	if createRequest.Method == "" {
+		createRequest.Method = http.MethodPut
+	}
+
+	if len(createRequest.ExpectedStatusCodes) > 0 {
+		createRequest.ExpectedStatusCodes = append(slices.Clone(request.ExpectedStatusCodes), http.StatusPreconditionFailed)
+	}
+
+	response, err := client.Do(ctx, &createRequest, nil)
+	if err != nil {
+		return response, errors.WithStack(err)
+	}
+
+	if response.StatusCode == http.StatusPreconditionFailed {
+		_ = response.Body.Close()
+
+		return nil, errors.WithStack(newStatusCodeError(response.StatusCode, &AlreadyExistsError{Path: request.Path}))
+	}
+
+	return response, nil
+}
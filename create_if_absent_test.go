@@ -0,0 +1,96 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		request      *Request
+		status       int
+		wantMethod   string
+		wantExists   bool
+		wantCategory ErrorCategory
+	}{
+		{
+			name:       "success: created",
+			request:    &Request{Path: "/packages/a", Body: strings.NewReader("{}"), ExpectedStatusCodes: []int{http.StatusCreated}},
+			status:     http.StatusCreated,
+			wantMethod: http.MethodPut,
+		},
+		{
+			name: "success: explicit method and conflicting header",
+			request: &Request{
+				Method:  http.MethodPost,
+				Path:    "/packages",
+				Headers: map[string][]string{"if-none-match": {`"v1"`}},
+			},
+			status:     http.StatusCreated,
+			wantMethod: http.MethodPost,
+		},
+		{
+			name:         "failure: already exists",
+			request:      &Request{Path: "/packages/a", ExpectedStatusCodes: []int{http.StatusCreated}},
+			status:       http.StatusPreconditionFailed,
+			wantMethod:   http.MethodPut,
+			wantExists:   true,
+			wantCategory: CategoryHTTPStatus,
+		},
+		{
+			name:         "failure: unexpected status code",
+			request:      &Request{Path: "/packages/a", ExpectedStatusCodes: []int{http.StatusCreated}},
+			status:       http.StatusForbidden,
+			wantMethod:   http.MethodPut,
+			wantCategory: CategoryHTTPStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got *http.Request
+
+			client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+				got = req
+
+				return &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}))
+			headers := tt.request.Headers
+
+			response, err := CreateIfAbsent(context.Background(), client, tt.request)
+			require.NotNil(t, got)
+			assert.Equal(t, tt.wantMethod, got.Method)
+			assert.Equal(t, []string{"*"}, got.Header.Values("If-None-Match"))
+			assert.Equal(t, headers, tt.request.Headers, "request is not modified")
+
+			if tt.wantCategory != "" {
+				require.Error(t, err)
+				assert.Nil(t, response)
+				assert.Equal(t, tt.wantCategory, ClassifyError(err))
+				assert.Equal(t, tt.wantExists, errors.Is(err, ErrAlreadyExists))
+
+				if tt.wantExists {
+					var existsErr *AlreadyExistsError
+					require.ErrorAs(t, err, &existsErr)
+					assert.Equal(t, "/packages/a", existsErr.Path)
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, response.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Debouncer collapses rapid repeated PUT requests to the same URL with the same Authorization header
+// into the last one, for autosave-style integrations that would otherwise send every intermediate state.
+type Debouncer struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*debounceGroup
+}
+
+type debounceGroup struct {
+	// requests are the collapsed requests in the order they were made.
+	requests []*http.Request
+	timer    *time.Timer
+	done     chan struct{}
+	response *http.Response
+	body     []byte
+	err      error
+}
+
+// NewDebouncer creates a new Debouncer that sends a PUT request once no other PUT request
+// to the same URL has been made for window.
+func NewDebouncer(window time.Duration) *Debouncer {
+	return &Debouncer{
+		window:  window,
+		pending: map[string]*debounceGroup{},
+	}
+}
+
+// Wrap wraps do so that PUT requests are debounced. The last request of a collapsed group whose caller is still
+// waiting is sent, with a context that is not canceled with it, and every waiting caller receives its response
+// with its own copy of the buffered body. Other methods are sent as is.
+func (d *Debouncer) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		if httpRequest.Method != http.MethodPut {
+			return do(httpRequest)
+		}
+
+		group := d.enqueue(httpRequest, do)
+
+		select {
+		case <-group.done:
+		case <-httpRequest.Context().Done():
+			return nil, errors.WithStack(httpRequest.Context().Err())
+		}
+
+		if group.err != nil {
+			return nil, errors.WithStack(group.err)
+		}
+
+		httpResponse := *group.response
+		httpResponse.Header = group.response.Header.Clone()
+		httpResponse.Body = io.NopCloser(bytes.NewReader(group.body))
+
+		return &httpResponse, nil
+	}
+}
+
+func (d *Debouncer) enqueue(httpRequest *http.Request, do DoFunc) *debounceGroup {
+	// Requests of different credentials are never collapsed, so that no caller receives the response of another.
+	key := httpRequest.URL.String() + "\x00" + httpRequest.Header.Get("Authorization")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// A group whose timer has already fired is being sent, so a new group is started instead.
+	if group, ok := d.pending[key]; ok && group.timer.Stop() {
+		group.requests = append(group.requests, httpRequest)
+		group.timer.Reset(d.window)
+
+		return group
+	}
+
+	group := &debounceGroup{
+		requests: []*http.Request{httpRequest},
+		done:     make(chan struct{}),
+	}
+	group.timer = time.AfterFunc(d.window, func() {
+		d.flush(key, group, do)
+	})
+	d.pending[key] = group
+
+	return group
+}
+
+func (d *Debouncer) flush(key string, group *debounceGroup, do DoFunc) {
+	d.mu.Lock()
+	if d.pending[key] == group {
+		delete(d.pending, key)
+	}
+	requests := group.requests
+	d.mu.Unlock()
+
+	defer close(group.done)
+
+	// The requests of callers who have gone are dropped.
+	var httpRequest *http.Request
+
+	for _, request := range slices.Backward(requests) {
+		if request.Context().Err() == nil {
+			httpRequest = request
+
+			break
+		}
+	}
+
+	if httpRequest == nil {
+		group.err = context.Canceled
+
+		return
+	}
+
+	httpResponse, err := do(httpRequest.WithContext(context.WithoutCancel(httpRequest.Context())))
+	if err != nil {
+		group.err = err
+
+		return
+	}
+
+	defer func() {
+		_ = httpResponse.Body.Close()
+	}()
+
+	body, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		group.err = err
+
+		return
+	}
+
+	group.response = httpResponse
+	group.body = body
+}
@@ -0,0 +1,195 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebouncer_Wrap(t *testing.T) {
+	t.Parallel()
+
+	newDo := func(sent *[]string, mu *sync.Mutex) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			body := ""
+			if req.Body != nil {
+				b, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				body = string(b)
+			}
+
+			mu.Lock()
+			*sent = append(*sent, req.Method+" "+req.URL.Path+" "+body)
+			mu.Unlock()
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Etag": []string{`"` + body + `"`}},
+				Body:       io.NopCloser(bytes.NewReader([]byte("saved " + body))),
+			}, nil
+		}
+	}
+
+	newRequest := func(t *testing.T, ctx context.Context, method, path, body string) *http.Request {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(ctx, method, "http://example.com"+path, strings.NewReader(body))
+		require.NoError(t, err)
+
+		return req
+	}
+
+	t.Run("success: collapses to the last request", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var sent []string
+		do := NewDebouncer(50 * time.Millisecond).Wrap(newDo(&sent, &mu))
+
+		var wg sync.WaitGroup
+		bodies := make([]string, 3)
+		for i, body := range []string{"v1", "v2", "v3"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				resp, err := do(newRequest(t, context.Background(), http.MethodPut, "/doc", body))
+				if !assert.NoError(t, err) {
+					return
+				}
+				b, _ := io.ReadAll(resp.Body)
+				bodies[i] = string(b)
+			}()
+			time.Sleep(10 * time.Millisecond)
+		}
+		wg.Wait()
+
+		assert.Equal(t, []string{"PUT /doc v3"}, sent)
+		assert.Equal(t, []string{"saved v3", "saved v3", "saved v3"}, bodies)
+	})
+
+	t.Run("success: different URLs are not collapsed", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var sent []string
+		do := NewDebouncer(10 * time.Millisecond).Wrap(newDo(&sent, &mu))
+
+		var wg sync.WaitGroup
+		for _, path := range []string{"/a", "/b"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, err := do(newRequest(t, context.Background(), http.MethodPut, path, "v"))
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.ElementsMatch(t, []string{"PUT /a v", "PUT /b v"}, sent)
+	})
+
+	t.Run("success: different credentials are not collapsed", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var sent []string
+		do := NewDebouncer(10 * time.Millisecond).Wrap(newDo(&sent, &mu))
+
+		var wg sync.WaitGroup
+		bodies := make([]string, 2)
+		for i, user := range []string{"alice", "bob"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				req := newRequest(t, context.Background(), http.MethodPut, "/doc", user)
+				req.Header.Set("Authorization", "Bearer "+user)
+
+				resp, err := do(req)
+				if !assert.NoError(t, err) {
+					return
+				}
+				b, _ := io.ReadAll(resp.Body)
+				bodies[i] = string(b)
+			}()
+		}
+		wg.Wait()
+
+		assert.ElementsMatch(t, []string{"PUT /doc alice", "PUT /doc bob"}, sent)
+		assert.Equal(t, []string{"saved alice", "saved bob"}, bodies)
+	})
+
+	t.Run("success: requests of canceled callers are dropped", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var sent []string
+		do := NewDebouncer(50 * time.Millisecond).Wrap(newDo(&sent, &mu))
+
+		var wg sync.WaitGroup
+		var body string
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := do(newRequest(t, context.Background(), http.MethodPut, "/doc", "v1"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			b, _ := io.ReadAll(resp.Body)
+			body = string(b)
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := do(newRequest(t, ctx, http.MethodPut, "/doc", "v2"))
+		assert.ErrorIs(t, err, context.Canceled)
+		wg.Wait()
+
+		assert.Equal(t, []string{"PUT /doc v1"}, sent)
+		assert.Equal(t, "saved v1", body)
+	})
+
+	t.Run("success: other methods are sent immediately", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var sent []string
+		do := NewDebouncer(time.Hour).Wrap(newDo(&sent, &mu))
+
+		_, err := do(newRequest(t, context.Background(), http.MethodPost, "/doc", "v1"))
+		require.NoError(t, err)
+		_, err = do(newRequest(t, context.Background(), http.MethodPost, "/doc", "v2"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"POST /doc v1", "POST /doc v2"}, sent)
+	})
+
+	t.Run("failure: context canceled while waiting", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var sent []string
+		do := NewDebouncer(time.Hour).Wrap(newDo(&sent, &mu))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := do(newRequest(t, ctx, http.MethodPut, "/doc", "v1"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,90 @@
+package webapiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// maxDecodeSnippetSize is the number of bytes of the body quoted by a DecodeError.
+const maxDecodeSnippetSize = 256
+
+// DecodeError is returned when the body of a response cannot be decoded.
+type DecodeError struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Snippet is the beginning of the body, truncated to 256 bytes.
+	Snippet string
+	// Err is the error of the decoder.
+	Err error
+}
+
+// Error returns the message of the decoder followed by the status code and the beginning of the body.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode response body: %v (status %d, body %q)", e.Err, e.StatusCode, e.Snippet)
+}
+
+// Unwrap returns the error of the decoder.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSON reads the body of the response, closes it, and decodes it as JSON into v.
+// A body that cannot be decoded is reported with a *DecodeError classified as CategoryDecode.
+func (r *Response) DecodeJSON(v any) error {
+	return r.decode(v, json.Unmarshal)
+}
+
+// DecodeXML reads the body of the response, closes it, and decodes it as XML into v.
+// Text in a charset other than UTF-8 is decoded according to the charset of the Content-Type header,
+// or else the encoding declaration of the document; see RegisterCharset.
+// A body that cannot be decoded is reported with a *DecodeError classified as CategoryDecode.
+func (r *Response) DecodeXML(v any) error {
+	charset := contentTypeCharset(r.Headers)
+
+	return r.decode(v, func(data []byte, v any) error {
+		decoder, err := newXMLDecoder(bytes.NewReader(data), charset)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return decoder.Decode(v) //nolint:wrapcheck
+	})
+}
+
+func (r *Response) decode(v any, unmarshal func(data []byte, v any) error) error {
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+
+	if err != nil {
+		return errors.WithStack(classifyTransportError(err))
+	}
+
+	err = unmarshal(body, v)
+	if err != nil {
+		return errors.WithStack(newDecodeError(r.StatusCode, body, err))
+	}
+
+	return nil
+}
+
+func newDecodeError(statusCode int, body []byte, err error) *Error {
+	return newError(CategoryDecode, &DecodeError{StatusCode: statusCode, Snippet: string(truncateSnippet(body)), Err: err})
+}
+
+// truncateSnippet returns at most maxDecodeSnippetSize bytes of body, without splitting a UTF-8 sequence.
+func truncateSnippet(body []byte) []byte {
+	snippet := body
+	if len(snippet) > maxDecodeSnippetSize {
+		snippet = snippet[:maxDecodeSnippetSize]
+		for len(snippet) > 0 && !utf8.Valid(snippet) {
+			snippet = snippet[:len(snippet)-1]
+		}
+	}
+
+	return snippet
+}
@@ -0,0 +1,115 @@
+package webapiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DecodeStrategy is a way of decoding a response body tried by Response.DecodeFallback.
+type DecodeStrategy struct {
+	// Name identifies the strategy in the result of DecodeFallback.
+	Name string
+	// Decode decodes the body, and returns an error when the body does not fit the strategy.
+	Decode func(data []byte) error
+}
+
+// StrictJSON returns a DecodeStrategy named "strict" decoding a JSON body into the value pointed to by v,
+// failing on unknown fields and trailing data. v is only modified when the body is decoded.
+func StrictJSON(v any) DecodeStrategy {
+	return DecodeStrategy{
+		Name: "strict",
+		Decode: func(data []byte) error {
+			return decodeInto(v, func(fresh any) error {
+				decoder := json.NewDecoder(bytes.NewReader(data))
+				decoder.DisallowUnknownFields()
+
+				err := decoder.Decode(fresh)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+
+				if decoder.More() {
+					return errors.New("trailing data after JSON value")
+				}
+
+				return nil
+			})
+		},
+	}
+}
+
+// LenientJSON returns a DecodeStrategy named "lenient" decoding a JSON body into the value pointed to by v,
+// such as a struct ignoring unknown fields or a map[string]any. v is only modified when the body is decoded.
+func LenientJSON(v any) DecodeStrategy {
+	return DecodeStrategy{
+		Name: "lenient",
+		Decode: func(data []byte) error {
+			return decodeInto(v, func(fresh any) error {
+				return json.Unmarshal(data, fresh) //nolint:wrapcheck
+			})
+		},
+	}
+}
+
+// RawBody returns a DecodeStrategy named "raw" storing the body as is into v. It never fails.
+func RawBody(v *[]byte) DecodeStrategy {
+	return DecodeStrategy{
+		Name: "raw",
+		Decode: func(data []byte) error {
+			*v = data
+
+			return nil
+		},
+	}
+}
+
+// decodeInto decodes into a new value of the type pointed to by v, and only stores it into v on success,
+// so that failed strategies do not leave v partially filled.
+func decodeInto(v any, decode func(fresh any) error) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return errors.Errorf("decode target %T is not a non-nil pointer", v)
+	}
+
+	fresh := reflect.New(target.Type().Elem())
+
+	err := decode(fresh.Interface())
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	target.Elem().Set(fresh.Elem())
+
+	return nil
+}
+
+// DecodeFallback reads the body of the response, closes it, and tries strategies in order until one decodes
+// the body, so that partially incompatible upstream changes degrade gracefully instead of failing.
+// It returns the name of the strategy that decoded the body. When none did, the errors of every strategy
+// are reported with a *DecodeError classified as CategoryDecode.
+func (r *Response) DecodeFallback(strategies ...DecodeStrategy) (string, error) {
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+
+	if err != nil {
+		return "", errors.WithStack(classifyTransportError(err))
+	}
+
+	messages := make([]string, 0, len(strategies))
+
+	for _, strategy := range strategies {
+		err := strategy.Decode(body)
+		if err == nil {
+			return strategy.Name, nil
+		}
+
+		messages = append(messages, strategy.Name+": "+err.Error())
+	}
+
+	return "", errors.WithStack(newDecodeError(r.StatusCode, body, errors.New("no decode strategy succeeded: "+strings.Join(messages, "; "))))
+}
@@ -0,0 +1,106 @@
+package webapiclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_DecodeFallback(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		strategies   func(strict, lenient *user, loose *map[string]any, raw *[]byte) []DecodeStrategy
+		wantStrategy string
+		wantStrict   user
+		wantLenient  user
+		wantLoose    map[string]any
+		wantRaw      string
+		wantErr      bool
+	}{
+		{
+			name: "success: strict",
+			body: `{"id":1,"name":"Jane"}`,
+			strategies: func(strict, lenient *user, loose *map[string]any, raw *[]byte) []DecodeStrategy {
+				return []DecodeStrategy{StrictJSON(strict), LenientJSON(lenient), RawBody(raw)}
+			},
+			wantStrategy: "strict",
+			wantStrict:   user{ID: 1, Name: "Jane"},
+		},
+		{
+			name: "success: lenient on an unknown field",
+			body: `{"id":1,"name":"Jane","email":"jane@example.com"}`,
+			strategies: func(strict, lenient *user, loose *map[string]any, raw *[]byte) []DecodeStrategy {
+				return []DecodeStrategy{StrictJSON(strict), LenientJSON(lenient), RawBody(raw)}
+			},
+			wantStrategy: "lenient",
+			wantLenient:  user{ID: 1, Name: "Jane"},
+		},
+		{
+			name: "success: map on a changed field type",
+			body: `{"id":"u-1"}`,
+			strategies: func(strict, lenient *user, loose *map[string]any, raw *[]byte) []DecodeStrategy {
+				return []DecodeStrategy{StrictJSON(strict), LenientJSON(lenient), LenientJSON(loose), RawBody(raw)}
+			},
+			wantStrategy: "lenient",
+			wantLoose:    map[string]any{"id": "u-1"},
+		},
+		{
+			name: "success: raw",
+			body: `<user id="1"/>`,
+			strategies: func(strict, lenient *user, loose *map[string]any, raw *[]byte) []DecodeStrategy {
+				return []DecodeStrategy{StrictJSON(strict), LenientJSON(loose), RawBody(raw)}
+			},
+			wantStrategy: "raw",
+			wantRaw:      `<user id="1"/>`,
+		},
+		{
+			name: "failure: no strategy succeeded",
+			body: `{"id":1} {"id":2}`,
+			strategies: func(strict, lenient *user, loose *map[string]any, raw *[]byte) []DecodeStrategy {
+				return []DecodeStrategy{StrictJSON(strict)}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				strict, lenient user
+				loose           map[string]any
+				raw             []byte
+			)
+
+			response := &Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tt.body))}
+
+			got, err := response.DecodeFallback(tt.strategies(&strict, &lenient, &loose, &raw)...)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, CategoryDecode, ClassifyError(err))
+				assert.Contains(t, err.Error(), "strict: trailing data")
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStrategy, got)
+			assert.Equal(t, tt.wantStrict, strict)
+			assert.Equal(t, tt.wantLenient, lenient)
+			assert.Equal(t, tt.wantLoose, loose)
+			assert.Equal(t, tt.wantRaw, string(raw))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package webapiclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_DecodeJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		body        string
+		want        jsonUser
+		wantSnippet string
+	}{
+		{name: "success: valid body", body: `{"id":1,"name":"Jane"}`, want: jsonUser{ID: 1, Name: "Jane"}},
+		{name: "failure: HTML body", body: "<html>maintenance</html>", wantSnippet: "<html>maintenance</html>"},
+		{name: "failure: long body", body: "<" + strings.Repeat("é", 200), wantSnippet: "<" + strings.Repeat("é", 127)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			body := &trackingBody{Reader: strings.NewReader(tt.body), closed: make(chan struct{})}
+			response := &Response{StatusCode: http.StatusOK, Body: body}
+
+			var got jsonUser
+
+			err := response.DecodeJSON(&got)
+			select {
+			case <-body.closed:
+			default:
+				t.Error("body not closed")
+			}
+
+			if tt.wantSnippet == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+
+				return
+			}
+
+			var decodeError *DecodeError
+			require.ErrorAs(t, err, &decodeError)
+			assert.Equal(t, http.StatusOK, decodeError.StatusCode)
+			assert.Equal(t, tt.wantSnippet, decodeError.Snippet)
+			assert.Equal(t, CategoryDecode, ClassifyError(err))
+			assert.Contains(t, err.Error(), "status 200")
+		})
+	}
+}
+
+func TestResponse_DecodeXML(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		ID   int    `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    item
+		wantErr bool
+	}{
+		{name: "success: valid body", body: `<item id="1"><name>Jane</name></item>`, want: item{ID: 1, Name: "Jane"}},
+		{name: "failure: JSON body", body: `{"id":1}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response := &Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tt.body))}
+
+			var got item
+
+			err := response.DecodeXML(&got)
+			if tt.wantErr {
+				var decodeError *DecodeError
+				require.ErrorAs(t, err, &decodeError)
+				assert.Equal(t, tt.body, decodeError.Snippet)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package webapiclient
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrSyncTokenExpired is returned by SyncDelta when the server no longer accepts the stored sync token.
+// The token is then removed from the store, so that the next sync is a full one.
+var ErrSyncTokenExpired = errors.New("sync token expired")
+
+// SyncTokenStore persists the sync tokens of delta queries between syncs.
+type SyncTokenStore interface {
+	// LoadSyncToken returns the token stored for key, or an empty string when there is none.
+	LoadSyncToken(ctx context.Context, key string) (string, error)
+	// SaveSyncToken stores token for key; an empty token removes it.
+	SaveSyncToken(ctx context.Context, key string, token string) error
+}
+
+// Compile-time check to ensure MemorySyncTokenStore implements SyncTokenStore interface.
+var _ SyncTokenStore = (*MemorySyncTokenStore)(nil)
+
+// MemorySyncTokenStore is a SyncTokenStore keeping tokens in memory.
+type MemorySyncTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewMemorySyncTokenStore creates an empty MemorySyncTokenStore.
+func NewMemorySyncTokenStore() *MemorySyncTokenStore {
+	return &MemorySyncTokenStore{tokens: map[string]string{}}
+}
+
+// LoadSyncToken returns the token stored for key.
+func (s *MemorySyncTokenStore) LoadSyncToken(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tokens[key], nil
+}
+
+// SaveSyncToken stores token for key.
+func (s *MemorySyncTokenStore) SaveSyncToken(_ context.Context, key string, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token == "" {
+		delete(s.tokens, key)
+	} else {
+		s.tokens[key] = token
+	}
+
+	return nil
+}
+
+// ChangeKind is the kind of a Change.
+type ChangeKind string
+
+const (
+	// ChangeUpserted is the kind of items that were added or updated; most delta APIs do not tell them apart.
+	ChangeUpserted ChangeKind = "upserted"
+	// ChangeAdded is the kind of items that were added.
+	ChangeAdded ChangeKind = "added"
+	// ChangeUpdated is the kind of items that were updated.
+	ChangeUpdated ChangeKind = "updated"
+	// ChangeDeleted is the kind of items that were deleted.
+	ChangeDeleted ChangeKind = "deleted"
+)
+
+// Change is a change of an item of a collection returned by a delta query.
+type Change[T any] struct {
+	// Kind is the kind of change.
+	Kind ChangeKind
+	// ID is the ID of the item.
+	ID string
+	// Item is the item; it may be partial or zero for deleted items.
+	Item T
+}
+
+// DeltaPage is one page of changes returned by a delta query.
+type DeltaPage[T any] struct {
+	// Changes are the changes of the page.
+	Changes []Change[T]
+	// PageToken is the link or token of the next page, or an empty string on the last page.
+	PageToken string
+	// SyncToken is the link or token for the next sync, returned on the last page.
+	SyncToken string
+}
+
+// DeltaProtocol adapts SyncDelta to a delta query API.
+type DeltaProtocol[T any] struct {
+	// Request returns the request for the page pageToken when it is not empty, otherwise for the changes since
+	// syncToken, or for a full sync when syncToken is empty too.
+	Request func(syncToken string, pageToken string) *Request
+	// Parse decodes and closes the body of a response into a page.
+	Parse func(response *Response) (*DeltaPage[T], error)
+	// Expired reports whether err, returned for a request with a sync token, means the token expired.
+	Expired func(err error) bool
+}
+
+// SyncDelta returns the changes of a collection since the last sync as a stream, following the pages of
+// the delta query described by protocol. The sync token stored for key in store is used to request only the changes,
+// and the sync token of the last page replaces it once every change has been consumed; a stream stopped early
+// leaves the stored token as it was, so that the changes are returned again by the next sync.
+// The stream ends after the first error, which is ErrSyncTokenExpired when the server rejects the stored token.
+func SyncDelta[T any](
+	ctx context.Context, client Client, store SyncTokenStore, key string, protocol DeltaProtocol[T],
+) iter.Seq2[Change[T], error] {
+	return func(yield func(Change[T], error) bool) {
+		syncToken, err := store.LoadSyncToken(ctx, key)
+		if err != nil {
+			yield(Change[T]{}, errors.WithStack(err))
+
+			return
+		}
+
+		pageToken := ""
+
+		for {
+			page, err := fetchDeltaPage(ctx, client, protocol, syncToken, pageToken)
+			if err != nil {
+				if syncToken != "" && protocol.Expired != nil && protocol.Expired(err) {
+					err = store.SaveSyncToken(ctx, key, "")
+					if err == nil {
+						err = errors.WithStack(ErrSyncTokenExpired)
+					}
+				}
+
+				yield(Change[T]{}, errors.WithStack(err))
+
+				return
+			}
+
+			for _, change := range page.Changes {
+				if !yield(change, nil) {
+					return
+				}
+			}
+
+			if page.PageToken == "" {
+				err := store.SaveSyncToken(ctx, key, page.SyncToken)
+				if err != nil {
+					yield(Change[T]{}, errors.WithStack(err))
+				}
+
+				return
+			}
+
+			pageToken = page.PageToken
+		}
+	}
+}
+
+func fetchDeltaPage[T any](
+	ctx context.Context, client Client, protocol DeltaProtocol[T], syncToken string, pageToken string,
+) (*DeltaPage[T], error) {
+	response, err := client.Do(ctx, protocol.Request(syncToken, pageToken), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	page, err := protocol.Parse(response)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return page, nil
+}
+
+// GraphDelta returns the DeltaProtocol of Microsoft Graph delta queries starting at path, such as "/me/messages/delta".
+// The sync token is the @odata.deltaLink, items marked with @removed are deleted, and the other ones are upserted.
+// 410 Gone means the token expired.
+func GraphDelta[T any](path string) DeltaProtocol[T] {
+	return DeltaProtocol[T]{
+		Request: func(syncToken string, pageToken string) *Request {
+			link := path
+			if pageToken != "" {
+				link = pageToken
+			} else if syncToken != "" {
+				link = syncToken
+			}
+
+			return &Request{Method: http.MethodGet, Path: link, ExpectedStatusCodes: []int{http.StatusOK}}
+		},
+		Parse: func(response *Response) (*DeltaPage[T], error) {
+			var body struct {
+				Value     []json.RawMessage `json:"value"`
+				NextLink  string            `json:"@odata.nextLink"`  //nolint:tagliatelle
+				DeltaLink string            `json:"@odata.deltaLink"` //nolint:tagliatelle
+			}
+
+			err := response.DecodeJSON(&body)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			changes, err := parseDeltaItems[T](body.Value, func(marker deltaItemMarker) bool {
+				return marker.Removed != nil
+			})
+			if err != nil {
+				return nil, errors.WithStack(newDecodeError(response.StatusCode, nil, err))
+			}
+
+			return &DeltaPage[T]{Changes: changes, PageToken: body.NextLink, SyncToken: body.DeltaLink}, nil
+		},
+		Expired: isGone,
+	}
+}
+
+// GoogleSync returns the DeltaProtocol of Google APIs with incremental sync tokens, such as the events of
+// Google Calendar, listed at path. The pageToken or else syncToken query parameter is set on the request,
+// items with the status "cancelled" are deleted, and the other ones are upserted. 410 Gone means the token expired.
+func GoogleSync[T any](path string) DeltaProtocol[T] {
+	return DeltaProtocol[T]{
+		Request: func(syncToken string, pageToken string) *Request {
+			query := map[string][]string{}
+			if pageToken != "" {
+				query["pageToken"] = []string{pageToken}
+			} else if syncToken != "" {
+				query["syncToken"] = []string{syncToken}
+			}
+
+			return &Request{Method: http.MethodGet, Path: path, Query: query, ExpectedStatusCodes: []int{http.StatusOK}}
+		},
+		Parse: func(response *Response) (*DeltaPage[T], error) {
+			var body struct {
+				Items         []json.RawMessage `json:"items"`
+				NextPageToken string            `json:"nextPageToken"`
+				NextSyncToken string            `json:"nextSyncToken"`
+			}
+
+			err := response.DecodeJSON(&body)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			changes, err := parseDeltaItems[T](body.Items, func(marker deltaItemMarker) bool {
+				return marker.Status == "cancelled"
+			})
+			if err != nil {
+				return nil, errors.WithStack(newDecodeError(response.StatusCode, nil, err))
+			}
+
+			return &DeltaPage[T]{Changes: changes, PageToken: body.NextPageToken, SyncToken: body.NextSyncToken}, nil
+		},
+		Expired: isGone,
+	}
+}
+
+// isGone reports whether err was returned for a 410 Gone response.
+func isGone(err error) bool {
+	var apiError *APIError
+
+	return errors.As(err, &apiError) && apiError.StatusCode == http.StatusGone
+}
+
+// deltaItemMarker holds the members of delta items telling their ID and whether they were deleted.
+type deltaItemMarker struct {
+	ID      string          `json:"id"`
+	Removed json.RawMessage `json:"@removed"` //nolint:tagliatelle
+	Status  string          `json:"status"`
+}
+
+// parseDeltaItems returns the items as upserted changes, or deleted ones when deleted reports so.
+func parseDeltaItems[T any](items []json.RawMessage, deleted func(marker deltaItemMarker) bool) ([]Change[T], error) {
+	changes := make([]Change[T], 0, len(items))
+
+	for _, item := range items {
+		var marker deltaItemMarker
+
+		err := json.Unmarshal(item, &marker)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		change := Change[T]{Kind: ChangeUpserted, ID: marker.ID}
+		if deleted(marker) {
+			change.Kind = ChangeDeleted
+		}
+
+		err = json.Unmarshal(item, &change.Item)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
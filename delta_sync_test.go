@@ -0,0 +1,169 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deltaMessage struct {
+	ID      string `json:"id"`
+	Subject string `json:"subject"`
+}
+
+func newDeltaClient(t *testing.T, responses map[string]string, requested *[]string) Client {
+	t.Helper()
+
+	return NewClient("https://graph.example.com/v1.0/", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		*requested = append(*requested, req.URL.String())
+
+		body, ok := responses[req.URL.String()]
+		if !ok {
+			return &http.Response{StatusCode: http.StatusGone, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}))
+}
+
+func collectChanges[T any](t *testing.T, changes iter.Seq2[Change[T], error]) ([]Change[T], error) {
+	t.Helper()
+
+	var got []Change[T]
+
+	for change, err := range changes {
+		if err != nil {
+			return got, err
+		}
+
+		got = append(got, change)
+	}
+
+	return got, nil
+}
+
+func TestSyncDelta_GraphDelta(t *testing.T) {
+	t.Parallel()
+
+	responses := map[string]string{
+		"https://graph.example.com/me/messages/delta": `{
+			"value": [{"id": "1", "subject": "a"}],
+			"@odata.nextLink": "https://graph.example.com/me/messages/delta?$skiptoken=2"
+		}`,
+		"https://graph.example.com/me/messages/delta?$skiptoken=2": `{
+			"value": [{"id": "2", "subject": "b"}],
+			"@odata.deltaLink": "https://graph.example.com/me/messages/delta?$deltatoken=3"
+		}`,
+		"https://graph.example.com/me/messages/delta?$deltatoken=3": `{
+			"value": [{"id": "1", "@removed": {"reason": "deleted"}}, {"id": "2", "subject": "b2"}],
+			"@odata.deltaLink": "https://graph.example.com/me/messages/delta?$deltatoken=4"
+		}`,
+	}
+
+	var requested []string
+
+	client := newDeltaClient(t, responses, &requested)
+	store := NewMemorySyncTokenStore()
+	protocol := GraphDelta[deltaMessage]("/me/messages/delta")
+
+	got, err := collectChanges(t, SyncDelta(context.Background(), client, store, "messages", protocol))
+	require.NoError(t, err)
+	assert.Equal(t, []Change[deltaMessage]{
+		{Kind: ChangeUpserted, ID: "1", Item: deltaMessage{ID: "1", Subject: "a"}},
+		{Kind: ChangeUpserted, ID: "2", Item: deltaMessage{ID: "2", Subject: "b"}},
+	}, got)
+
+	token, err := store.LoadSyncToken(context.Background(), "messages")
+	require.NoError(t, err)
+	assert.Equal(t, "https://graph.example.com/me/messages/delta?$deltatoken=3", token)
+
+	got, err = collectChanges(t, SyncDelta(context.Background(), client, store, "messages", protocol))
+	require.NoError(t, err)
+	assert.Equal(t, []Change[deltaMessage]{
+		{Kind: ChangeDeleted, ID: "1", Item: deltaMessage{ID: "1"}},
+		{Kind: ChangeUpserted, ID: "2", Item: deltaMessage{ID: "2", Subject: "b2"}},
+	}, got)
+	assert.Len(t, requested, 3)
+
+	token, err = store.LoadSyncToken(context.Background(), "messages")
+	require.NoError(t, err)
+	assert.Equal(t, "https://graph.example.com/me/messages/delta?$deltatoken=4", token)
+}
+
+func TestSyncDelta_GoogleSync(t *testing.T) {
+	t.Parallel()
+
+	responses := map[string]string{
+		"https://graph.example.com/v1.0/events": `{
+			"items": [{"id": "1", "subject": "a"}, {"id": "2", "subject": "b"}],
+			"nextPageToken": "p2"
+		}`,
+		"https://graph.example.com/v1.0/events?pageToken=p2": `{
+			"items": [{"id": "3", "status": "cancelled"}],
+			"nextSyncToken": "s1"
+		}`,
+	}
+
+	t.Run("success: stopped early keeps the token", func(t *testing.T) {
+		t.Parallel()
+
+		var requested []string
+
+		store := NewMemorySyncTokenStore()
+
+		for change, err := range SyncDelta(context.Background(), newDeltaClient(t, responses, &requested), store, "events", GoogleSync[deltaMessage]("events")) {
+			require.NoError(t, err)
+			assert.Equal(t, "1", change.ID)
+
+			break
+		}
+
+		token, err := store.LoadSyncToken(context.Background(), "events")
+		require.NoError(t, err)
+		assert.Empty(t, token)
+		assert.Len(t, requested, 1)
+	})
+
+	t.Run("success: pages and deletions", func(t *testing.T) {
+		t.Parallel()
+
+		var requested []string
+
+		store := NewMemorySyncTokenStore()
+
+		got, err := collectChanges(t, SyncDelta(context.Background(), newDeltaClient(t, responses, &requested), store, "events", GoogleSync[deltaMessage]("events")))
+		require.NoError(t, err)
+		assert.Equal(t, []ChangeKind{ChangeUpserted, ChangeUpserted, ChangeDeleted}, []ChangeKind{got[0].Kind, got[1].Kind, got[2].Kind})
+
+		token, err := store.LoadSyncToken(context.Background(), "events")
+		require.NoError(t, err)
+		assert.Equal(t, "s1", token)
+	})
+
+	t.Run("failure: expired sync token", func(t *testing.T) {
+		t.Parallel()
+
+		var requested []string
+
+		store := NewMemorySyncTokenStore()
+		require.NoError(t, store.SaveSyncToken(context.Background(), "events", "s0"))
+
+		_, err := collectChanges(t, SyncDelta(context.Background(), newDeltaClient(t, responses, &requested), store, "events", GoogleSync[deltaMessage]("events")))
+		require.ErrorIs(t, err, ErrSyncTokenExpired)
+		assert.Equal(t, []string{"https://graph.example.com/v1.0/events?syncToken=s0"}, requested)
+
+		token, err := store.LoadSyncToken(context.Background(), "events")
+		require.NoError(t, err)
+		assert.Empty(t, token, "the next sync is a full one")
+	})
+}
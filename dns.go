@@ -0,0 +1,325 @@
+package webapiclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultDialTimeout is the connection timeout of DNSDialer, shared among the addresses of a host.
+	defaultDialTimeout = 30 * time.Second
+	// fallbackDelay is the time after which the addresses of the other IP family are raced, as in net.Dialer.
+	fallbackDelay = 300 * time.Millisecond
+	// minAddressDialTimeout is the minimum share of the timeout given to an address, as in net.Dialer.
+	minAddressDialTimeout = 2 * time.Second
+)
+
+// Compile-time check to ensure the resolvers implement Resolver interface.
+var (
+	_ Resolver = (*net.Resolver)(nil)
+	_ Resolver = (*ServerResolver)(nil)
+)
+
+// Resolver resolves host names to IP addresses. *net.Resolver implements this interface.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// ServerResolver resolves host names by querying a specific DNS server directly,
+// bypassing the negative cache of the operating system.
+type ServerResolver struct {
+	address  string
+	resolver *net.Resolver
+}
+
+// NewServerResolver creates a new ServerResolver querying the DNS server at address (host:port).
+func NewServerResolver(address string) *ServerResolver {
+	return &ServerResolver{
+		address: address,
+		resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+
+				return dialer.DialContext(ctx, network, address) //nolint:wrapcheck
+			},
+		},
+	}
+}
+
+// LookupHost resolves host using the configured DNS server.
+func (r *ServerResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addresses, err := r.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return addresses, nil
+}
+
+// String returns the address of the DNS server.
+func (r *ServerResolver) String() string {
+	return r.address
+}
+
+// DNSAttempt is the result of a single resolution attempt.
+type DNSAttempt struct {
+	Resolver string
+	Err      error
+}
+
+// DNSResolutionError is returned when every resolver failed to resolve a host.
+type DNSResolutionError struct {
+	Host     string
+	Attempts []DNSAttempt
+}
+
+// Error returns a message listing the failure of each resolver.
+func (e *DNSResolutionError) Error() string {
+	messages := make([]string, 0, len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		messages = append(messages, fmt.Sprintf("%s: %v", attempt.Resolver, attempt.Err))
+	}
+
+	return fmt.Sprintf("failed to resolve %s: %s", e.Host, strings.Join(messages, "; "))
+}
+
+// Unwrap returns the errors of every attempt, so that *net.DNSError can be inspected with errors.As.
+func (e *DNSResolutionError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		errs = append(errs, attempt.Err)
+	}
+
+	return errs
+}
+
+// DNSDialer dials connections after resolving host names with a chain of resolvers,
+// falling back to the next resolver when a lookup fails.
+// Its DialContext method can be used as http.Transport.DialContext.
+type DNSDialer struct {
+	resolvers   []Resolver
+	dialContext func(ctx context.Context, network string, address string) (net.Conn, error)
+}
+
+// NewDNSDialer creates a new DNSDialer trying resolvers in order.
+// Passing the same resolver more than once retries it. The system resolver is used when none is given.
+func NewDNSDialer(resolvers ...Resolver) *DNSDialer {
+	if len(resolvers) == 0 {
+		resolvers = []Resolver{net.DefaultResolver}
+	}
+
+	return &DNSDialer{
+		resolvers:   resolvers,
+		dialContext: (&net.Dialer{}).DialContext,
+	}
+}
+
+// DialContext resolves the host of address and connects to the first reachable IP address like net.Dialer:
+// the addresses of the IP family of the first one are tried in turn, each with a share of the timeout,
+// and those of the other family are raced against them after 300ms (Happy Eyeballs, RFC 6555).
+func (d *DNSDialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if net.ParseIP(host) != nil {
+		return d.dial(ctx, network, []string{host}, port)
+	}
+
+	addresses, err := d.lookupHost(ctx, host)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return d.dial(ctx, network, addresses, port)
+}
+
+func (d *DNSDialer) lookupHost(ctx context.Context, host string) ([]string, error) {
+	resolutionError := &DNSResolutionError{Host: host}
+
+	for _, resolver := range d.resolvers {
+		addresses, err := resolver.LookupHost(ctx, host)
+		if err == nil && len(addresses) > 0 {
+			return addresses, nil
+		}
+
+		if err == nil {
+			err = &net.DNSError{Err: "no addresses found", Name: host, IsNotFound: true}
+		}
+
+		resolutionError.Attempts = append(resolutionError.Attempts, DNSAttempt{
+			Resolver: describeResolver(resolver),
+			Err:      err,
+		})
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, resolutionError
+}
+
+// dialResult is the result of dialing a list of addresses in turn.
+type dialResult struct {
+	conn    net.Conn
+	err     error
+	primary bool
+}
+
+func (d *DNSDialer) dial(ctx context.Context, network string, addresses []string, port string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+	defer cancel()
+
+	primaries, fallbacks := partitionAddresses(addresses)
+	if len(fallbacks) == 0 {
+		return d.dialSerial(ctx, network, primaries, port)
+	}
+
+	returned := make(chan struct{})
+	defer close(returned)
+
+	results := make(chan dialResult)
+	race := func(ctx context.Context, addresses []string, primary bool) {
+		conn, err := d.dialSerial(ctx, network, addresses, port)
+
+		select {
+		case results <- dialResult{conn: conn, err: err, primary: primary}:
+		case <-returned:
+			if conn != nil {
+				_ = conn.Close()
+			}
+		}
+	}
+
+	primaryCtx, primaryCancel := context.WithCancel(ctx)
+	defer primaryCancel()
+
+	go race(primaryCtx, primaries, true)
+
+	fallbackTimer := time.NewTimer(fallbackDelay)
+	defer fallbackTimer.Stop()
+
+	fallbackCtx, fallbackCancel := context.WithCancel(ctx)
+	defer fallbackCancel()
+
+	var (
+		primaryErr      error
+		fallbackStarted bool
+		fallbackDone    bool
+	)
+
+	for {
+		select {
+		case <-fallbackTimer.C:
+			fallbackStarted = true
+
+			go race(fallbackCtx, fallbacks, false)
+		case result := <-results:
+			if result.err == nil {
+				return result.conn, nil
+			}
+
+			if !result.primary {
+				fallbackDone = true
+			} else {
+				primaryErr = result.err
+
+				// The fallback is started right away when the primary addresses failed before the delay.
+				if !fallbackStarted {
+					fallbackTimer.Stop()
+
+					fallbackStarted = true
+
+					go race(fallbackCtx, fallbacks, false)
+				}
+			}
+
+			if primaryErr != nil && fallbackDone {
+				return nil, errors.WithStack(primaryErr)
+			}
+		}
+	}
+}
+
+// dialSerial tries addresses in turn, giving each a share of the time left before the deadline of ctx.
+func (d *DNSDialer) dialSerial(ctx context.Context, network string, addresses []string, port string) (net.Conn, error) {
+	var firstErr error
+
+	for i, address := range addresses {
+		dialCtx, cancel := ctx, context.CancelFunc(func() {})
+		if deadline, ok := ctx.Deadline(); ok {
+			dialCtx, cancel = context.WithDeadline(ctx, partialDeadline(time.Now(), deadline, len(addresses)-i))
+		}
+
+		conn, err := d.dialContext(dialCtx, network, net.JoinHostPort(address, port))
+
+		cancel()
+
+		if err == nil {
+			return conn, nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, errors.WithStack(firstErr)
+}
+
+// partialDeadline returns the deadline of one of the remaining addresses, as computed by net.Dialer.
+func partialDeadline(now time.Time, deadline time.Time, remaining int) time.Time {
+	timeRemaining := deadline.Sub(now)
+
+	timeout := timeRemaining / time.Duration(remaining)
+	if timeout < minAddressDialTimeout {
+		timeout = min(timeRemaining, minAddressDialTimeout)
+	}
+
+	return now.Add(timeout)
+}
+
+// partitionAddresses splits addresses into those of the IP family of the first one and the others.
+func partitionAddresses(addresses []string) ([]string, []string) {
+	var primaries, fallbacks []string
+
+	isIPv4 := func(address string) bool {
+		ip := net.ParseIP(address)
+
+		return ip != nil && ip.To4() != nil
+	}
+
+	for _, address := range addresses {
+		if len(primaries) == 0 || isIPv4(address) == isIPv4(primaries[0]) {
+			primaries = append(primaries, address)
+		} else {
+			fallbacks = append(fallbacks, address)
+		}
+	}
+
+	return primaries, fallbacks
+}
+
+func describeResolver(resolver Resolver) string {
+	if resolver == net.DefaultResolver {
+		return "system"
+	}
+
+	if stringer, ok := resolver.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprintf("%T", resolver)
+}
@@ -0,0 +1,198 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	name      string
+	addresses []string
+	err       error
+	calls     int
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, _ string) ([]string, error) {
+	r.calls++
+
+	return r.addresses, r.err
+}
+
+func (r *fakeResolver) String() string {
+	return r.name
+}
+
+func TestDNSDialer_DialContext(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	notFound := func() error {
+		return &net.DNSError{Err: "no such host", Name: "api.test", IsNotFound: true}
+	}
+
+	t.Run("success: first resolver", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakeResolver{name: "primary", addresses: []string{"127.0.0.1"}}
+		alternate := &fakeResolver{name: "alternate", addresses: []string{"127.0.0.1"}}
+		conn, err := NewDNSDialer(primary, alternate).DialContext(context.Background(), "tcp", net.JoinHostPort("api.test", port))
+		require.NoError(t, err)
+		_ = conn.Close()
+		assert.Equal(t, 1, primary.calls)
+		assert.Equal(t, 0, alternate.calls)
+	})
+
+	t.Run("success: falls back to alternate resolver", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakeResolver{name: "primary", err: notFound()}
+		alternate := &fakeResolver{name: "alternate", addresses: []string{"127.0.0.1"}}
+
+		transport := &http.Transport{DialContext: NewDNSDialer(primary, alternate).DialContext}
+		t.Cleanup(transport.CloseIdleConnections)
+		httpClient := &http.Client{Transport: transport}
+
+		client := NewClient("http://api.test:"+port, WithDoFunc(httpClient.Do))
+		response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/"}, nil)
+		require.NoError(t, err)
+		defer func() {
+			_ = response.Body.Close()
+		}()
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("ok"), body)
+		assert.Equal(t, 1, primary.calls)
+		assert.Equal(t, 1, alternate.calls)
+	})
+
+	t.Run("success: IP address is not resolved", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakeResolver{name: "primary", err: notFound()}
+		conn, err := NewDNSDialer(primary).DialContext(context.Background(), "tcp", server.Listener.Addr().String())
+		require.NoError(t, err)
+		_ = conn.Close()
+		assert.Equal(t, 0, primary.calls)
+	})
+
+	t.Run("success: unreachable IPv6 address is raced by IPv4", func(t *testing.T) {
+		t.Parallel()
+
+		dialer := NewDNSDialer(&fakeResolver{name: "primary", addresses: []string{"2001:db8::1", "2001:db8::2", "127.0.0.1"}})
+		dialer.dialContext = func(ctx context.Context, network string, address string) (net.Conn, error) {
+			host, _, _ := net.SplitHostPort(address)
+			if host != "127.0.0.1" {
+				<-ctx.Done()
+
+				return nil, ctx.Err()
+			}
+
+			return (&net.Dialer{}).DialContext(ctx, network, address)
+		}
+
+		start := time.Now()
+		conn, err := dialer.DialContext(context.Background(), "tcp", net.JoinHostPort("api.test", port))
+		require.NoError(t, err)
+		_ = conn.Close()
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("failure: timeout shared among addresses", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		var timeouts []time.Duration
+
+		dialer := NewDNSDialer(&fakeResolver{name: "primary", addresses: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"}})
+		dialer.dialContext = func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+
+			mu.Lock()
+			timeouts = append(timeouts, time.Until(deadline))
+			mu.Unlock()
+
+			return nil, syscall.ECONNREFUSED
+		}
+
+		_, err := dialer.DialContext(context.Background(), "tcp", net.JoinHostPort("api.test", port))
+		require.ErrorIs(t, err, syscall.ECONNREFUSED)
+		require.Len(t, timeouts, 3)
+		assert.LessOrEqual(t, timeouts[0], defaultDialTimeout/3)
+	})
+
+	t.Run("failure: every resolver fails", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakeResolver{name: "primary", err: notFound()}
+		alternate := &fakeResolver{name: "alternate"}
+
+		transport := &http.Transport{DialContext: NewDNSDialer(primary, alternate).DialContext}
+		httpClient := &http.Client{Transport: transport}
+
+		client := NewClient("http://api.test:"+port, WithDoFunc(httpClient.Do))
+		_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/"}, nil)
+		require.Error(t, err)
+		assert.Equal(t, CategoryDNS, ClassifyError(err))
+
+		var resolutionError *DNSResolutionError
+		require.ErrorAs(t, err, &resolutionError)
+		assert.Equal(t, "api.test", resolutionError.Host)
+		require.Len(t, resolutionError.Attempts, 2)
+		assert.Equal(t, "primary", resolutionError.Attempts[0].Resolver)
+		assert.Equal(t, "alternate", resolutionError.Attempts[1].Resolver)
+		assert.Equal(t,
+			"failed to resolve api.test: primary: lookup api.test: no such host; alternate: lookup api.test: no addresses found",
+			resolutionError.Error(),
+		)
+	})
+}
+
+func TestServerResolver_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "10.0.0.10:53", NewServerResolver("10.0.0.10:53").String())
+	assert.Equal(t, "system", describeResolver(net.DefaultResolver))
+}
+
+func TestPartialDeadline(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		deadline  time.Time
+		remaining int
+		want      time.Time
+	}{
+		{name: "success: split among addresses", deadline: now.Add(30 * time.Second), remaining: 3, want: now.Add(10 * time.Second)},
+		{name: "success: minimum share", deadline: now.Add(30 * time.Second), remaining: 30, want: now.Add(2 * time.Second)},
+		{name: "success: less than the minimum left", deadline: now.Add(time.Second), remaining: 3, want: now.Add(time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, partialDeadline(now, tt.deadline, tt.remaining))
+		})
+	}
+}
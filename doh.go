@@ -0,0 +1,116 @@
+package webapiclient
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Compile-time check to ensure DoHResolver implements Resolver interface.
+var _ Resolver = (*DoHResolver)(nil)
+
+// DNS record types and response codes used by DoHResolver.
+const (
+	dnsTypeA         = 1
+	dnsTypeAAAA      = 28
+	dnsRcodeNoError  = 0
+	dnsRcodeNXDomain = 3
+)
+
+// dohResponse is the JSON response of a DNS-over-HTTPS query.
+type dohResponse struct {
+	Status int `json:"Status"` //nolint:tagliatelle
+	Answer []struct {
+		Type int    `json:"type"`
+		Data string `json:"data"`
+	} `json:"Answer"` //nolint:tagliatelle
+}
+
+// DoHResolver resolves host names with DNS-over-HTTPS, for environments where plaintext DNS is
+// blocked or untrusted. It sends queries with a Client, using the JSON API served by public
+// resolvers such as https://cloudflare-dns.com/dns-query and https://dns.google/resolve.
+// Use it in a DNSDialer followed by net.DefaultResolver to fall back to the system resolver.
+type DoHResolver struct {
+	client Client
+	path   string
+}
+
+// NewDoHResolver creates a new DoHResolver querying path on the base URL of client.
+// The base URL should use an IP address, such as https://1.1.1.1, or client should not dial
+// through a DNSDialer using this resolver, so that reaching the resolver needs no lookup itself.
+func NewDoHResolver(client Client, path string) *DoHResolver {
+	return &DoHResolver{
+		client: client,
+		path:   path,
+	}
+}
+
+// LookupHost resolves the IPv4 and IPv6 addresses of host.
+func (r *DoHResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	var addresses []string
+
+	for _, recordType := range []int{dnsTypeA, dnsTypeAAAA} {
+		found, err := r.lookup(ctx, host, recordType)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		addresses = append(addresses, found...)
+	}
+
+	if len(addresses) == 0 {
+		return nil, &net.DNSError{Err: "no addresses found", Name: host, Server: r.String(), IsNotFound: true}
+	}
+
+	return addresses, nil
+}
+
+// String returns the path queried by the resolver.
+func (r *DoHResolver) String() string {
+	return "doh:" + r.path
+}
+
+func (r *DoHResolver) lookup(ctx context.Context, host string, recordType int) ([]string, error) {
+	response, err := r.client.Do(ctx, &Request{
+		Method:              http.MethodGet,
+		Path:                r.path,
+		Query:               map[string][]string{"name": {host}, "type": {strconv.Itoa(recordType)}},
+		Headers:             map[string][]string{"Accept": {"application/dns-json"}},
+		ExpectedStatusCodes: []int{http.StatusOK},
+	}, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	var result dohResponse
+
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return nil, errors.WithStack(newError(CategoryDecode, err))
+	}
+
+	switch result.Status {
+	case dnsRcodeNoError:
+	case dnsRcodeNXDomain:
+		return nil, &net.DNSError{Err: "no such host", Name: host, Server: r.String(), IsNotFound: true}
+	default:
+		return nil, &net.DNSError{Err: "DNS response code " + strconv.Itoa(result.Status), Name: host, Server: r.String()}
+	}
+
+	var addresses []string
+
+	for _, answer := range result.Answer {
+		if answer.Type == recordType && net.ParseIP(answer.Data) != nil {
+			addresses = append(addresses, answer.Data)
+		}
+	}
+
+	return addresses, nil
+}
@@ -0,0 +1,109 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoHResolver_LookupHost(t *testing.T) {
+	t.Parallel()
+
+	// newDo answers each query with the body registered for its name and record type.
+	newDo := func(bodies map[string]string) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "application/dns-json", req.Header.Get("Accept"))
+			assert.Equal(t, "/dns-query", req.URL.Path)
+
+			query := req.URL.Query()
+			body, ok := bodies[query.Get("name")+"/"+query.Get("type")]
+			if !ok {
+				body = `{"Status":0}`
+			}
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+	}
+
+	tests := []struct {
+		name         string
+		bodies       map[string]string
+		want         []string
+		wantNotFound bool
+		wantErr      bool
+	}{
+		{
+			name: "success: IPv4 and IPv6 addresses",
+			bodies: map[string]string{
+				"api.example.com/1": `{"Status":0,"Answer":[` +
+					`{"name":"api.example.com","type":5,"data":"edge.example.net."},` +
+					`{"name":"edge.example.net","type":1,"data":"192.0.2.1"}]}`,
+				"api.example.com/28": `{"Status":0,"Answer":[{"name":"api.example.com","type":28,"data":"2001:db8::1"}]}`,
+			},
+			want: []string{"192.0.2.1", "2001:db8::1"},
+		},
+		{
+			name:         "failure: NXDOMAIN",
+			bodies:       map[string]string{"api.example.com/1": `{"Status":3}`},
+			wantNotFound: true,
+		},
+		{
+			name:         "failure: no addresses",
+			bodies:       map[string]string{},
+			wantNotFound: true,
+		},
+		{
+			name:    "failure: server failure",
+			bodies:  map[string]string{"api.example.com/1": `{"Status":2}`},
+			wantErr: true,
+		},
+		{
+			name:    "failure: invalid JSON",
+			bodies:  map[string]string{"api.example.com/1": `<html>`},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver := NewDoHResolver(NewClient("https://1.1.1.1", WithDoFunc(newDo(tt.bodies))), "/dns-query")
+
+			got, err := resolver.LookupHost(context.Background(), "api.example.com")
+			if tt.wantNotFound || tt.wantErr {
+				require.Error(t, err)
+
+				var dnsError *net.DNSError
+				if tt.wantNotFound {
+					require.ErrorAs(t, err, &dnsError)
+					assert.True(t, dnsError.IsNotFound)
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDoHResolver_fallback(t *testing.T) {
+	t.Parallel()
+
+	unreachable := NewDoHResolver(NewClient("https://1.1.1.1", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+	})), "/dns-query")
+	system := &fakeResolver{name: "system", addresses: []string{"127.0.0.1"}}
+
+	got, err := NewDNSDialer(unreachable, system).lookupHost(context.Background(), "api.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, got)
+	assert.Equal(t, 1, system.calls)
+}
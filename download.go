@@ -0,0 +1,232 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Filename returns the file name suggested by the Content-Disposition header of response (RFC 6266).
+// Extended UTF-8 and ISO-8859-1 file names (filename*) take precedence over plain ones.
+// The returned name is not sanitized; use SanitizeFilename before using it as a path.
+func Filename(response *Response) (string, bool) {
+	value := getHeader(response.Headers, "Content-Disposition")
+	if value == "" {
+		return "", false
+	}
+
+	_, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return "", false
+	}
+
+	// mime.ParseMediaType decodes only UTF-8 extended values, in place of the plain ones,
+	// so ISO-8859-1 ones are decoded here first.
+	if filename, ok := parseLatin1ExtendedFilename(value); ok {
+		return filename, true
+	}
+
+	if filename := params["filename"]; filename != "" {
+		return filename, true
+	}
+
+	return "", false
+}
+
+func parseLatin1ExtendedFilename(value string) (string, bool) {
+	for _, param := range strings.Split(value, ";") {
+		key, extendedValue, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "filename*") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSpace(extendedValue), "'", 3) //nolint:mnd
+		if len(parts) != 3 || !strings.EqualFold(parts[0], "iso-8859-1") {
+			continue
+		}
+
+		decoded, err := url.PathUnescape(parts[2])
+		if err != nil || decoded == "" {
+			continue
+		}
+
+		runes := make([]rune, 0, len(decoded))
+		for _, b := range []byte(decoded) {
+			runes = append(runes, rune(b))
+		}
+
+		return string(runes), true
+	}
+
+	return "", false
+}
+
+// SanitizeFilename reduces name to a safe base file name by removing directory components,
+// control characters, leading dots and characters reserved on common file systems.
+// It returns an empty string when nothing usable remains.
+func SanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r) || r == '/':
+			return -1
+		case strings.ContainsRune(`<>:"|?*`, r):
+			return '_'
+		default:
+			return r
+		}
+	}, name)
+
+	return strings.TrimLeft(strings.TrimSpace(name), ".")
+}
+
+// SaveToDir saves the body of response into dir under the sanitized file name suggested by
+// the Content-Disposition header, or under fallback when there is none, and returns the path of the file.
+// The file is created through an os.Root so that it cannot escape dir, and an existing file is never
+// overwritten: the error then matches fs.ErrExist. A file left incomplete by a failed copy is removed.
+func SaveToDir(response *Response, dir string, fallback string) (string, error) {
+	name, _ := Filename(response)
+
+	name = SanitizeFilename(name)
+	if name == "" {
+		name = SanitizeFilename(fallback)
+	}
+
+	if name == "" {
+		return "", errors.New("no usable file name")
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer func() {
+		_ = root.Close()
+	}()
+
+	file, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644) //nolint:mnd
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	_, err = io.Copy(file, response.Body)
+	if err != nil {
+		_ = file.Close()
+		_ = root.Remove(name)
+
+		return "", errors.WithStack(err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// ProgressFunc is called with the number of body bytes read so far and the total size of the body,
+// which is -1 when the response has no valid Content-Length header.
+type ProgressFunc func(written int64, total int64)
+
+// progressContextKey is the context key of the ProgressFunc attached with WithDownloadProgress.
+type progressContextKey struct{}
+
+// WithDownloadProgress returns a copy of ctx whose downloads with DoDownload report their progress to fn.
+func WithDownloadProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, fn)
+}
+
+// TrackProgress replaces the body of response with one calling fn after every read that returned data,
+// for example to render a progress bar.
+func TrackProgress(response *Response, fn ProgressFunc) {
+	total := int64(-1)
+	if length, err := strconv.ParseInt(getHeader(response.Headers, "Content-Length"), 10, 64); err == nil && length >= 0 {
+		total = length
+	}
+
+	response.Body = &progressReader{ReadCloser: response.Body, fn: fn, total: total}
+}
+
+type progressReader struct {
+	io.ReadCloser
+
+	fn      ProgressFunc
+	written int64
+	total   int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.written += int64(n)
+		r.fn(r.written, r.total)
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// DoDownload sends request and copies the body of the response into w until it ends or ctx is done.
+// It returns the number of bytes written and the response, whose body is closed and replaced by http.NoBody.
+// Errors reading the body are classified like transport errors; errors writing into w are returned as is.
+// The progress is reported to the ProgressFunc attached to ctx with WithDownloadProgress.
+func DoDownload(ctx context.Context, client Client, request *Request, w io.Writer) (int64, *Response, error) {
+	response, err := client.Do(ctx, request, nil)
+	if err != nil {
+		// The response is returned as is when the client was created with WithResponseOnError.
+		return 0, response, errors.WithStack(err)
+	}
+
+	if fn, ok := ctx.Value(progressContextKey{}).(ProgressFunc); ok {
+		TrackProgress(response, fn)
+	}
+
+	body := &contextReader{ctx: ctx, r: response.Body}
+	written, err := io.Copy(w, body)
+
+	_ = response.Body.Close()
+	response.Body = http.NoBody
+
+	if body.err != nil {
+		return written, response, errors.WithStack(classifyTransportError(body.err))
+	}
+
+	if err != nil {
+		return written, response, errors.WithStack(err)
+	}
+
+	return written, response, nil
+}
+
+// contextReader reads from r until ctx is done, and keeps the error reading from r other than io.EOF.
+type contextReader struct {
+	ctx context.Context //nolint:containedctx
+	r   io.Reader
+	err error
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		r.err = err
+
+		return 0, err //nolint:wrapcheck
+	}
+
+	n, err := r.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+
+	return n, err //nolint:wrapcheck
+}
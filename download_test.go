@@ -0,0 +1,341 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilename(t *testing.T) {
+	t.Parallel()
+
+	type want struct {
+		filename string
+		ok       bool
+	}
+	tests := []struct {
+		name   string
+		header string
+		want   want
+	}{
+		{
+			name:   "success: quoted filename",
+			header: `attachment; filename="report.csv"`,
+			want:   want{filename: "report.csv", ok: true},
+		},
+		{
+			name:   "success: UTF-8 extended filename takes precedence",
+			header: `attachment; filename="rates.txt"; filename*=UTF-8''%e2%82%ac%20rates.txt`,
+			want:   want{filename: "€ rates.txt", ok: true},
+		},
+		{
+			name:   "success: ISO-8859-1 extended filename",
+			header: `attachment; filename*=iso-8859-1'en'caf%E9.txt`,
+			want:   want{filename: "café.txt", ok: true},
+		},
+		{
+			name:   "success: ISO-8859-1 extended filename takes precedence",
+			header: `attachment; filename="cafe.txt"; filename*=iso-8859-1'en'caf%E9.txt`,
+			want:   want{filename: "café.txt", ok: true},
+		},
+		{
+			name:   "success: traversal is returned as is",
+			header: `attachment; filename="../../etc/passwd"`,
+			want:   want{filename: "../../etc/passwd", ok: true},
+		},
+		{
+			name:   "failure: no filename",
+			header: `inline`,
+			want:   want{},
+		},
+		{
+			name:   "failure: no header",
+			header: "",
+			want:   want{},
+		},
+		{
+			name:   "failure: malformed header",
+			header: `attachment; filename`,
+			want:   want{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			headers := map[string][]string{}
+			if tt.header != "" {
+				headers["Content-Disposition"] = []string{tt.header}
+			}
+
+			filename, ok := Filename(&Response{Headers: headers})
+			assert.Equal(t, tt.want.ok, ok)
+			assert.Equal(t, tt.want.filename, filename)
+		})
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{name: "success: plain name", filename: "report.csv", want: "report.csv"},
+		{name: "success: unix traversal", filename: "../../etc/passwd", want: "passwd"},
+		{name: "success: windows traversal", filename: `..\..\windows\system.ini`, want: "system.ini"},
+		{name: "success: hidden file", filename: ".bashrc", want: "bashrc"},
+		{name: "success: reserved characters", filename: "a<b>:c?.txt", want: "a_b__c_.txt"},
+		{name: "success: control characters", filename: "a\x00b\nc.txt", want: "abc.txt"},
+		{name: "success: parent directory only", filename: "..", want: ""},
+		{name: "success: empty", filename: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, SanitizeFilename(tt.filename))
+		})
+	}
+}
+
+func TestSaveToDir(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		header   string
+		fallback string
+		want     string
+		wantErr  bool
+	}{
+		{name: "success: suggested name", header: `attachment; filename="report.csv"`, fallback: "download", want: "report.csv"},
+		{name: "success: traversal sanitized", header: `attachment; filename="../../evil.sh"`, fallback: "download", want: "evil.sh"},
+		{name: "success: fallback", header: `attachment`, fallback: "download.bin", want: "download.bin"},
+		{name: "failure: no usable name", header: `attachment; filename=".."`, fallback: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			response := &Response{
+				Headers: map[string][]string{"Content-Disposition": {tt.header}},
+				Body:    io.NopCloser(bytes.NewReader([]byte("content"))),
+			}
+
+			got, err := SaveToDir(response, dir, tt.fallback)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tt.want), got)
+
+			data, err := os.ReadFile(got)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("content"), data)
+		})
+	}
+}
+
+func TestSaveToDir_Existing(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.csv"), []byte("existing"), 0o600))
+
+	response := &Response{
+		Headers: map[string][]string{"Content-Disposition": {`attachment; filename="report.csv"`}},
+		Body:    io.NopCloser(bytes.NewReader([]byte("content"))),
+	}
+
+	_, err := SaveToDir(response, dir, "download")
+	require.ErrorIs(t, err, fs.ErrExist)
+
+	data, err := os.ReadFile(filepath.Join(dir, "report.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("existing"), data)
+}
+
+func TestSaveToDir_CopyError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	response := &Response{
+		Headers: map[string][]string{"Content-Disposition": {`attachment; filename="report.csv"`}},
+		Body:    io.NopCloser(iotest.ErrReader(io.ErrUnexpectedEOF)),
+	}
+
+	_, err := SaveToDir(response, dir, "download")
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.NoFileExists(t, filepath.Join(dir, "report.csv"))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(_ []byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func TestDoDownload(t *testing.T) {
+	t.Parallel()
+
+	newClient := func(status int, body io.Reader) Client {
+		return NewClient("http://example.com", WithDoFunc(func(_ *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": {"text/csv"}},
+				Body:       io.NopCloser(body),
+			}, nil
+		}))
+	}
+
+	request := &Request{Method: http.MethodGet, Path: "/exports/1.csv", ExpectedStatusCodes: []int{http.StatusOK}}
+
+	t.Run("success: copies the body", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+
+		written, response, err := DoDownload(context.Background(), newClient(http.StatusOK, strings.NewReader("a,b\n1,2\n")), request, &buffer)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), written)
+		assert.Equal(t, "a,b\n1,2\n", buffer.String())
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, []string{"text/csv"}, response.Headers["Content-Type"])
+		assert.Equal(t, http.NoBody, response.Body)
+	})
+
+	t.Run("success: reports progress", func(t *testing.T) {
+		t.Parallel()
+
+		var progress [][2]int64
+
+		ctx := WithDownloadProgress(context.Background(), func(written, total int64) {
+			progress = append(progress, [2]int64{written, total})
+		})
+		body := iotest.OneByteReader(strings.NewReader("abc"))
+
+		written, _, err := DoDownload(ctx, newClient(http.StatusOK, body), request, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), written)
+		assert.Equal(t, [][2]int64{{1, -1}, {2, -1}, {3, -1}}, progress)
+	})
+
+	t.Run("failure: unexpected status code", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+
+		written, _, err := DoDownload(context.Background(), newClient(http.StatusNotFound, strings.NewReader("missing")), request, &buffer)
+		require.Error(t, err)
+		assert.Equal(t, CategoryHTTPStatus, ClassifyError(err))
+		assert.Zero(t, written)
+		assert.Empty(t, buffer.String())
+	})
+
+	t.Run("failure: body read error", func(t *testing.T) {
+		t.Parallel()
+
+		body := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(syscall.ECONNRESET))
+
+		var buffer bytes.Buffer
+
+		written, response, err := DoDownload(context.Background(), newClient(http.StatusOK, body), request, &buffer)
+		require.Error(t, err)
+		assert.Equal(t, CategoryNetwork, ClassifyError(err))
+		assert.Equal(t, int64(7), written)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("failure: write error", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := DoDownload(context.Background(), newClient(http.StatusOK, strings.NewReader("data")), request, failingWriter{})
+		require.ErrorIs(t, err, os.ErrClosed)
+	})
+
+	t.Run("failure: context canceled while copying", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		body := io.MultiReader(strings.NewReader("first"), readerFunc(func(p []byte) (int, error) {
+			cancel()
+
+			return copy(p, "second"), nil
+		}), strings.NewReader("third"))
+
+		var buffer bytes.Buffer
+
+		_, _, err := DoDownload(ctx, newClient(http.StatusOK, body), request, &buffer)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.NotContains(t, buffer.String(), "third")
+	})
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestTrackProgress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		contentLength string
+		wantTotal     int64
+	}{
+		{name: "success: Content-Length", contentLength: "10", wantTotal: 10},
+		{name: "success: no Content-Length", contentLength: "", wantTotal: -1},
+		{name: "success: invalid Content-Length", contentLength: "-5", wantTotal: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response := &Response{
+				Headers: map[string][]string{},
+				Body:    io.NopCloser(strings.NewReader("0123456789")),
+			}
+			if tt.contentLength != "" {
+				response.Headers["Content-Length"] = []string{tt.contentLength}
+			}
+
+			var got [][2]int64
+
+			TrackProgress(response, func(written, total int64) {
+				got = append(got, [2]int64{written, total})
+			})
+
+			buffer := make([]byte, 4)
+			for {
+				_, err := response.Body.Read(buffer)
+				if err != nil {
+					require.ErrorIs(t, err, io.EOF)
+
+					break
+				}
+			}
+
+			require.NoError(t, response.Body.Close())
+			assert.Equal(t, [][2]int64{{4, tt.wantTotal}, {8, tt.wantTotal}, {10, tt.wantTotal}}, got)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package webapiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorDecoderFunc decodes the body of a response with an unexpected status code into an error describing
+// the failure, or returns nil when the body does not describe one.
+type ErrorDecoderFunc func(statusCode int, body []byte) error
+
+// WithErrorDecoder decodes the body of responses with an unexpected status code with decoder when their
+// Content-Type matches contentType, so that servers answering in different formats are all reported with
+// the best available structured error. contentType is a media type such as "application/problem+json",
+// a structured syntax suffix such as "+json", a type wildcard such as "text/*", or "*" matching any response.
+// Decoders are tried from the most to the least specific match until one returns an error, which is then
+// set as the Err of the *APIError returned by Do.
+func WithErrorDecoder(contentType string, decoder ErrorDecoderFunc) Option {
+	key := strings.ToLower(strings.TrimSpace(contentType))
+
+	return func(c *client) {
+		if c.errorDecoders == nil {
+			c.errorDecoders = map[string]ErrorDecoderFunc{}
+		}
+
+		c.errorDecoders[key] = decoder
+	}
+}
+
+// JSONErrorDecoder returns an ErrorDecoderFunc unmarshaling JSON bodies into a T, whose pointer is the error.
+// Bodies that are not valid JSON for T are not decoded.
+func JSONErrorDecoder[T any, PT interface {
+	*T
+	error
+}]() ErrorDecoderFunc {
+	return func(_ int, body []byte) error {
+		var v T
+
+		err := json.Unmarshal(body, &v)
+		if err != nil {
+			return nil
+		}
+
+		return PT(&v)
+	}
+}
+
+// TextErrorDecoder is an ErrorDecoderFunc returning the trimmed body, truncated to a short snippet, as the error.
+// Empty bodies are not decoded.
+func TextErrorDecoder(_ int, body []byte) error {
+	text := bytes.TrimSpace(body)
+	if len(text) == 0 {
+		return nil
+	}
+
+	return errors.New(string(truncateSnippet(text)))
+}
+
+// decodeError returns the error decoded from body by the best matching error decoder for contentType,
+// falling back to ProblemDetailsDecoder for problem details, or nil.
+func (c *client) decodeError(statusCode int, contentType string, body []byte) error {
+	for _, decoder := range c.matchErrorDecoders(contentType) {
+		decoded := decoder(statusCode, body)
+		if decoded != nil {
+			return decoded
+		}
+	}
+
+	if isProblemDetails(contentType) {
+		return ProblemDetailsDecoder(statusCode, body)
+	}
+
+	return nil
+}
+
+// matchErrorDecoders returns the error decoders matching contentType, from the most to the least specific.
+func (c *client) matchErrorDecoders(contentType string) []ErrorDecoderFunc {
+	if len(c.errorDecoders) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	keys := make([]string, 0, 4)
+	if mediaType != "" {
+		keys = append(keys, mediaType)
+
+		if _, suffix, ok := strings.Cut(mediaType, "+"); ok {
+			keys = append(keys, "+"+suffix)
+		}
+
+		if typ, _, ok := strings.Cut(mediaType, "/"); ok {
+			keys = append(keys, typ+"/*")
+		}
+	}
+
+	keys = append(keys, "*")
+
+	var decoders []ErrorDecoderFunc
+
+	for _, key := range keys {
+		if decoder, ok := c.errorDecoders[key]; ok {
+			decoders = append(decoders, decoder)
+		}
+	}
+
+	return decoders
+}
@@ -0,0 +1,145 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type problemError struct {
+	Title string `json:"title"`
+}
+
+func (e *problemError) Error() string {
+	return "problem: " + e.Title
+}
+
+type vendorError struct {
+	Code string `json:"code"`
+}
+
+func (e *vendorError) Error() string {
+	return "vendor: " + e.Code
+}
+
+func TestClientImpl_Do_ErrorDecoder(t *testing.T) {
+	t.Parallel()
+
+	options := []Option{
+		WithErrorDecoder("application/problem+json", JSONErrorDecoder[problemError]()),
+		WithErrorDecoder("+JSON", JSONErrorDecoder[vendorError]()),
+		WithErrorDecoder("*", TextErrorDecoder),
+		WithResponseOnError(),
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     string
+	}{
+		{
+			name:        "success: exact media type",
+			contentType: "application/problem+json; charset=utf-8",
+			body:        `{"title":"Out of credit"}`,
+			wantErr:     "unexpected status code: 403: problem: Out of credit",
+		},
+		{
+			name:        "success: structured syntax suffix",
+			contentType: "application/vnd.example+json",
+			body:        `{"code":"E42"}`,
+			wantErr:     "unexpected status code: 403: vendor: E42",
+		},
+		{
+			name:        "success: falls back to a less specific decoder",
+			contentType: "application/vnd.example+json",
+			body:        `not json`,
+			wantErr:     "unexpected status code: 403: not json",
+		},
+		{
+			name:        "success: any content type",
+			contentType: "text/plain",
+			body:        "  forbidden\n",
+			wantErr:     "unexpected status code: 403: forbidden",
+		},
+		{
+			name:        "success: nothing decoded",
+			contentType: "",
+			body:        "",
+			wantErr:     "unexpected status code: 403",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", append([]Option{WithDoFunc(func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusForbidden,
+					Header:     http.Header{"Content-Type": {tt.contentType}},
+					Body:       io.NopCloser(strings.NewReader(tt.body)),
+				}, nil
+			})}, options...)...)
+
+			response, err := client.Do(context.Background(), &Request{Path: "/", ExpectedStatusCodes: []int{http.StatusOK}}, nil)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			assert.Equal(t, CategoryHTTPStatus, ClassifyError(err))
+
+			body, readErr := io.ReadAll(response.Body)
+			require.NoError(t, readErr)
+			assert.Equal(t, tt.body, string(body), "the body is kept for the caller")
+		})
+	}
+
+	t.Run("success: errors.As finds the decoded error", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("http://example.com", append([]Option{WithDoFunc(func(_ *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusConflict,
+				Header:     http.Header{"Content-Type": {"application/problem+json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"title":"Conflict"}`)),
+			}, nil
+		})}, options...)...)
+
+		_, err := client.Do(context.Background(), &Request{Path: "/", ExpectedStatusCodes: []int{http.StatusOK}}, nil)
+
+		var problem *problemError
+		require.ErrorAs(t, err, &problem)
+		assert.Equal(t, "Conflict", problem.Title)
+	})
+}
+
+func TestClientImpl_MatchErrorDecoders(t *testing.T) {
+	t.Parallel()
+
+	var order []string
+
+	decoder := func(name string) ErrorDecoderFunc {
+		return func(_ int, _ []byte) error {
+			order = append(order, name)
+
+			return nil
+		}
+	}
+	c := newClient("http://example.com",
+		WithErrorDecoder("*", decoder("*")),
+		WithErrorDecoder("application/*", decoder("application/*")),
+		WithErrorDecoder("+xml", decoder("+xml")),
+		WithErrorDecoder("application/atom+xml", decoder("application/atom+xml")),
+	)
+
+	for _, decoder := range c.matchErrorDecoders("Application/Atom+XML; type=feed") {
+		_ = decoder(http.StatusBadRequest, nil)
+	}
+
+	assert.Equal(t, []string{"application/atom+xml", "+xml", "application/*", "*"}, order)
+	assert.Len(t, c.matchErrorDecoders("text/html"), 1)
+	assert.Empty(t, newClient("http://example.com").matchErrorDecoders("text/html"))
+}
@@ -0,0 +1,195 @@
+package webapiclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorCategory classifies errors returned by the client.
+type ErrorCategory string
+
+const (
+	// CategoryUnknown is the category of errors that cannot be classified.
+	CategoryUnknown ErrorCategory = "unknown"
+	// CategoryNetwork is the category of connection level failures.
+	CategoryNetwork ErrorCategory = "network"
+	// CategoryTimeout is the category of requests that ran out of time.
+	CategoryTimeout ErrorCategory = "timeout"
+	// CategoryDNS is the category of name resolution failures.
+	CategoryDNS ErrorCategory = "dns"
+	// CategoryTLS is the category of TLS handshake and certificate failures.
+	CategoryTLS ErrorCategory = "tls"
+	// CategoryHTTPStatus is the category of responses with an unexpected status code.
+	CategoryHTTPStatus ErrorCategory = "http_status"
+	// CategoryDecode is the category of response bodies that cannot be decoded.
+	CategoryDecode ErrorCategory = "decode"
+	// CategoryValidation is the category of invalid requests and unexpected responses.
+	CategoryValidation ErrorCategory = "validation"
+)
+
+// ErrRequestBuild is matched by the errors of requests that could not be validated, built or edited,
+// and were therefore not sent.
+var ErrRequestBuild = errors.New("request build failed")
+
+// Error is an error returned by the client, classified by category.
+type Error struct {
+	category   ErrorCategory
+	temporary  bool
+	timeout    bool
+	artifactID string
+	err        error
+}
+
+// Error returns the message of the underlying error, followed by the artifact reference ID when captured.
+func (e *Error) Error() string {
+	if e.artifactID != "" {
+		return e.err.Error() + " (artifact: " + e.artifactID + ")"
+	}
+
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Category returns the category of the error.
+func (e *Error) Category() ErrorCategory {
+	return e.category
+}
+
+// Temporary reports whether the same request may succeed if it is retried.
+func (e *Error) Temporary() bool {
+	return e.temporary
+}
+
+// Timeout reports whether the error was caused by a timeout.
+func (e *Error) Timeout() bool {
+	return e.timeout
+}
+
+// ArtifactID returns the reference ID of the failure artifact captured for the error, or an empty string.
+func (e *Error) ArtifactID() string {
+	return e.artifactID
+}
+
+// ClassifyError returns the category of err, or CategoryUnknown when err was not returned by the client.
+func ClassifyError(err error) ErrorCategory {
+	var clientError *Error
+	if errors.As(err, &clientError) {
+		return clientError.category
+	}
+
+	return CategoryUnknown
+}
+
+func newError(category ErrorCategory, err error) *Error {
+	return &Error{
+		category: category,
+		err:      err,
+	}
+}
+
+// RequestBuildError is returned, classified as CategoryValidation, for requests that could not be validated,
+// built or edited.
+type RequestBuildError struct {
+	// Err is the error that prevented building the request.
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *RequestBuildError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *RequestBuildError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrRequestBuild.
+func (e *RequestBuildError) Is(target error) bool {
+	return target == ErrRequestBuild //nolint:errorlint
+}
+
+func newRequestBuildError(err error) *Error {
+	return newError(CategoryValidation, &RequestBuildError{Err: err})
+}
+
+func newStatusCodeError(statusCode int, err error) *Error {
+	return &Error{
+		category:  CategoryHTTPStatus,
+		temporary: statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError,
+		err:       err,
+	}
+}
+
+// classifyTransportError classifies an error returned while sending a request.
+func classifyTransportError(err error) *Error {
+	if errors.Is(err, context.Canceled) {
+		return newError(CategoryUnknown, err)
+	}
+
+	if errors.Is(err, ErrBodyTooLarge) {
+		return newError(CategoryValidation, err)
+	}
+
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return &Error{
+			category:  CategoryDNS,
+			temporary: dnsError.IsTemporary || dnsError.IsTimeout,
+			timeout:   dnsError.IsTimeout,
+			err:       err,
+		}
+	}
+
+	if isTLSError(err) {
+		return newError(CategoryTLS, err)
+	}
+
+	// Retrying a request rejected by an open circuit would only be rejected again until the cooldown has passed.
+	if errors.Is(err, ErrCircuitOpen) {
+		return newError(CategoryNetwork, err)
+	}
+
+	var netError net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netError) && netError.Timeout()) {
+		return &Error{
+			category:  CategoryTimeout,
+			temporary: true,
+			timeout:   true,
+			err:       err,
+		}
+	}
+
+	return &Error{
+		category:  CategoryNetwork,
+		temporary: true,
+		err:       err,
+	}
+}
+
+func isTLSError(err error) bool {
+	var (
+		recordHeaderError       tls.RecordHeaderError
+		alertError              tls.AlertError
+		verificationError       *tls.CertificateVerificationError
+		unknownAuthorityError   x509.UnknownAuthorityError
+		hostnameError           x509.HostnameError
+		certificateInvalidError x509.CertificateInvalidError
+	)
+
+	return errors.As(err, &recordHeaderError) ||
+		errors.As(err, &alertError) ||
+		errors.As(err, &verificationError) ||
+		errors.As(err, &unknownAuthorityError) ||
+		errors.As(err, &hostnameError) ||
+		errors.As(err, &certificateInvalidError)
+}
@@ -0,0 +1,218 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClientImpl_Do_ErrorCategory(t *testing.T) {
+	t.Parallel()
+
+	newResponse := func(status int, contentType string) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       io.NopCloser(bytes.NewReader([]byte{})),
+		}
+	}
+
+	type fields struct {
+		do DoFunc
+	}
+	type args struct {
+		request *Request
+		edit    EditRequestFunc
+	}
+	type want struct {
+		category  ErrorCategory
+		temporary bool
+		timeout   bool
+		is        error
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   want
+	}{
+		{
+			name: "failure: invalid path",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return newResponse(http.StatusOK, "application/json"), nil
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "%zz"}},
+			want: want{category: CategoryValidation, is: ErrRequestBuild},
+		},
+		{
+			name: "failure: edit request",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return newResponse(http.StatusOK, "application/json"), nil
+				},
+			},
+			args: args{
+				request: &Request{Method: http.MethodGet, Path: "/test"},
+				edit: func(req *http.Request) error {
+					return errors.New("edit failed")
+				},
+			},
+			want: want{category: CategoryValidation, is: ErrRequestBuild},
+		},
+		{
+			name: "failure: connection refused",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test"}},
+			want: want{category: CategoryNetwork, temporary: true},
+		},
+		{
+			name: "failure: timeout",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: timeoutError{}}
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test"}},
+			want: want{category: CategoryTimeout, temporary: true, timeout: true},
+		},
+		{
+			name: "failure: context deadline exceeded",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return nil, context.DeadlineExceeded
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test"}},
+			want: want{category: CategoryTimeout, temporary: true, timeout: true},
+		},
+		{
+			name: "failure: context canceled",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return nil, context.Canceled
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test"}},
+			want: want{category: CategoryUnknown},
+		},
+		{
+			name: "failure: DNS not found",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}}
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test"}},
+			want: want{category: CategoryDNS},
+		},
+		{
+			name: "failure: DNS timeout",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return nil, &net.DNSError{Err: "timeout", Name: "example.com", IsTimeout: true}
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test"}},
+			want: want{category: CategoryDNS, temporary: true, timeout: true},
+		},
+		{
+			name: "failure: TLS certificate",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: x509.UnknownAuthorityError{}}
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test"}},
+			want: want{category: CategoryTLS},
+		},
+		{
+			name: "failure: circuit open is not temporary",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return nil, &CircuitOpenError{Key: "example.com"}
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test"}},
+			want: want{category: CategoryNetwork, is: ErrCircuitOpen},
+		},
+		{
+			name: "failure: unexpected status code",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return newResponse(http.StatusNotFound, "application/json"), nil
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test", ExpectedStatusCodes: []int{http.StatusOK}}},
+			want: want{category: CategoryHTTPStatus, is: ErrUnexpectedStatusCode},
+		},
+		{
+			name: "failure: unexpected status code is temporary",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return newResponse(http.StatusServiceUnavailable, "application/json"), nil
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test", ExpectedStatusCodes: []int{http.StatusOK}}},
+			want: want{category: CategoryHTTPStatus, temporary: true, is: ErrUnexpectedStatusCode},
+		},
+		{
+			name: "failure: unexpected content type",
+			fields: fields{
+				do: func(req *http.Request) (*http.Response, error) {
+					return newResponse(http.StatusOK, "text/plain"), nil
+				},
+			},
+			args: args{request: &Request{Method: http.MethodGet, Path: "/test", ExpectedContentTypes: []string{"application/json"}}},
+			want: want{category: CategoryValidation, is: ErrUnexpectedContentType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", WithDoFunc(tt.fields.do))
+
+			_, err := client.Do(context.Background(), tt.args.request, tt.args.edit)
+			require.Error(t, err)
+			assert.Equal(t, tt.want.category, ClassifyError(err))
+
+			var clientError *Error
+			require.ErrorAs(t, err, &clientError)
+			assert.Equal(t, tt.want.category, clientError.Category())
+			assert.Equal(t, tt.want.temporary, clientError.Temporary())
+			assert.Equal(t, tt.want.timeout, clientError.Timeout())
+
+			for _, sentinel := range []error{ErrRequestBuild, ErrUnexpectedStatusCode, ErrUnexpectedContentType, ErrCircuitOpen} {
+				assert.Equal(t, sentinel == tt.want.is, errors.Is(err, sentinel), sentinel) //nolint:errorlint
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, CategoryUnknown, ClassifyError(errors.New("other")))
+	assert.Equal(t, CategoryDecode, ClassifyError(errors.WithStack(newError(CategoryDecode, errors.New("decode")))))
+}
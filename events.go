@@ -0,0 +1,155 @@
+package webapiclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// eventBusContextKey is the context key of the EventBus of the client sending a request.
+type eventBusContextKey struct{}
+
+// Event is an event published on an EventBus: one of RequestStarted, RetryScheduled, CircuitOpened,
+// CacheHit and TokenRefreshed.
+type Event interface {
+	isEvent()
+}
+
+// RequestStarted is published when Do starts sending a request.
+type RequestStarted struct {
+	// Method is the method of the request.
+	Method string
+	// URL is the URL of the request, with credentials and sensitive query parameters redacted.
+	URL string
+	// Meta is the call metadata of the request restricted to the allowlist of WithCallMetaAllowlist, or nil.
+	Meta map[string]string
+}
+
+// RetryScheduled is published when the retry policy schedules another attempt of a request.
+type RetryScheduled struct {
+	// Method is the method of the request.
+	Method string
+	// URL is the URL of the request, with credentials and sensitive query parameters redacted.
+	URL string
+	// Attempt is the number of the scheduled attempt, starting at 2.
+	Attempt int
+	// Delay is the time waited before the attempt.
+	Delay time.Duration
+	// StatusCode is the status code of the failed attempt, or zero when it failed with Err.
+	StatusCode int
+	// Err is the transport error of the failed attempt, or nil.
+	Err error
+	// Meta is the call metadata of the request restricted to the allowlist of WithCallMetaAllowlist, or nil.
+	Meta map[string]string
+}
+
+// CircuitOpened is published when a CircuitBreaker opens a circuit.
+type CircuitOpened struct {
+	// Key is the key of the circuit.
+	Key string
+	// RetryAt is the time after which a probe request is allowed.
+	RetryAt time.Time
+}
+
+// CacheHit is published when a QueryCache answers a request from the cache.
+type CacheHit struct {
+	// URL is the URL of the request, with credentials and sensitive query parameters redacted.
+	URL string
+}
+
+// TokenRefreshed is published when a token source, such as the one of the oauth2 package, refreshes its token.
+type TokenRefreshed struct {
+	// Expiry is the expiry of the new token, or the zero time when it does not expire.
+	Expiry time.Time
+}
+
+func (RequestStarted) isEvent() {}
+func (RetryScheduled) isEvent() {}
+func (CircuitOpened) isEvent()  {}
+func (CacheHit) isEvent()       {}
+func (TokenRefreshed) isEvent() {}
+
+// EventBus delivers the events of clients to any number of subscribers, decoupling observability integrations
+// from the order of middlewares. Subscribers are called synchronously, in the order they subscribed, by the
+// goroutine publishing the event, and must therefore return quickly. It is safe for concurrent use.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+}
+
+type subscriber struct {
+	fn func(event Event)
+}
+
+// NewEventBus creates an EventBus without subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe calls fn for every event published on the bus, until the returned function is called.
+func (b *EventBus) Subscribe(fn func(event Event)) func() {
+	s := &subscriber{fn: fn}
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, s)
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		for i, current := range b.subscribers {
+			if current == s {
+				b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+
+				break
+			}
+		}
+	}
+}
+
+// SubscribeTo calls fn for every event of type E published on bus, until the returned function is called.
+func SubscribeTo[E Event](bus *EventBus, fn func(event E)) func() {
+	return bus.Subscribe(func(event Event) {
+		if typed, ok := event.(E); ok {
+			fn(typed)
+		}
+	})
+}
+
+// Publish delivers event to every subscriber.
+func (b *EventBus) Publish(event Event) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		s.fn(event)
+	}
+}
+
+// WithEventBus makes the client publish its events on bus. The bus is carried by the context of the requests
+// it sends, so that middlewares such as CircuitBreaker and QueryCache publish their events on it too.
+func WithEventBus(bus *EventBus) Option {
+	return func(c *client) {
+		c.eventBus = bus
+	}
+}
+
+// PublishEvent publishes event on the EventBus carried by ctx, if any. Middlewares and token sources
+// receive such a context with the requests of a client configured with WithEventBus.
+func PublishEvent(ctx context.Context, event Event) {
+	bus, ok := ctx.Value(eventBusContextKey{}).(*EventBus)
+	if ok {
+		bus.Publish(event)
+	}
+}
+
+// withEventBus returns a copy of ctx carrying the EventBus of the client, if any.
+func (c *client) withEventBus(ctx context.Context) context.Context {
+	if c.eventBus == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, eventBusContextKey{}, c.eventBus)
+}
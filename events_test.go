@@ -0,0 +1,164 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBus_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+
+	var all []Event
+
+	var hits []CacheHit
+
+	unsubscribe := bus.Subscribe(func(event Event) {
+		all = append(all, event)
+	})
+	SubscribeTo(bus, func(event CacheHit) {
+		hits = append(hits, event)
+	})
+
+	bus.Publish(RequestStarted{Method: http.MethodGet, URL: "http://example.com/a"})
+	bus.Publish(CacheHit{URL: "http://example.com/a"})
+	unsubscribe()
+	bus.Publish(CacheHit{URL: "http://example.com/b"})
+
+	assert.Equal(t, []Event{
+		RequestStarted{Method: http.MethodGet, URL: "http://example.com/a"},
+		CacheHit{URL: "http://example.com/a"},
+	}, all)
+	assert.Equal(t, []CacheHit{{URL: "http://example.com/a"}, {URL: "http://example.com/b"}}, hits)
+}
+
+func TestPublishEvent(t *testing.T) {
+	t.Parallel()
+
+	// Without a bus in the context, publishing is a no-op.
+	PublishEvent(context.Background(), CacheHit{})
+
+	bus := NewEventBus()
+
+	var got []Event
+
+	bus.Subscribe(func(event Event) {
+		got = append(got, event)
+	})
+
+	ctx := context.WithValue(context.Background(), eventBusContextKey{}, bus)
+	PublishEvent(ctx, TokenRefreshed{})
+
+	assert.Equal(t, []Event{TokenRefreshed{}}, got)
+}
+
+func TestClientImpl_Do_Events(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+
+	var got []Event
+
+	bus.Subscribe(func(event Event) {
+		got = append(got, event)
+	})
+
+	breaker := NewCircuitBreaker(2, time.Minute, nil)
+	client := NewClient("http://example.com",
+		WithEventBus(bus),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		WithDoFunc(breaker.Wrap(func(_ *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+		})),
+	)
+
+	_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/items?api_key=k", ExpectedStatusCodes: []int{http.StatusOK}}, nil)
+	require.Error(t, err)
+	require.Len(t, got, 3)
+
+	assert.Equal(t, RequestStarted{Method: http.MethodGet, URL: "http://example.com/items?api_key=REDACTED"}, got[0])
+
+	scheduled, ok := got[1].(RetryScheduled)
+	require.True(t, ok)
+	assert.Equal(t, http.MethodGet, scheduled.Method)
+	assert.Equal(t, "http://example.com/items?api_key=REDACTED", scheduled.URL)
+	assert.Equal(t, 2, scheduled.Attempt)
+	assert.Equal(t, http.StatusServiceUnavailable, scheduled.StatusCode)
+	assert.NoError(t, scheduled.Err)
+
+	opened, ok := got[2].(CircuitOpened)
+	require.True(t, ok)
+	assert.Equal(t, "example.com", opened.Key)
+	assert.False(t, opened.RetryAt.IsZero())
+}
+
+func TestClientImpl_Do_EventsCallMeta(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+
+	var got []Event
+
+	bus.Subscribe(func(event Event) {
+		got = append(got, event)
+	})
+
+	client := NewClient("http://example.com",
+		WithEventBus(bus),
+		WithCallMetaAllowlist("job_id", "tenant"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		WithDoFunc(func(_ *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}),
+	)
+
+	ctx := WithCallMeta(context.Background(), map[string]string{"job_id": "j1", "user_id": "u1"})
+
+	_, err := client.Do(ctx, &Request{Method: http.MethodGet, Path: "/items", ExpectedStatusCodes: []int{http.StatusOK}}, nil)
+	require.Error(t, err)
+	require.Len(t, got, 2)
+
+	started, ok := got[0].(RequestStarted)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"job_id": "j1"}, started.Meta)
+
+	scheduled, ok := got[1].(RetryScheduled)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"job_id": "j1"}, scheduled.Meta)
+}
+
+func TestQueryCache_Wrap_CacheHit(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus()
+
+	var hits []CacheHit
+
+	SubscribeTo(bus, func(event CacheHit) {
+		hits = append(hits, event)
+	})
+
+	cache := NewQueryCache(time.Minute, []string{"/search"}, nil)
+	client := NewClient("http://example.com", WithEventBus(bus), WithDoFunc(cache.Wrap(func(_ *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("[]"))}, nil
+	})))
+
+	for range 2 {
+		response, err := client.Do(context.Background(), &Request{Method: http.MethodPost, Path: "/search", JSON: map[string]string{"q": "go"}}, nil)
+		require.NoError(t, err)
+
+		_, err = io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+	}
+
+	assert.Equal(t, []CacheHit{{URL: "http://example.com/search"}}, hits)
+}
@@ -12,7 +12,7 @@ import (
 
 func main() {
 	// Create a new client with default HTTP client
-	client := webapiclient.NewClient(http.DefaultClient.Do, "http://google.com")
+	client := webapiclient.NewClient("http://google.com")
 
 	// Prepare the request
 	request := &webapiclient.Request{
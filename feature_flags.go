@@ -0,0 +1,66 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	// FlagRetry toggles the retry policy set with WithRetryPolicy.
+	FlagRetry = "retry"
+	// FlagArtifactCapture toggles the failure artifacts enabled with WithArtifactSink.
+	FlagArtifactCapture = "artifact_capture"
+)
+
+// Compile-time check to ensure StaticFlags implements FlagProvider interface.
+var _ FlagProvider = StaticFlags(nil)
+
+// FlagProvider resolves feature flags controlling client behavior per request,
+// for gradual rollout of behavior changes in large fleets.
+type FlagProvider interface {
+	// Enabled reports whether flag is enabled for the request whose context is ctx.
+	Enabled(ctx context.Context, flag string) bool
+}
+
+// FlagProviderFunc is an adapter to use an ordinary function as a FlagProvider.
+type FlagProviderFunc func(ctx context.Context, flag string) bool
+
+// Enabled calls f(ctx, flag).
+func (f FlagProviderFunc) Enabled(ctx context.Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+// StaticFlags is a FlagProvider with fixed values; flags missing from the map are enabled.
+type StaticFlags map[string]bool
+
+// Enabled returns the value of flag, or true when it is not set.
+func (f StaticFlags) Enabled(_ context.Context, flag string) bool {
+	enabled, ok := f[flag]
+
+	return !ok || enabled
+}
+
+// WithFeatureFlags makes the client resolve FlagRetry and FlagArtifactCapture from provider for every request.
+// Without a provider, every configured behavior is enabled. Request.RetryPolicy is applied regardless of FlagRetry.
+func WithFeatureFlags(provider FlagProvider) Option {
+	return func(c *client) {
+		c.flags = provider
+	}
+}
+
+// ToggleDo returns a DoFunc sending requests with enabled when flag is enabled for them, and with disabled otherwise,
+// for example to roll out a cache or an alternative retry layer gradually.
+func ToggleDo(provider FlagProvider, flag string, enabled DoFunc, disabled DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		if provider.Enabled(httpRequest.Context(), flag) {
+			return enabled(httpRequest)
+		}
+
+		return disabled(httpRequest)
+	}
+}
+
+// enabled reports whether flag is enabled for ctx; flags are enabled when no provider is configured.
+func (c *client) enabled(ctx context.Context, flag string) bool {
+	return c.flags == nil || c.flags.Enabled(ctx, flag)
+}
@@ -0,0 +1,121 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flagContextKey struct{}
+
+func TestStaticFlags_Enabled(t *testing.T) {
+	t.Parallel()
+
+	flags := StaticFlags{"on": true, "off": false}
+
+	tests := []struct {
+		name string
+		flag string
+		want bool
+	}{
+		{name: "success: enabled", flag: "on", want: true},
+		{name: "success: disabled", flag: "off", want: false},
+		{name: "success: missing flag is enabled", flag: "unknown", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, flags.Enabled(context.Background(), tt.flag))
+		})
+	}
+}
+
+func TestWithFeatureFlags(t *testing.T) {
+	t.Parallel()
+
+	// provider enables FlagRetry and FlagArtifactCapture only for contexts marked as canary.
+	provider := FlagProviderFunc(func(ctx context.Context, _ string) bool {
+		canary, _ := ctx.Value(flagContextKey{}).(bool)
+
+		return canary
+	})
+
+	newClient := func(calls *int, sink ArtifactSink) Client {
+		do := func(req *http.Request) (*http.Response, error) {
+			*calls++
+
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+
+		return NewClient("http://example.com", WithDoFunc(do), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+			WithArtifactSink(sink),
+			WithFeatureFlags(provider),
+		)
+	}
+
+	request := &Request{Method: http.MethodGet, Path: "/test", ExpectedStatusCodes: []int{http.StatusOK}}
+
+	t.Run("success: flags enabled", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		sink := &memoryArtifactSink{}
+		_, err := newClient(&calls, sink).Do(context.WithValue(context.Background(), flagContextKey{}, true), request, nil)
+		require.Error(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Len(t, sink.artifacts, 1)
+	})
+
+	t.Run("success: flags disabled", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		sink := &memoryArtifactSink{}
+		_, err := newClient(&calls, sink).Do(context.Background(), request, nil)
+		require.Error(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, sink.artifacts)
+	})
+}
+
+func TestToggleDo(t *testing.T) {
+	t.Parallel()
+
+	newDo := func(name string) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"X-Path": []string{name}},
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		}
+	}
+
+	tests := []struct {
+		name  string
+		flags StaticFlags
+		want  string
+	}{
+		{name: "success: enabled", flags: StaticFlags{"cache": true}, want: "enabled"},
+		{name: "success: disabled", flags: StaticFlags{"cache": false}, want: "disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com/test", nil)
+			require.NoError(t, err)
+
+			resp, err := ToggleDo(tt.flags, "cache", newDo("enabled"), newDo("disabled"))(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.Header.Get("X-Path"))
+		})
+	}
+}
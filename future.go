@@ -0,0 +1,45 @@
+package webapiclient
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Future is the result of a request executed asynchronously by Client.Go.
+type Future struct {
+	done     chan struct{}
+	response *Response
+	err      error
+}
+
+// Done returns a channel that is closed when the request completes.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits until the request completes and returns its result.
+// When ctx is done first, Wait returns the context error; the request keeps running and Wait may be called again.
+func (f *Future) Wait(ctx context.Context) (*Response, error) {
+	select {
+	case <-ctx.Done():
+		return nil, errors.WithStack(ctx.Err())
+	case <-f.done:
+		return f.response, f.err
+	}
+}
+
+// Go executes an HTTP request asynchronously and returns a Future for its result.
+func (c *client) Go(ctx context.Context, request *Request, edit EditRequestFunc) *Future {
+	future := &Future{
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(future.done)
+
+		future.response, future.err = c.Do(ctx, request, edit)
+	}()
+
+	return future
+}
@@ -0,0 +1,82 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientImpl_Go(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: fan-out and join", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(req.URL.Path))),
+			}, nil
+		}))
+
+		paths := []string{"/a", "/b", "/c"}
+		futures := make([]*Future, 0, len(paths))
+		for _, path := range paths {
+			futures = append(futures, client.Go(context.Background(), &Request{Method: http.MethodGet, Path: path}, nil))
+		}
+
+		for i, future := range futures {
+			<-future.Done()
+
+			got, err := future.Wait(context.Background())
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(got.Body)
+			require.NoError(t, err)
+			_ = got.Body.Close()
+			assert.Equal(t, []byte(paths[i]), body)
+		}
+	})
+
+	t.Run("failure: request error", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}))
+
+		future := client.Go(context.Background(), &Request{Method: http.MethodGet, Path: "/", ExpectedStatusCodes: []int{http.StatusOK}}, nil)
+
+		_, err := future.Wait(context.Background())
+		assert.Equal(t, CategoryHTTPStatus, ClassifyError(err))
+	})
+
+	t.Run("failure: wait context canceled", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			<-release
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}))
+
+		future := client.Go(context.Background(), &Request{Method: http.MethodGet, Path: "/"}, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := future.Wait(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		close(release)
+		got, err := future.Wait(context.Background())
+		require.NoError(t, err)
+		_ = got.Body.Close()
+	})
+}
@@ -0,0 +1,81 @@
+package webapiclient
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Pacer spaces calls to Wait at least an interval apart, giving a worker its own share of a rate budget.
+type Pacer struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewPacer creates a new Pacer allowing one call every interval.
+func NewPacer(interval time.Duration) *Pacer {
+	return &Pacer{
+		interval: interval,
+	}
+}
+
+// Wait blocks until the next slot of the pacer or until ctx is done.
+func (p *Pacer) Wait(ctx context.Context) error {
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next
+	if at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(p.interval)
+	p.mu.Unlock()
+
+	wait := at.Sub(now)
+	if wait <= 0 {
+		return errors.WithStack(ctx.Err())
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// SplitRate divides a budget of requestsPerSecond evenly among workers and returns one Pacer per worker,
+// for example one per errgroup worker sharing a client. It returns an error when requestsPerSecond is not
+// a positive number. Budgets too small to be represented space calls by the largest time.Duration.
+func SplitRate(requestsPerSecond float64, workers int) ([]*Pacer, error) {
+	if requestsPerSecond <= 0 || math.IsNaN(requestsPerSecond) {
+		return nil, errors.Errorf("requests per second must be positive: %v", requestsPerSecond)
+	}
+
+	workers = max(workers, 1)
+
+	interval := time.Duration(math.MaxInt64)
+	if seconds := float64(workers) / requestsPerSecond; seconds < float64(interval)/float64(time.Second) {
+		interval = time.Duration(seconds * float64(time.Second))
+	}
+
+	pacers := make([]*Pacer, workers)
+	for i := range pacers {
+		pacers[i] = NewPacer(interval)
+	}
+
+	return pacers, nil
+}
+
+// IsCanceled reports whether err was caused by the cancellation of the request context, such as
+// the cancellation of an errgroup context after another worker failed, rather than by the request itself.
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,137 @@
+package webapiclient
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacer_Wait(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: calls are spaced", func(t *testing.T) {
+		t.Parallel()
+
+		pacer := NewPacer(10 * time.Millisecond)
+
+		started := time.Now()
+		for range 4 {
+			require.NoError(t, pacer.Wait(context.Background()))
+		}
+		assert.GreaterOrEqual(t, time.Since(started), 30*time.Millisecond)
+	})
+
+	t.Run("failure: context canceled", func(t *testing.T) {
+		t.Parallel()
+
+		pacer := NewPacer(time.Hour)
+		require.NoError(t, pacer.Wait(context.Background()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := pacer.Wait(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestSplitRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		requestsPerSecond float64
+		workers           int
+		wantPacers        int
+		wantInterval      time.Duration
+		wantErr           bool
+	}{
+		{
+			name:              "success: evenly divided",
+			requestsPerSecond: 10,
+			workers:           4,
+			wantPacers:        4,
+			wantInterval:      400 * time.Millisecond,
+		},
+		{
+			name:              "success: at least one worker",
+			requestsPerSecond: 2,
+			workers:           0,
+			wantPacers:        1,
+			wantInterval:      500 * time.Millisecond,
+		},
+		{
+			name:              "success: tiny budget is clamped",
+			requestsPerSecond: math.SmallestNonzeroFloat64,
+			workers:           2,
+			wantPacers:        2,
+			wantInterval:      time.Duration(math.MaxInt64),
+		},
+		{
+			name:              "failure: zero budget",
+			requestsPerSecond: 0,
+			workers:           2,
+			wantErr:           true,
+		},
+		{
+			name:              "failure: negative budget",
+			requestsPerSecond: -1,
+			workers:           2,
+			wantErr:           true,
+		},
+		{
+			name:              "failure: NaN budget",
+			requestsPerSecond: math.NaN(),
+			workers:           2,
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pacers, err := SplitRate(tt.requestsPerSecond, tt.workers)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, pacers)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, pacers, tt.wantPacers)
+			for _, pacer := range pacers {
+				assert.Equal(t, tt.wantInterval, pacer.interval)
+			}
+		})
+	}
+}
+
+func TestIsCanceled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "success: canceled", err: errors.WithStack(newError(CategoryUnknown, context.Canceled)), want: true},
+		{name: "success: wrapped", err: fmt.Errorf("call: %w", context.Canceled), want: true},
+		{name: "success: deadline exceeded", err: context.DeadlineExceeded, want: false},
+		{name: "success: other error", err: errors.New("boom"), want: false},
+		{name: "success: nil", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, IsCanceled(tt.err))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DoJSON sends request with in, unless nil, as its JSON field, and decodes the JSON body of the
+// response into a TOut. Content-Type and Accept default to application/json; request is not modified.
+// Responses without a body, such as 204 No Content, decode to the zero TOut. The returned Response has
+// its body already read, and can be read again.
+func DoJSON[TIn any, TOut any](ctx context.Context, client Client, request *Request, in *TIn) (TOut, *Response, error) {
+	var out TOut
+
+	jsonRequest := *request
+	jsonRequest.Headers = maps.Clone(request.Headers)
+
+	if jsonRequest.Headers == nil {
+		jsonRequest.Headers = map[string][]string{}
+	}
+
+	if in != nil {
+		jsonRequest.JSON = in
+	}
+
+	setDefaultHeader(jsonRequest.Headers, "Accept", "application/json")
+
+	response, err := client.Do(ctx, &jsonRequest, nil)
+	if err != nil {
+		// The response is returned as is when the client was created with WithResponseOnError.
+		return out, response, errors.WithStack(err)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+
+	if err != nil {
+		return out, nil, errors.WithStack(classifyTransportError(err))
+	}
+
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return out, response, nil
+	}
+
+	err = json.Unmarshal(body, &out)
+	if err != nil {
+		return out, response, errors.WithStack(newDecodeError(response.StatusCode, body, err))
+	}
+
+	return out, response, nil
+}
+
+// setDefaultHeader sets the header key of headers to value unless it is set, whatever the case of its name.
+func setDefaultHeader(headers map[string][]string, key string, value string) {
+	for name := range headers {
+		if strings.EqualFold(name, key) {
+			return
+		}
+	}
+
+	headers[key] = []string{value}
+}
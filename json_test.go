@@ -0,0 +1,120 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonUser struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+func TestDoJSON(t *testing.T) {
+	t.Parallel()
+
+	type sent struct {
+		contentType string
+		accept      string
+		body        string
+	}
+
+	newDo := func(got *sent, status int, contentType string, body string) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			got.contentType = req.Header.Get("Content-Type")
+			got.accept = req.Header.Get("Accept")
+
+			if req.Body != nil {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				got.body = string(body)
+			}
+
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": {contentType}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}
+	}
+
+	t.Run("success: request and response bodies", func(t *testing.T) {
+		t.Parallel()
+
+		var got sent
+		client := NewClient("http://example.com", WithDoFunc(newDo(&got, http.StatusCreated, "application/json", `{"id":1,"name":"Jane"}`)))
+		request := &Request{Method: http.MethodPost, Path: "/users", ExpectedStatusCodes: []int{http.StatusCreated}}
+
+		user, response, err := DoJSON[jsonUser, jsonUser](context.Background(), client, request, &jsonUser{Name: "Jane"})
+		require.NoError(t, err)
+		assert.Equal(t, jsonUser{ID: 1, Name: "Jane"}, user)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Equal(t, sent{contentType: "application/json", accept: "application/json", body: `{"name":"Jane"}`}, got)
+		assert.Nil(t, request.Headers)
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":1,"name":"Jane"}`, string(body))
+	})
+
+	t.Run("success: headers of the request take precedence", func(t *testing.T) {
+		t.Parallel()
+
+		var got sent
+		client := NewClient("http://example.com", WithDoFunc(newDo(&got, http.StatusOK, "application/json", `[]`)))
+		request := &Request{
+			Method:  http.MethodPut,
+			Path:    "/users",
+			Headers: map[string][]string{"content-type": {"application/merge-patch+json"}, "accept": {"application/vnd.api+json"}},
+		}
+
+		users, _, err := DoJSON[[]jsonUser, []jsonUser](context.Background(), client, request, &[]jsonUser{{Name: "Jane"}})
+		require.NoError(t, err)
+		assert.Empty(t, users)
+		assert.Equal(t, sent{contentType: "application/merge-patch+json", accept: "application/vnd.api+json", body: `[{"name":"Jane"}]`}, got)
+	})
+
+	t.Run("success: no content", func(t *testing.T) {
+		t.Parallel()
+
+		var got sent
+		client := NewClient("http://example.com", WithDoFunc(newDo(&got, http.StatusNoContent, "", "")))
+
+		user, response, err := DoJSON[any, *jsonUser](context.Background(), client, &Request{Method: http.MethodDelete, Path: "/users/1"}, nil)
+		require.NoError(t, err)
+		assert.Nil(t, user)
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		assert.Empty(t, got.contentType)
+	})
+
+	t.Run("failure: invalid response body", func(t *testing.T) {
+		t.Parallel()
+
+		var got sent
+		client := NewClient("http://example.com", WithDoFunc(newDo(&got, http.StatusOK, "text/html", "<html>")))
+
+		_, response, err := DoJSON[any, jsonUser](context.Background(), client, &Request{Method: http.MethodGet, Path: "/users/1"}, nil)
+		require.Error(t, err)
+		assert.Equal(t, CategoryDecode, ClassifyError(err))
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("failure: unexpected status code", func(t *testing.T) {
+		t.Parallel()
+
+		var got sent
+		client := NewClient("http://example.com", WithDoFunc(newDo(&got, http.StatusNotFound, "application/json", `{}`)))
+		request := &Request{Method: http.MethodGet, Path: "/users/1", ExpectedStatusCodes: []int{http.StatusOK}}
+
+		_, response, err := DoJSON[any, jsonUser](context.Background(), client, request, nil)
+		require.Error(t, err)
+		assert.Equal(t, CategoryHTTPStatus, ClassifyError(err))
+		assert.Nil(t, response)
+	})
+}
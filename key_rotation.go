@@ -0,0 +1,115 @@
+package webapiclient
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// KeyRotationEvent is reported when a request rejected with one credential succeeded with the other.
+type KeyRotationEvent struct {
+	// Host is the host of the request.
+	Host string
+	// StatusCode is the status code returned for the rejected credential.
+	StatusCode int
+	// Secondary reports whether the secondary credential is now in use.
+	Secondary bool
+}
+
+// KeyRotation sets a credential header on every request and supports zero-downtime key rotation:
+// when the active credential is rejected with 401 or 403, the request is retried with the other one,
+// which becomes the active credential when it succeeds.
+type KeyRotation struct {
+	header   string
+	onRotate func(event KeyRotationEvent)
+
+	mu        sync.Mutex
+	keys      [2]string
+	secondary bool
+}
+
+// NewKeyRotation creates a new KeyRotation setting header to primary, falling back to secondary,
+// and calling onRotate, which may be nil, whenever the active credential changes.
+func NewKeyRotation(header string, primary string, secondary string, onRotate func(event KeyRotationEvent)) *KeyRotation {
+	return &KeyRotation{
+		header:   http.CanonicalHeaderKey(header),
+		onRotate: onRotate,
+		keys:     [2]string{primary, secondary},
+	}
+}
+
+// SetKeys replaces both credentials and makes the primary one active again.
+func (r *KeyRotation) SetKeys(primary string, secondary string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys = [2]string{primary, secondary}
+	r.secondary = false
+}
+
+// Wrap wraps do so that requests carry the active credential and fall back to the other one when rejected.
+func (r *KeyRotation) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		r.mu.Lock()
+		secondary, keys := r.secondary, r.keys
+		r.mu.Unlock()
+
+		active, other := keys[0], keys[1]
+		if secondary {
+			active, other = other, active
+		}
+
+		request := httpRequest.Clone(httpRequest.Context())
+		request.Header.Set(r.header, active)
+
+		httpResponse, err := do(request)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if !isCredentialRejected(httpResponse.StatusCode) || other == "" || !canRewind(httpRequest) {
+			return httpResponse, nil
+		}
+
+		retryRequest, err := rewindRequest(httpRequest)
+		if err != nil {
+			return httpResponse, nil //nolint:nilerr
+		}
+
+		_ = httpResponse.Body.Close()
+		retryRequest.Header.Set(r.header, other)
+
+		retryResponse, err := do(retryRequest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if !isCredentialRejected(retryResponse.StatusCode) {
+			r.rotate(secondary, KeyRotationEvent{
+				Host:       httpRequest.URL.Host,
+				StatusCode: httpResponse.StatusCode,
+				Secondary:  !secondary,
+			})
+		}
+
+		return retryResponse, nil
+	}
+}
+
+func (r *KeyRotation) rotate(from bool, event KeyRotationEvent) {
+	r.mu.Lock()
+	rotated := r.secondary == from
+	if rotated {
+		r.secondary = !from
+	}
+	r.mu.Unlock()
+
+	if rotated && r.onRotate != nil {
+		r.onRotate(event)
+	}
+}
+
+func isCredentialRejected(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
@@ -0,0 +1,116 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRotation_Wrap(t *testing.T) {
+	t.Parallel()
+
+	newDo := func(valid map[string]bool, sent *[]string) DoFunc {
+		var mu sync.Mutex
+
+		return func(req *http.Request) (*http.Response, error) {
+			key := req.Header.Get("X-Api-Key")
+
+			mu.Lock()
+			*sent = append(*sent, key)
+			mu.Unlock()
+
+			if req.Body != nil {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, []byte("payload"), body)
+			}
+
+			status := http.StatusUnauthorized
+			if valid[key] {
+				status = http.StatusOK
+			}
+
+			return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+	}
+
+	send := func(t *testing.T, client Client) int {
+		t.Helper()
+
+		response, err := client.Do(context.Background(), &Request{
+			Method: http.MethodPost,
+			Path:   "/test",
+			Body:   strings.NewReader("payload"),
+		}, nil)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+
+		return response.StatusCode
+	}
+
+	t.Run("success: primary accepted", func(t *testing.T) {
+		t.Parallel()
+
+		var sent []string
+		var events []KeyRotationEvent
+		rotation := NewKeyRotation("x-api-key", "primary", "secondary", func(event KeyRotationEvent) {
+			events = append(events, event)
+		})
+		client := NewClient("http://example.com", WithDoFunc(rotation.Wrap(newDo(map[string]bool{"primary": true}, &sent))))
+
+		assert.Equal(t, http.StatusOK, send(t, client))
+		assert.Equal(t, []string{"primary"}, sent)
+		assert.Empty(t, events)
+	})
+
+	t.Run("success: falls back to secondary and keeps using it", func(t *testing.T) {
+		t.Parallel()
+
+		var sent []string
+		var events []KeyRotationEvent
+		rotation := NewKeyRotation("X-Api-Key", "primary", "secondary", func(event KeyRotationEvent) {
+			events = append(events, event)
+		})
+		client := NewClient("http://example.com", WithDoFunc(rotation.Wrap(newDo(map[string]bool{"secondary": true}, &sent))))
+
+		assert.Equal(t, http.StatusOK, send(t, client))
+		assert.Equal(t, http.StatusOK, send(t, client))
+		assert.Equal(t, []string{"primary", "secondary", "secondary"}, sent)
+		assert.Equal(t, []KeyRotationEvent{{Host: "example.com", StatusCode: http.StatusUnauthorized, Secondary: true}}, events)
+	})
+
+	t.Run("success: both rejected", func(t *testing.T) {
+		t.Parallel()
+
+		var sent []string
+		var events []KeyRotationEvent
+		rotation := NewKeyRotation("X-Api-Key", "primary", "secondary", func(event KeyRotationEvent) {
+			events = append(events, event)
+		})
+		client := NewClient("http://example.com", WithDoFunc(rotation.Wrap(newDo(map[string]bool{}, &sent))))
+
+		assert.Equal(t, http.StatusUnauthorized, send(t, client))
+		assert.Equal(t, []string{"primary", "secondary"}, sent)
+		assert.Empty(t, events)
+	})
+
+	t.Run("success: SetKeys restores primary", func(t *testing.T) {
+		t.Parallel()
+
+		var sent []string
+		rotation := NewKeyRotation("X-Api-Key", "old", "new", nil)
+		client := NewClient("http://example.com", WithDoFunc(rotation.Wrap(newDo(map[string]bool{"new": true}, &sent))))
+
+		assert.Equal(t, http.StatusOK, send(t, client))
+		rotation.SetKeys("new", "")
+		assert.Equal(t, http.StatusOK, send(t, client))
+		assert.Equal(t, []string{"old", "new", "new"}, sent)
+	})
+}
@@ -0,0 +1,140 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// errNoFirstByte is the cause of attempts aborted by LatencyBudget.
+var errNoFirstByte = errors.New("no first response byte within the latency budget")
+
+// latencyBudgetContextKey is the context key of the budget attached with WithLatencyBudget.
+type latencyBudgetContextKey struct{}
+
+// WithLatencyBudget returns a copy of ctx whose requests use budget instead of the default budget of LatencyBudget.
+func WithLatencyBudget(ctx context.Context, budget time.Duration) context.Context {
+	return context.WithValue(ctx, latencyBudgetContextKey{}, budget)
+}
+
+// LatencyBudget aborts attempts that consumed a fraction of their latency budget without receiving
+// the first response byte, and retries them immediately instead of waiting for the full timeout.
+// Only idempotent requests with a rewindable body are aborted.
+type LatencyBudget struct {
+	budget     time.Duration
+	fraction   float64
+	maxRetries int
+}
+
+// NewLatencyBudget creates a new LatencyBudget aborting attempts without a first byte after fraction of budget,
+// up to maxRetries times; the last attempt is never aborted.
+func NewLatencyBudget(budget time.Duration, fraction float64, maxRetries int) *LatencyBudget {
+	return &LatencyBudget{
+		budget:     budget,
+		fraction:   fraction,
+		maxRetries: maxRetries,
+	}
+}
+
+// Wrap wraps do so that slow attempts are aborted early and retried.
+func (b *LatencyBudget) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		budget, ok := httpRequest.Context().Value(latencyBudgetContextKey{}).(time.Duration)
+		if !ok {
+			budget = b.budget
+		}
+
+		threshold := time.Duration(float64(budget) * b.fraction)
+		if threshold <= 0 || !isIdempotent(httpRequest) || !canRewind(httpRequest) {
+			return do(httpRequest)
+		}
+
+		for attempt := 0; ; attempt++ {
+			request := httpRequest
+			if attempt > 0 {
+				var err error
+
+				request, err = rewindRequest(httpRequest)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
+			}
+
+			if attempt >= b.maxRetries {
+				return do(request)
+			}
+
+			httpResponse, err := sendWithFirstByteDeadline(do, request, threshold)
+			if errors.Is(err, errNoFirstByte) && httpRequest.Context().Err() == nil {
+				continue
+			}
+
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			return httpResponse, nil
+		}
+	}
+}
+
+// sendWithFirstByteDeadline sends httpRequest and cancels it with errNoFirstByte when no response byte
+// was received within threshold.
+func sendWithFirstByteDeadline(do DoFunc, httpRequest *http.Request, threshold time.Duration) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(httpRequest.Context())
+
+	var firstByte atomic.Bool
+
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			firstByte.Store(true)
+		},
+	})
+
+	timer := time.AfterFunc(threshold, func() {
+		if !firstByte.Load() {
+			cancel(errNoFirstByte)
+		}
+	})
+
+	httpResponse, err := do(httpRequest.WithContext(ctx))
+	timer.Stop()
+
+	if errors.Is(context.Cause(ctx), errNoFirstByte) {
+		if err == nil {
+			_ = httpResponse.Body.Close()
+		}
+
+		cancel(nil)
+
+		return nil, errors.WithStack(errNoFirstByte)
+	}
+
+	if err != nil {
+		cancel(nil)
+
+		return nil, errors.WithStack(err)
+	}
+
+	httpResponse.Body = &cancelOnCloseBody{ReadCloser: httpResponse.Body, cancel: cancel}
+
+	return httpResponse, nil
+}
+
+// cancelOnCloseBody releases the context of an attempt when its response body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+
+	cancel context.CancelCauseFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel(nil)
+
+	return errors.WithStack(b.ReadCloser.Close())
+}
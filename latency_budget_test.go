@@ -0,0 +1,111 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyBudget_Wrap(t *testing.T) {
+	t.Parallel()
+
+	// newDo returns a DoFunc whose attempts listed in slow never respond before their context is done.
+	newDo := func(calls *atomic.Int32, slow map[int32]bool) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			call := calls.Add(1)
+			if slow[call] {
+				<-req.Context().Done()
+
+				return nil, req.Context().Err()
+			}
+
+			if req.Body != nil {
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				if string(body) != "payload" {
+					return nil, io.ErrUnexpectedEOF
+				}
+			}
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte("ok")))}, nil
+		}
+	}
+
+	send := func(ctx context.Context, do DoFunc, method string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, "http://example.com/test", strings.NewReader("payload"))
+		if err != nil {
+			return nil, err
+		}
+
+		return do(req)
+	}
+
+	t.Run("success: slow attempt is retried", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		do := NewLatencyBudget(50*time.Millisecond, 0.4, 2).Wrap(newDo(&calls, map[int32]bool{1: true}))
+
+		started := time.Now()
+		resp, err := send(context.Background(), do, http.MethodPut)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Less(t, time.Since(started), time.Second)
+	})
+
+	t.Run("success: budget from context", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		do := NewLatencyBudget(time.Hour, 0.5, 2).Wrap(newDo(&calls, map[int32]bool{1: true}))
+
+		resp, err := send(WithLatencyBudget(context.Background(), 20*time.Millisecond), do, http.MethodGet)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("failure: last attempt is not aborted", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		do := NewLatencyBudget(20*time.Millisecond, 0.5, 1).Wrap(newDo(&calls, map[int32]bool{1: true, 2: true}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := send(ctx, do, http.MethodGet)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("failure: non-idempotent requests are not aborted", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		do := NewLatencyBudget(20*time.Millisecond, 0.5, 3).Wrap(newDo(&calls, map[int32]bool{1: true}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := send(ctx, do, http.MethodPost)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
@@ -0,0 +1,175 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Compile-time check to ensure ManifestReader implements io.ReadCloser interface.
+var _ io.ReadCloser = (*ManifestReader)(nil)
+
+// ManifestPart is a part of a payload delivered as a manifest of chunk endpoints.
+type ManifestPart struct {
+	// Path is the path of the part endpoint, resolved against the base URL of the client.
+	Path string
+	// Size is the expected size of the part in bytes, or zero when unknown.
+	Size int64
+	// SHA256 is the expected hex encoded SHA-256 digest of the part, or empty when unknown.
+	SHA256 string
+}
+
+// ManifestReader reads the parts of a manifest as a single contiguous stream, fetching parts concurrently.
+type ManifestReader struct {
+	ctx     context.Context //nolint:containedctx
+	cancel  context.CancelFunc
+	results []chan manifestPartResult
+	window  chan struct{}
+	index   int
+	current *bytes.Reader
+	err     error
+}
+
+type manifestPartResult struct {
+	body []byte
+	err  error
+}
+
+// NewManifestReader creates a new ManifestReader fetching parts with client, at most concurrency at a time.
+// Every part is verified against its expected size and digest before it is read.
+// The reader must be closed to stop fetching when it is not read to the end.
+func NewManifestReader(ctx context.Context, client Client, parts []ManifestPart, concurrency int) *ManifestReader {
+	ctx, cancel := context.WithCancel(ctx)
+
+	r := &ManifestReader{
+		ctx:     ctx,
+		cancel:  cancel,
+		results: make([]chan manifestPartResult, len(parts)),
+		window:  make(chan struct{}, max(concurrency, 1)),
+	}
+
+	for i := range parts {
+		r.results[i] = make(chan manifestPartResult, 1)
+	}
+
+	go r.fetchAll(client, parts)
+
+	return r
+}
+
+// Read reads the next bytes of the payload.
+func (r *ManifestReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if r.current == nil {
+			r.next()
+
+			continue
+		}
+
+		n, _ := r.current.Read(p)
+		if r.current.Len() == 0 {
+			r.current = nil
+			r.index++
+			<-r.window
+		}
+
+		if n > 0 || len(p) == 0 {
+			return n, nil
+		}
+	}
+
+	return 0, r.err
+}
+
+// Close stops fetching the remaining parts.
+func (r *ManifestReader) Close() error {
+	r.cancel()
+
+	return nil
+}
+
+func (r *ManifestReader) next() {
+	if r.index >= len(r.results) {
+		r.err = io.EOF
+
+		return
+	}
+
+	select {
+	case result := <-r.results[r.index]:
+		if result.err != nil {
+			r.err = errors.WithStack(result.err)
+
+			return
+		}
+
+		r.current = bytes.NewReader(result.body)
+	case <-r.ctx.Done():
+		r.err = errors.WithStack(r.ctx.Err())
+	}
+}
+
+// fetchAll fetches the parts in order, keeping at most cap(r.window) parts fetched but not yet read.
+func (r *ManifestReader) fetchAll(client Client, parts []ManifestPart) {
+	for i, part := range parts {
+		select {
+		case r.window <- struct{}{}:
+		case <-r.ctx.Done():
+			return
+		}
+
+		go func() {
+			body, err := fetchManifestPart(r.ctx, client, part)
+			if err != nil {
+				err = errors.Wrapf(err, "part %d", i)
+			}
+
+			r.results[i] <- manifestPartResult{body: body, err: err}
+		}()
+	}
+}
+
+func fetchManifestPart(ctx context.Context, client Client, part ManifestPart) ([]byte, error) {
+	response, err := client.Do(ctx, &Request{
+		Method:              http.MethodGet,
+		Path:                part.Path,
+		ExpectedStatusCodes: []int{http.StatusOK},
+	}, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.WithStack(classifyTransportError(err))
+	}
+
+	if part.Size > 0 && int64(len(body)) != part.Size {
+		return nil, errors.WithStack(newError(
+			CategoryDecode,
+			errors.Errorf("size mismatch: expected %d, got %d", part.Size, len(body)),
+		))
+	}
+
+	if part.SHA256 != "" {
+		digest := sha256.Sum256(body)
+		if actual := hex.EncodeToString(digest[:]); !strings.EqualFold(actual, part.SHA256) {
+			return nil, errors.WithStack(newError(
+				CategoryDecode,
+				errors.Errorf("sha256 mismatch: expected %s, got %s", part.SHA256, actual),
+			))
+		}
+	}
+
+	return body, nil
+}
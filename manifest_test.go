@@ -0,0 +1,136 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestReader_Read(t *testing.T) {
+	t.Parallel()
+
+	chunks := map[string]string{
+		"/parts/0": strings.Repeat("a", 100),
+		"/parts/1": "",
+		"/parts/2": strings.Repeat("b", 50),
+		"/parts/3": "tail",
+	}
+
+	digest := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+
+		return hex.EncodeToString(sum[:])
+	}
+
+	newClient := func(active, maxActive *atomic.Int32) Client {
+		return NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				current := maxActive.Load()
+				if n <= current || maxActive.CompareAndSwap(current, n) {
+					break
+				}
+			}
+
+			body, ok := chunks[req.URL.Path]
+			if !ok {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+			}
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}))
+	}
+
+	parts := []ManifestPart{
+		{Path: "/parts/0", Size: 100, SHA256: digest(chunks["/parts/0"])},
+		{Path: "/parts/1"},
+		{Path: "/parts/2", SHA256: strings.ToUpper(digest(chunks["/parts/2"]))},
+		{Path: "/parts/3"},
+	}
+
+	t.Run("success: contiguous payload", func(t *testing.T) {
+		t.Parallel()
+
+		var active, maxActive atomic.Int32
+		reader := NewManifestReader(context.Background(), newClient(&active, &maxActive), parts, 2)
+		defer func() {
+			_ = reader.Close()
+		}()
+
+		got, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, chunks["/parts/0"]+chunks["/parts/2"]+chunks["/parts/3"], string(got))
+		assert.LessOrEqual(t, maxActive.Load(), int32(2))
+	})
+
+	t.Run("success: no parts", func(t *testing.T) {
+		t.Parallel()
+
+		var active, maxActive atomic.Int32
+		got, err := io.ReadAll(NewManifestReader(context.Background(), newClient(&active, &maxActive), nil, 2))
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("failure: checksum mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		var active, maxActive atomic.Int32
+		reader := NewManifestReader(context.Background(), newClient(&active, &maxActive), []ManifestPart{
+			{Path: "/parts/0"},
+			{Path: "/parts/3", SHA256: digest("other")},
+		}, 2)
+		defer func() {
+			_ = reader.Close()
+		}()
+
+		got, err := io.ReadAll(reader)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "part 1: sha256 mismatch")
+		assert.Equal(t, CategoryDecode, ClassifyError(err))
+		assert.Equal(t, chunks["/parts/0"], string(got))
+	})
+
+	t.Run("failure: size mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		var active, maxActive atomic.Int32
+		reader := NewManifestReader(context.Background(), newClient(&active, &maxActive), []ManifestPart{{Path: "/parts/3", Size: 5}}, 1)
+
+		_, err := io.ReadAll(reader)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "size mismatch: expected 5, got 4")
+	})
+
+	t.Run("failure: part not found", func(t *testing.T) {
+		t.Parallel()
+
+		var active, maxActive atomic.Int32
+		reader := NewManifestReader(context.Background(), newClient(&active, &maxActive), []ManifestPart{{Path: "/parts/9"}}, 1)
+
+		_, err := io.ReadAll(reader)
+		require.Error(t, err)
+		assert.Equal(t, CategoryHTTPStatus, ClassifyError(err))
+	})
+
+	t.Run("failure: closed", func(t *testing.T) {
+		t.Parallel()
+
+		var active, maxActive atomic.Int32
+		reader := NewManifestReader(context.Background(), newClient(&active, &maxActive), parts, 1)
+		require.NoError(t, reader.Close())
+
+		_, err := io.ReadAll(reader)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
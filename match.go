@@ -0,0 +1,193 @@
+package webapiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MatchOption configures MatchRequest.
+type MatchOption func(options *matchOptions)
+
+type matchOptions struct {
+	ignoredHeaders map[string]bool
+	ignoredQuery   map[string]bool
+	normalizeJSON  bool
+}
+
+// IgnoreHeaders makes MatchRequest ignore volatile headers such as Date or X-Request-Id.
+func IgnoreHeaders(keys ...string) MatchOption {
+	return func(options *matchOptions) {
+		for _, key := range keys {
+			options.ignoredHeaders[http.CanonicalHeaderKey(key)] = true
+		}
+	}
+}
+
+// IgnoreQueryParams makes MatchRequest ignore volatile query parameters such as timestamps or nonces.
+func IgnoreQueryParams(keys ...string) MatchOption {
+	return func(options *matchOptions) {
+		for _, key := range keys {
+			options.ignoredQuery[key] = true
+		}
+	}
+}
+
+// NormalizeJSONBody makes MatchRequest compare bodies that are both valid JSON by value,
+// ignoring formatting and key order.
+func NormalizeJSONBody() MatchOption {
+	return func(options *matchOptions) {
+		options.normalizeJSON = true
+	}
+}
+
+// MismatchError lists the differences found by MatchRequest.
+type MismatchError struct {
+	Reasons []string
+}
+
+// Error returns every difference found.
+func (e *MismatchError) Error() string {
+	return "request mismatch: " + strings.Join(e.Reasons, "; ")
+}
+
+// MatchRequest compares actual against expected and returns a *MismatchError describing the differences,
+// or nil when they match. Method, URL without the query, query parameters and body are compared exactly;
+// headers of expected must be present in actual with the same values, while extra headers in actual are allowed.
+// Request bodies are restored so that both requests can still be sent or inspected.
+func MatchRequest(expected *http.Request, actual *http.Request, opts ...MatchOption) error {
+	options := &matchOptions{
+		ignoredHeaders: map[string]bool{},
+		ignoredQuery:   map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var reasons []string
+
+	if expected.Method != actual.Method {
+		reasons = append(reasons, fmt.Sprintf("method: want %s, got %s", expected.Method, actual.Method))
+	}
+
+	expectedURL, actualURL := *expected.URL, *actual.URL
+	expectedURL.RawQuery, actualURL.RawQuery = "", ""
+
+	if expectedURL.String() != actualURL.String() {
+		reasons = append(reasons, fmt.Sprintf("url: want %s, got %s", expectedURL.String(), actualURL.String()))
+	}
+
+	reasons = append(reasons, matchQuery(expected, actual, options)...)
+	reasons = append(reasons, matchHeaders(expected, actual, options)...)
+
+	reason, err := matchBody(expected, actual, options)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if reason != "" {
+		reasons = append(reasons, reason)
+	}
+
+	if len(reasons) > 0 {
+		return &MismatchError{Reasons: reasons}
+	}
+
+	return nil
+}
+
+func matchQuery(expected *http.Request, actual *http.Request, options *matchOptions) []string {
+	expectedQuery, actualQuery := expected.URL.Query(), actual.URL.Query()
+	keys := slices.Sorted(maps.Keys(expectedQuery))
+	for _, key := range slices.Sorted(maps.Keys(actualQuery)) {
+		if _, ok := expectedQuery[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	var reasons []string
+
+	for _, key := range keys {
+		if options.ignoredQuery[key] || slices.Equal(expectedQuery[key], actualQuery[key]) {
+			continue
+		}
+
+		reasons = append(reasons, fmt.Sprintf("query %s: want %v, got %v", key, expectedQuery[key], actualQuery[key]))
+	}
+
+	return reasons
+}
+
+func matchHeaders(expected *http.Request, actual *http.Request, options *matchOptions) []string {
+	var reasons []string
+
+	for _, key := range slices.Sorted(maps.Keys(expected.Header)) {
+		canonicalKey := http.CanonicalHeaderKey(key)
+		if options.ignoredHeaders[canonicalKey] {
+			continue
+		}
+
+		want, got := expected.Header.Values(canonicalKey), actual.Header.Values(canonicalKey)
+		if !slices.Equal(want, got) {
+			reasons = append(reasons, fmt.Sprintf("header %s: want %v, got %v", canonicalKey, want, got))
+		}
+	}
+
+	return reasons
+}
+
+func matchBody(expected *http.Request, actual *http.Request, options *matchOptions) (string, error) {
+	expectedBody, err := readBodyPreserving(expected)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	actualBody, err := readBodyPreserving(actual)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if bytes.Equal(expectedBody, actualBody) {
+		return "", nil
+	}
+
+	if options.normalizeJSON {
+		var expectedValue, actualValue any
+
+		if json.Unmarshal(expectedBody, &expectedValue) == nil && json.Unmarshal(actualBody, &actualValue) == nil &&
+			reflect.DeepEqual(expectedValue, actualValue) {
+			return "", nil
+		}
+	}
+
+	return fmt.Sprintf("body: want %q, got %q", expectedBody, actualBody), nil
+}
+
+// readBodyPreserving reads the body of httpRequest and replaces it with an identical unread body.
+func readBodyPreserving(httpRequest *http.Request) ([]byte, error) {
+	if httpRequest.Body == nil || httpRequest.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(httpRequest.Body)
+	_ = httpRequest.Body.Close()
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	httpRequest.Body = io.NopCloser(bytes.NewReader(body))
+	httpRequest.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return body, nil
+}
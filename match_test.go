@@ -0,0 +1,116 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRequest(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(method string, url string, headers http.Header, body string) *http.Request {
+		var reader io.Reader
+		if body != "" {
+			reader = strings.NewReader(body)
+		}
+
+		req, err := http.NewRequestWithContext(context.Background(), method, url, reader)
+		require.NoError(t, err)
+		for key, values := range headers {
+			req.Header[key] = values
+		}
+		return req
+	}
+
+	type args struct {
+		expected *http.Request
+		actual   *http.Request
+		opts     []MatchOption
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "success: identical requests",
+			args: args{
+				expected: newRequest(http.MethodPost, "http://example.com/a?x=1", http.Header{"Content-Type": {"application/json"}}, `{"a":1}`),
+				actual:   newRequest(http.MethodPost, "http://example.com/a?x=1", http.Header{"Content-Type": {"application/json"}}, `{"a":1}`),
+			},
+		},
+		{
+			name: "success: extra actual headers and query order",
+			args: args{
+				expected: newRequest(http.MethodGet, "http://example.com/a?x=1&y=2", nil, ""),
+				actual:   newRequest(http.MethodGet, "http://example.com/a?y=2&x=1", http.Header{"User-Agent": {"test"}}, ""),
+			},
+		},
+		{
+			name: "success: ignored volatile headers and query parameters",
+			args: args{
+				expected: newRequest(http.MethodGet, "http://example.com/a?ts=1", http.Header{"X-Request-Id": {"a"}}, ""),
+				actual:   newRequest(http.MethodGet, "http://example.com/a?ts=2", http.Header{"X-Request-Id": {"b"}}, ""),
+				opts:     []MatchOption{IgnoreHeaders("x-request-id"), IgnoreQueryParams("ts")},
+			},
+		},
+		{
+			name: "success: normalized JSON body",
+			args: args{
+				expected: newRequest(http.MethodPost, "http://example.com/a", nil, `{"a":1,"b":[1,2]}`),
+				actual:   newRequest(http.MethodPost, "http://example.com/a", nil, "{\n  \"b\": [1, 2],\n  \"a\": 1\n}"),
+				opts:     []MatchOption{NormalizeJSONBody()},
+			},
+		},
+		{
+			name: "failure: every difference is reported",
+			args: args{
+				expected: newRequest(http.MethodPost, "http://example.com/a?x=1", http.Header{"Accept": {"application/json"}}, `{"a":1}`),
+				actual:   newRequest(http.MethodPut, "http://example.com/b?x=2&y=3", http.Header{"Accept": {"text/plain"}}, `{"a":2}`),
+				opts:     []MatchOption{NormalizeJSONBody()},
+			},
+			want: []string{
+				"method: want POST, got PUT",
+				"url: want http://example.com/a, got http://example.com/b",
+				"query x: want [1], got [2]",
+				"query y: want [], got [3]",
+				"header Accept: want [application/json], got [text/plain]",
+				`body: want "{\"a\":1}", got "{\"a\":2}"`,
+			},
+		},
+		{
+			name: "failure: JSON bodies differ without normalization",
+			args: args{
+				expected: newRequest(http.MethodPost, "http://example.com/a", nil, `{"a":1}`),
+				actual:   newRequest(http.MethodPost, "http://example.com/a", nil, `{ "a": 1 }`),
+			},
+			want: []string{`body: want "{\"a\":1}", got "{ \"a\": 1 }"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := MatchRequest(tt.args.expected, tt.args.actual, tt.args.opts...)
+			if tt.want == nil {
+				require.NoError(t, err)
+			} else {
+				var mismatchError *MismatchError
+				require.ErrorAs(t, err, &mismatchError)
+				assert.Equal(t, tt.want, mismatchError.Reasons)
+			}
+
+			if tt.args.actual.Body != nil {
+				body, err := io.ReadAll(tt.args.actual.Body)
+				require.NoError(t, err)
+				assert.NotEmpty(t, body)
+			}
+		})
+	}
+}
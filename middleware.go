@@ -0,0 +1,25 @@
+package webapiclient
+
+// Middleware wraps a DoFunc with a cross-cutting concern such as logging, authentication, metrics or retries.
+// The Wrap methods of components such as CircuitBreaker and KeyRotation are middlewares.
+// Like http.RoundTripper, a middleware must not modify the request it receives; it clones it instead.
+type Middleware func(next DoFunc) DoFunc
+
+// Chain composes middlewares into one, the first being the outermost.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next DoFunc) DoFunc {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+
+		return next
+	}
+}
+
+// Use adds middlewares around the DoFunc of the client. Middlewares run in the order they were added,
+// the first one being the outermost; they wrap every attempt of a request, including retries.
+// Use must not be called concurrently with requests.
+func (c *client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	c.buildChain()
+}
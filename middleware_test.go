@@ -0,0 +1,73 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	var trace []string
+	record := func(name string) Middleware {
+		return func(next DoFunc) DoFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				trace = append(trace, name+" before")
+				resp, err := next(req)
+				trace = append(trace, name+" after")
+
+				return resp, err
+			}
+		}
+	}
+
+	do := Chain(record("a"), record("b"))(func(req *http.Request) (*http.Response, error) {
+		trace = append(trace, "do")
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com/test", nil)
+	require.NoError(t, err)
+
+	_, err = do(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a before", "b before", "do", "b after", "a after"}, trace)
+}
+
+func TestClientImpl_Use(t *testing.T) {
+	t.Parallel()
+
+	header := func(value string) Middleware {
+		return func(next DoFunc) DoFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				req.Header.Add("X-Trace", value)
+
+				return next(req)
+			}
+		}
+	}
+
+	var traces [][]string
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		traces = append(traces, req.Header.Values("X-Trace"))
+
+		return &http.Response{StatusCode: statuses[len(traces)-1], Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 2}))
+
+	client.Use(header("a"), header("b"))
+	client.Use(header("c"))
+
+	response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"a", "b", "c"}}, traces)
+}
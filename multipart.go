@@ -0,0 +1,92 @@
+package webapiclient
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Part is a single part of a multipart response.
+type Part struct {
+	Headers map[string][]string
+	Body    io.Reader
+}
+
+// MultipartReader streams the parts of a multipart/mixed, multipart/related or other multipart response.
+type MultipartReader struct {
+	reader *multipart.Reader
+}
+
+// NewMultipartReader creates a MultipartReader over the body of a multipart response.
+func NewMultipartReader(response *Response) (*MultipartReader, error) {
+	contentType := getHeader(response.Headers, "Content-Type")
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, errors.WithStack(newError(CategoryDecode, err))
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, errors.WithStack(newError(CategoryDecode, errors.Errorf("not a multipart response: %s", contentType)))
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.WithStack(newError(CategoryDecode, errors.New("multipart boundary is missing")))
+	}
+
+	return &MultipartReader{
+		reader: multipart.NewReader(response.Body, boundary),
+	}, nil
+}
+
+// NextPart returns the next part, or io.EOF when there are no more parts.
+// The body of the previous part is discarded.
+func (r *MultipartReader) NextPart() (*Part, error) {
+	part, err := r.reader.NextPart()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+
+	if err != nil {
+		return nil, errors.WithStack(newError(CategoryDecode, err))
+	}
+
+	return &Part{
+		Headers: part.Header,
+		Body:    part,
+	}, nil
+}
+
+// EachPart calls fn for every part of a multipart response in order.
+func EachPart(response *Response, fn func(part *Part) error) error {
+	reader, err := NewMultipartReader(response)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		err = fn(part)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
+
+// getHeader returns the first value associated with the canonical form of key.
+func getHeader(headers map[string][]string, key string) string {
+	return http.Header(headers).Get(key)
+}
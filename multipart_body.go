@@ -0,0 +1,141 @@
+package webapiclient
+
+import (
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// MultipartBody builds a multipart/form-data request body of fields and files that is streamed
+// into the request as it is sent, without being buffered in memory.
+// The body can be sent only once, so requests carrying it are not retried.
+type MultipartBody struct {
+	boundary string
+	parts    []func(writer *multipart.Writer) error
+}
+
+// NewMultipartBody creates a new empty MultipartBody with a random boundary.
+func NewMultipartBody() *MultipartBody {
+	return &MultipartBody{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+// AddField adds a form field.
+func (b *MultipartBody) AddField(name string, value string) *MultipartBody {
+	b.parts = append(b.parts, func(writer *multipart.Writer) error {
+		return writer.WriteField(name, value) //nolint:wrapcheck
+	})
+
+	return b
+}
+
+// AddFile adds a file named fileName with the content read from r, which is closed after it is
+// streamed when it is an io.Closer.
+func (b *MultipartBody) AddFile(name string, fileName string, r io.Reader) *MultipartBody {
+	b.parts = append(b.parts, func(writer *multipart.Writer) error {
+		if closer, ok := r.(io.Closer); ok {
+			defer func() {
+				_ = closer.Close()
+			}()
+		}
+
+		part, err := writer.CreateFormFile(name, fileName)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		_, err = io.Copy(part, r)
+
+		return errors.WithStack(err)
+	})
+
+	return b
+}
+
+// AddFilePath adds the file at path, which is opened when the body is streamed.
+func (b *MultipartBody) AddFilePath(name string, path string) *MultipartBody {
+	b.parts = append(b.parts, func(writer *multipart.Writer) error {
+		file, err := os.Open(path) //nolint:gosec
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		part, err := writer.CreateFormFile(name, filepath.Base(path))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		_, err = io.Copy(part, file)
+
+		return errors.WithStack(err)
+	})
+
+	return b
+}
+
+// ContentType returns the multipart/form-data content type with the boundary of the body.
+func (b *MultipartBody) ContentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// newReader returns a reader streaming the body. The parts are written by a goroutine started on the first read,
+// so that nothing is left running when the body is never sent.
+func (b *MultipartBody) newReader() io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+
+	return &multipartBodyReader{
+		body:       b,
+		pipeReader: pipeReader,
+		pipeWriter: pipeWriter,
+	}
+}
+
+type multipartBodyReader struct {
+	body       *MultipartBody
+	once       sync.Once
+	pipeReader *io.PipeReader
+	pipeWriter *io.PipeWriter
+}
+
+func (r *multipartBodyReader) Read(p []byte) (int, error) {
+	r.once.Do(func() {
+		go r.write()
+	})
+
+	return r.pipeReader.Read(p) //nolint:wrapcheck
+}
+
+// Close stops the streaming of the body, making the goroutine writing it return.
+func (r *multipartBodyReader) Close() error {
+	return r.pipeReader.Close() //nolint:wrapcheck
+}
+
+func (r *multipartBodyReader) write() {
+	writer := multipart.NewWriter(r.pipeWriter)
+
+	err := writer.SetBoundary(r.body.boundary)
+	if err != nil {
+		r.pipeWriter.CloseWithError(err)
+
+		return
+	}
+
+	for _, part := range r.body.parts {
+		err = part(writer)
+		if err != nil {
+			r.pipeWriter.CloseWithError(err)
+
+			return
+		}
+	}
+
+	r.pipeWriter.CloseWithError(writer.Close())
+}
@@ -0,0 +1,120 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartBody(t *testing.T) {
+	t.Parallel()
+
+	type part struct {
+		name     string
+		fileName string
+		body     string
+	}
+
+	newDo := func(got *[]part) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			defer func() {
+				_ = req.Body.Close()
+			}()
+
+			mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			if err != nil {
+				return nil, err
+			}
+
+			if mediaType != "multipart/form-data" {
+				return nil, io.ErrUnexpectedEOF
+			}
+
+			reader := multipart.NewReader(req.Body, params["boundary"])
+			for {
+				p, err := reader.NextPart()
+				if err == io.EOF {
+					break
+				}
+
+				if err != nil {
+					return nil, err
+				}
+
+				body, err := io.ReadAll(p)
+				if err != nil {
+					return nil, err
+				}
+
+				*got = append(*got, part{name: p.FormName(), fileName: p.FileName(), body: string(body)})
+			}
+
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}
+	}
+
+	t.Run("success: fields and files", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "report.csv")
+		require.NoError(t, os.WriteFile(path, []byte("a,b\n1,2\n"), 0o600))
+
+		var got []part
+		client := NewClient("http://example.com", WithDoFunc(newDo(&got)))
+
+		_, err := client.Do(context.Background(), &Request{
+			Method: http.MethodPost,
+			Path:   "/upload",
+			Multipart: NewMultipartBody().
+				AddField("title", "Q1").
+				AddFile("attachment", "notes.txt", strings.NewReader("hello")).
+				AddFilePath("report", path),
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []part{
+			{name: "title", body: "Q1"},
+			{name: "attachment", fileName: "notes.txt", body: "hello"},
+			{name: "report", fileName: "report.csv", body: "a,b\n1,2\n"},
+		}, got)
+	})
+
+	t.Run("success: content type", func(t *testing.T) {
+		t.Parallel()
+
+		body := NewMultipartBody()
+
+		got, err := NewClient("http://example.com").DryRun(context.Background(), &Request{
+			Method:    http.MethodPost,
+			Path:      "/upload",
+			Multipart: body,
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, body.ContentType(), got.Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(body.ContentType(), "multipart/form-data; boundary="))
+		require.NoError(t, got.Body.Close())
+	})
+
+	t.Run("failure: missing file", func(t *testing.T) {
+		t.Parallel()
+
+		var got []part
+		client := NewClient("http://example.com", WithDoFunc(newDo(&got)))
+
+		_, err := client.Do(context.Background(), &Request{
+			Method:    http.MethodPost,
+			Path:      "/upload",
+			Multipart: NewMultipartBody().AddFilePath("report", filepath.Join(t.TempDir(), "missing.csv")),
+		}, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,118 @@
+package webapiclient
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMultipartResponse(t *testing.T, mediaType string) *Response {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}})
+	require.NoError(t, err)
+	_, err = part.Write([]byte(`{"id":1}`))
+	require.NoError(t, err)
+
+	part, err = writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/octet-stream"}})
+	require.NoError(t, err)
+	_, err = part.Write([]byte{0x01, 0x02})
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Close())
+
+	return &Response{
+		Headers: map[string][]string{"Content-Type": {mediaType + "; boundary=" + writer.Boundary()}},
+		Body:    io.NopCloser(&buffer),
+	}
+}
+
+func TestMultipartReader_NextPart(t *testing.T) {
+	t.Parallel()
+
+	for _, mediaType := range []string{"multipart/mixed", "multipart/related"} {
+		t.Run("success: "+mediaType, func(t *testing.T) {
+			t.Parallel()
+
+			reader, err := NewMultipartReader(newMultipartResponse(t, mediaType))
+			require.NoError(t, err)
+
+			part, err := reader.NextPart()
+			require.NoError(t, err)
+			assert.Equal(t, "application/json", getHeader(part.Headers, "Content-Type"))
+			body, err := io.ReadAll(part.Body)
+			require.NoError(t, err)
+			assert.Equal(t, []byte(`{"id":1}`), body)
+
+			part, err = reader.NextPart()
+			require.NoError(t, err)
+			assert.Equal(t, "application/octet-stream", getHeader(part.Headers, "Content-Type"))
+			body, err = io.ReadAll(part.Body)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{0x01, 0x02}, body)
+
+			_, err = reader.NextPart()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestNewMultipartReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{name: "failure: not multipart", contentType: "application/json"},
+		{name: "failure: missing boundary", contentType: "multipart/mixed"},
+		{name: "failure: malformed content type", contentType: "multipart/mixed; boundary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewMultipartReader(&Response{
+				Headers: map[string][]string{"Content-Type": {tt.contentType}},
+				Body:    io.NopCloser(bytes.NewReader(nil)),
+			})
+			assert.Error(t, err)
+			assert.Equal(t, CategoryDecode, ClassifyError(err))
+		})
+	}
+}
+
+func TestEachPart(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: visits every part", func(t *testing.T) {
+		t.Parallel()
+
+		var contentTypes []string
+		err := EachPart(newMultipartResponse(t, "multipart/mixed"), func(part *Part) error {
+			contentTypes = append(contentTypes, getHeader(part.Headers, "Content-Type"))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"application/json", "application/octet-stream"}, contentTypes)
+	})
+
+	t.Run("failure: callback error", func(t *testing.T) {
+		t.Parallel()
+
+		want := errors.New("stop")
+		err := EachPart(newMultipartResponse(t, "multipart/mixed"), func(part *Part) error {
+			return want
+		})
+		assert.ErrorIs(t, err, want)
+	})
+}
@@ -0,0 +1,212 @@
+package webapiclient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MultiStatusResult is the outcome for a single resource of a 207 Multi-Status response.
+type MultiStatusResult struct {
+	// Href identifies the resource.
+	Href string
+	// StatusCode is the status code of the operation on the resource.
+	StatusCode int
+	// Description is the human-readable description of the outcome, if any.
+	Description string
+	// Error is the error reported for the resource, if any.
+	Error string
+}
+
+// OK reports whether the operation on the resource succeeded.
+func (r MultiStatusResult) OK() bool {
+	return r.StatusCode >= http.StatusOK && r.StatusCode < http.StatusMultipleChoices
+}
+
+// multiStatusXML is a WebDAV multistatus element defined in RFC 4918.
+type multiStatusXML struct {
+	Responses []struct {
+		Hrefs    []string `xml:"href"`
+		Status   string   `xml:"status"`
+		Propstat []struct {
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+		Error struct {
+			InnerXML string `xml:",innerxml"`
+		} `xml:"error"`
+		Description string `xml:"responsedescription"`
+	} `xml:"response"`
+}
+
+// multiStatusJSONItem is an item of a JSON multi-status body.
+type multiStatusJSONItem struct {
+	Href        string          `json:"href"`
+	ID          json.RawMessage `json:"id"`
+	Status      json.RawMessage `json:"status"`
+	Description string          `json:"description"`
+	Error       json.RawMessage `json:"error"`
+}
+
+// ParseMultiStatus reads the body of a 207 Multi-Status response, closes it, and returns the outcome for
+// each resource in document order. XML bodies are parsed as WebDAV multistatus elements (RFC 4918).
+// JSON bodies are either an array of items or an object holding them in "responses" or "results", each
+// item identifying its resource with "href" or "id" and carrying a "status", a number or a status line,
+// with an optional "description" and "error". The Content-Type header must be kept in Response.Headers.
+func ParseMultiStatus(response *Response) ([]MultiStatusResult, error) {
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+
+	if err != nil {
+		return nil, errors.WithStack(classifyTransportError(err))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(http.Header(response.Headers).Get("Content-Type"))
+
+	var results []MultiStatusResult
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		results, err = parseMultiStatusJSON(body)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		results, err = parseMultiStatusXML(body)
+	default:
+		return nil, errors.WithStack(newError(CategoryValidation, errors.Errorf("unsupported multi-status content type: %s", mediaType)))
+	}
+
+	if err != nil {
+		return nil, errors.WithStack(newDecodeError(response.StatusCode, body, err))
+	}
+
+	return results, nil
+}
+
+func parseMultiStatusXML(body []byte) ([]MultiStatusResult, error) {
+	var multiStatus multiStatusXML
+
+	err := xml.Unmarshal(body, &multiStatus)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	results := make([]MultiStatusResult, 0, len(multiStatus.Responses))
+	for _, response := range multiStatus.Responses {
+		statusCode, err := parseStatusLine(response.Status)
+		if response.Status == "" {
+			// The status of a response with properties is the least successful status of its properties.
+			for _, propstat := range response.Propstat {
+				propstatCode, propstatErr := parseStatusLine(propstat.Status)
+				if propstatErr != nil || propstatCode > statusCode {
+					statusCode, err = propstatCode, propstatErr
+				}
+			}
+		}
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, href := range response.Hrefs {
+			results = append(results, MultiStatusResult{
+				Href:        href,
+				StatusCode:  statusCode,
+				Description: strings.TrimSpace(response.Description),
+				Error:       strings.TrimSpace(response.Error.InnerXML),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func parseMultiStatusJSON(body []byte) ([]MultiStatusResult, error) {
+	var items []multiStatusJSONItem
+
+	if err := json.Unmarshal(body, &items); err != nil {
+		var envelope struct {
+			Responses []multiStatusJSONItem `json:"responses"`
+			Results   []multiStatusJSONItem `json:"results"`
+		}
+
+		if json.Unmarshal(body, &envelope) != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		items = append(envelope.Responses, envelope.Results...)
+	}
+
+	results := make([]MultiStatusResult, 0, len(items))
+	for _, item := range items {
+		statusCode, err := parseJSONStatus(item.Status)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		href := item.Href
+		if href == "" {
+			href = rawJSONString(item.ID)
+		}
+
+		results = append(results, MultiStatusResult{
+			Href:        href,
+			StatusCode:  statusCode,
+			Description: item.Description,
+			Error:       rawJSONString(item.Error),
+		})
+	}
+
+	return results, nil
+}
+
+// parseStatusLine parses the status code of an HTTP status line such as "HTTP/1.1 404 Not Found".
+func parseStatusLine(line string) (int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+		return 0, errors.Errorf("invalid status line: %q", line)
+	}
+
+	statusCode, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, errors.Errorf("invalid status line: %q", line)
+	}
+
+	return statusCode, nil
+}
+
+// parseJSONStatus parses a status given as a number, a numeric string or a status line.
+func parseJSONStatus(raw json.RawMessage) (int, error) {
+	var statusCode int
+	if json.Unmarshal(raw, &statusCode) == nil {
+		return statusCode, nil
+	}
+
+	var status string
+	if json.Unmarshal(raw, &status) != nil {
+		return 0, errors.Errorf("invalid status: %s", raw)
+	}
+
+	if statusCode, err := strconv.Atoi(status); err == nil {
+		return statusCode, nil
+	}
+
+	return parseStatusLine(status)
+}
+
+// rawJSONString returns a JSON string unquoted, other values as they appear, and an empty string for null.
+func rawJSONString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	return string(raw)
+}
@@ -0,0 +1,119 @@
+package webapiclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMultiStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		contentType  string
+		body         string
+		want         []MultiStatusResult
+		wantCategory ErrorCategory
+	}{
+		{
+			name:        "success: WebDAV XML",
+			contentType: "application/xml; charset=utf-8",
+			body: `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:">
+  <D:response>
+    <D:href>/files/a.txt</D:href>
+    <D:href>/files/b.txt</D:href>
+    <D:status>HTTP/1.1 204 No Content</D:status>
+  </D:response>
+  <D:response>
+    <D:href>/files/locked.txt</D:href>
+    <D:status>HTTP/1.1 423 Locked</D:status>
+    <D:error><D:lock-token-submitted/></D:error>
+    <D:responsedescription>The file is locked</D:responsedescription>
+  </D:response>
+  <D:response>
+    <D:href>/files/c.txt</D:href>
+    <D:propstat><D:prop><D:displayname/></D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>
+    <D:propstat><D:prop><D:owner/></D:prop><D:status>HTTP/1.1 403 Forbidden</D:status></D:propstat>
+  </D:response>
+</D:multistatus>`,
+			want: []MultiStatusResult{
+				{Href: "/files/a.txt", StatusCode: http.StatusNoContent},
+				{Href: "/files/b.txt", StatusCode: http.StatusNoContent},
+				{Href: "/files/locked.txt", StatusCode: http.StatusLocked, Description: "The file is locked", Error: "<D:lock-token-submitted/>"},
+				{Href: "/files/c.txt", StatusCode: http.StatusForbidden},
+			},
+		},
+		{
+			name:        "success: JSON array",
+			contentType: "application/json",
+			body:        `[{"id":1,"status":201},{"id":"x","status":"HTTP/1.1 409 Conflict","error":{"code":"duplicate"}}]`,
+			want: []MultiStatusResult{
+				{Href: "1", StatusCode: http.StatusCreated},
+				{Href: "x", StatusCode: http.StatusConflict, Error: `{"code":"duplicate"}`},
+			},
+		},
+		{
+			name:        "success: JSON envelope",
+			contentType: "application/vnd.api+json",
+			body:        `{"responses":[{"href":"/users/1","status":"200","description":"updated"},{"href":"/users/2","status":404,"error":"not found"}]}`,
+			want: []MultiStatusResult{
+				{Href: "/users/1", StatusCode: http.StatusOK, Description: "updated"},
+				{Href: "/users/2", StatusCode: http.StatusNotFound, Error: "not found"},
+			},
+		},
+		{
+			name:         "failure: invalid status line",
+			contentType:  "text/xml",
+			body:         `<multistatus xmlns="DAV:"><response><href>/a</href><status>OK</status></response></multistatus>`,
+			wantCategory: CategoryDecode,
+		},
+		{
+			name:         "failure: invalid JSON",
+			contentType:  "application/json",
+			body:         `{"responses":`,
+			wantCategory: CategoryDecode,
+		},
+		{
+			name:         "failure: unsupported content type",
+			contentType:  "text/plain",
+			body:         `ok`,
+			wantCategory: CategoryValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response := &Response{
+				StatusCode: http.StatusMultiStatus,
+				Headers:    map[string][]string{"Content-Type": {tt.contentType}},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			got, err := ParseMultiStatus(response)
+			if tt.wantCategory != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCategory, ClassifyError(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMultiStatusResult_OK(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, MultiStatusResult{StatusCode: http.StatusNoContent}.OK())
+	assert.False(t, MultiStatusResult{StatusCode: http.StatusLocked}.OK())
+	assert.False(t, MultiStatusResult{}.OK())
+}
@@ -0,0 +1,282 @@
+// Package oauth2 provides helpers for the OAuth 2.0 authorization code flow with PKCE.
+package oauth2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/pkg/errors"
+)
+
+const (
+	// verifierLength is the number of random bytes used for a PKCE code verifier.
+	verifierLength = 32
+	// expiryDelta is subtracted from token lifetimes so that tokens are refreshed before they expire.
+	expiryDelta = 10 * time.Second
+)
+
+// Config describes an OAuth 2.0 client registered with an authorization server.
+type Config struct {
+	ClientID         string
+	ClientSecret     string
+	AuthorizationURL string
+	TokenURL         string
+	RedirectURL      string
+	Scopes           []string
+}
+
+// Token is a set of credentials issued by the token endpoint.
+type Token struct {
+	AccessToken  string    `json:"access_token"`  //nolint:tagliatelle
+	TokenType    string    `json:"token_type"`    //nolint:tagliatelle
+	RefreshToken string    `json:"refresh_token"` //nolint:tagliatelle
+	ExpiresIn    int64     `json:"expires_in"`    //nolint:tagliatelle
+	Expiry       time.Time `json:"-"`
+}
+
+// Valid reports whether the token has an access token that has not expired yet.
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry))
+}
+
+// TokenError is an error response returned by the token endpoint.
+type TokenError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"` //nolint:tagliatelle
+}
+
+// Error returns the error code and description reported by the token endpoint.
+func (e *TokenError) Error() string {
+	if e.Description == "" {
+		return "oauth2: " + e.Code
+	}
+
+	return "oauth2: " + e.Code + ": " + e.Description
+}
+
+// GenerateVerifier returns a new random PKCE code verifier.
+func GenerateVerifier() (string, error) {
+	buffer := make([]byte, verifierLength)
+
+	_, err := rand.Read(buffer)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+// ChallengeS256 returns the S256 PKCE code challenge for verifier.
+func ChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Flow runs the authorization code flow against the token endpoint using a webapiclient.Client.
+type Flow struct {
+	client webapiclient.Client
+	config Config
+}
+
+// NewFlow creates a new Flow that sends token requests through client.
+func NewFlow(client webapiclient.Client, config Config) *Flow {
+	return &Flow{
+		client: client,
+		config: config,
+	}
+}
+
+// AuthCodeURL returns the URL of the authorization endpoint to which the user is sent
+// to grant access, with the PKCE challenge derived from verifier.
+func (f *Flow) AuthCodeURL(state string, verifier string) (string, error) {
+	authorizationURL, err := url.Parse(f.config.AuthorizationURL)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	query := authorizationURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", f.config.ClientID)
+	query.Set("state", state)
+	query.Set("code_challenge", ChallengeS256(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	if f.config.RedirectURL != "" {
+		query.Set("redirect_uri", f.config.RedirectURL)
+	}
+
+	if len(f.config.Scopes) > 0 {
+		query.Set("scope", strings.Join(f.config.Scopes, " "))
+	}
+
+	authorizationURL.RawQuery = query.Encode()
+
+	return authorizationURL.String(), nil
+}
+
+// Exchange exchanges an authorization code and its PKCE verifier for a token.
+func (f *Flow) Exchange(ctx context.Context, code string, verifier string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {verifier},
+	}
+
+	if f.config.RedirectURL != "" {
+		form.Set("redirect_uri", f.config.RedirectURL)
+	}
+
+	return f.requestToken(ctx, form)
+}
+
+// Refresh obtains a new token using a refresh token.
+// The given refresh token is kept when the server does not rotate it.
+func (f *Flow) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	token, err := f.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+
+	return token, nil
+}
+
+func (f *Flow) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	form.Set("client_id", f.config.ClientID)
+
+	if f.config.ClientSecret != "" {
+		form.Set("client_secret", f.config.ClientSecret)
+	}
+
+	response, err := f.client.Do(ctx, &webapiclient.Request{
+		Method: http.MethodPost,
+		Path:   f.config.TokenURL,
+		Headers: map[string][]string{
+			"Accept": {"application/json"},
+		},
+		Form: form,
+	}, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		tokenError := &TokenError{StatusCode: response.StatusCode}
+
+		err = json.NewDecoder(response.Body).Decode(tokenError)
+		if err != nil || tokenError.Code == "" {
+			return nil, errors.Errorf("oauth2: unexpected status code: %d", response.StatusCode)
+		}
+
+		return nil, errors.WithStack(tokenError)
+	}
+
+	var token Token
+
+	err = json.NewDecoder(response.Body).Decode(&token)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if token.AccessToken == "" {
+		return nil, errors.New("oauth2: token response has no access_token")
+	}
+
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	return &token, nil
+}
+
+// TokenSource holds a token and refreshes it when it expires.
+type TokenSource struct {
+	flow    *Flow
+	mu      sync.Mutex
+	current *Token
+}
+
+// TokenSource returns a TokenSource that starts from token and refreshes it through the flow.
+func (f *Flow) TokenSource(token *Token) *TokenSource {
+	return &TokenSource{
+		flow:    f,
+		current: token,
+	}
+}
+
+// Token returns a valid token, refreshing it when the current one has expired. A refresh publishes
+// webapiclient.TokenRefreshed on the event bus carried by ctx, such as the context of a request passed to Authorize.
+func (s *TokenSource) Token(ctx context.Context) (*Token, error) {
+	token, refreshed, err := s.token(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The event is published without holding the lock, so that subscribers can call Token.
+	if refreshed {
+		webapiclient.PublishEvent(ctx, webapiclient.TokenRefreshed{Expiry: token.Expiry})
+	}
+
+	return token, nil
+}
+
+// token returns a valid token, and whether it was refreshed.
+func (s *TokenSource) token(ctx context.Context) (*Token, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current.Valid() {
+		return s.current, false, nil
+	}
+
+	if s.current == nil || s.current.RefreshToken == "" {
+		return nil, false, errors.New("oauth2: token expired and no refresh token is available")
+	}
+
+	token, err := s.flow.Refresh(ctx, s.current.RefreshToken)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+
+	s.current = token
+
+	return token, true, nil
+}
+
+// Authorize sets the Authorization header of httpRequest from a valid token.
+// It can be passed to webapiclient.Client.Do as a webapiclient.EditRequestFunc.
+func (s *TokenSource) Authorize(httpRequest *http.Request) error {
+	token, err := s.Token(httpRequest.Context())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	httpRequest.Header.Set("Authorization", tokenType+" "+token.AccessToken)
+
+	return nil
+}
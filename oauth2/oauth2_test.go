@@ -0,0 +1,278 @@
+package oauth2
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{
+	ClientID:         "client",
+	AuthorizationURL: "https://auth.example.com/authorize",
+	TokenURL:         "https://auth.example.com/token",
+	RedirectURL:      "http://localhost:8080/callback",
+	Scopes:           []string{"read", "write"},
+}
+
+func newTokenEndpoint(t *testing.T, status int, body string, check func(form url.Values)) webapiclient.DoFunc {
+	t.Helper()
+
+	return func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "https://auth.example.com/token", req.URL.String())
+		assert.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+
+		require.NoError(t, req.ParseForm())
+		if check != nil {
+			check(req.PostForm)
+		}
+
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	}
+}
+
+func TestGenerateVerifier(t *testing.T) {
+	t.Parallel()
+
+	verifier, err := GenerateVerifier()
+	require.NoError(t, err)
+	assert.Len(t, verifier, 43)
+
+	other, err := GenerateVerifier()
+	require.NoError(t, err)
+	assert.NotEqual(t, verifier, other)
+}
+
+func TestChallengeS256(t *testing.T) {
+	t.Parallel()
+
+	// Example from RFC 7636 Appendix B.
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", ChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func TestFlow_AuthCodeURL(t *testing.T) {
+	t.Parallel()
+
+	flow := NewFlow(webapiclient.NewClient("https://auth.example.com"), testConfig)
+
+	got, err := flow.AuthCodeURL("state", "verifier")
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(got)
+	require.NoError(t, err)
+	assert.Equal(t, "auth.example.com", parsed.Host)
+	assert.Equal(t, "/authorize", parsed.Path)
+	assert.Equal(t, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"client"},
+		"state":                 {"state"},
+		"code_challenge":        {ChallengeS256("verifier")},
+		"code_challenge_method": {"S256"},
+		"redirect_uri":          {"http://localhost:8080/callback"},
+		"scope":                 {"read write"},
+	}, parsed.Query())
+}
+
+func TestFlow_Exchange(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		status int
+		body   string
+	}
+	type want struct {
+		err   bool
+		token *Token
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "success: token issued",
+			args: args{
+				status: http.StatusOK,
+				body:   `{"access_token":"access","token_type":"Bearer","refresh_token":"refresh","expires_in":3600}`,
+			},
+			want: want{
+				token: &Token{AccessToken: "access", TokenType: "Bearer", RefreshToken: "refresh", ExpiresIn: 3600},
+			},
+		},
+		{
+			name: "failure: error response",
+			args: args{
+				status: http.StatusBadRequest,
+				body:   `{"error":"invalid_grant","error_description":"code expired"}`,
+			},
+			want: want{err: true},
+		},
+		{
+			name: "failure: missing access token",
+			args: args{status: http.StatusOK, body: `{}`},
+			want: want{err: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			do := newTokenEndpoint(t, tt.args.status, tt.args.body, func(form url.Values) {
+				assert.Equal(t, "authorization_code", form.Get("grant_type"))
+				assert.Equal(t, "code", form.Get("code"))
+				assert.Equal(t, "verifier", form.Get("code_verifier"))
+				assert.Equal(t, "client", form.Get("client_id"))
+				assert.Equal(t, "http://localhost:8080/callback", form.Get("redirect_uri"))
+			})
+			flow := NewFlow(webapiclient.NewClient("https://auth.example.com", webapiclient.WithDoFunc(do)), testConfig)
+
+			got, err := flow.Exchange(context.Background(), "code", "verifier")
+			if tt.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), got.Expiry, time.Minute)
+			got.Expiry = time.Time{}
+			assert.Equal(t, tt.want.token, got)
+		})
+	}
+}
+
+func TestFlow_Exchange_TokenError(t *testing.T) {
+	t.Parallel()
+
+	do := newTokenEndpoint(t, http.StatusBadRequest, `{"error":"invalid_grant","error_description":"code expired"}`, nil)
+	flow := NewFlow(webapiclient.NewClient("https://auth.example.com", webapiclient.WithDoFunc(do)), testConfig)
+
+	_, err := flow.Exchange(context.Background(), "code", "verifier")
+
+	var tokenError *TokenError
+	require.ErrorAs(t, err, &tokenError)
+	assert.Equal(t, http.StatusBadRequest, tokenError.StatusCode)
+	assert.Equal(t, "invalid_grant", tokenError.Code)
+	assert.Equal(t, "oauth2: invalid_grant: code expired", tokenError.Error())
+}
+
+func TestFlow_Refresh(t *testing.T) {
+	t.Parallel()
+
+	do := newTokenEndpoint(t, http.StatusOK, `{"access_token":"new-access","token_type":"Bearer"}`, func(form url.Values) {
+		assert.Equal(t, "refresh_token", form.Get("grant_type"))
+		assert.Equal(t, "refresh", form.Get("refresh_token"))
+	})
+	flow := NewFlow(webapiclient.NewClient("https://auth.example.com", webapiclient.WithDoFunc(do)), testConfig)
+
+	got, err := flow.Refresh(context.Background(), "refresh")
+	require.NoError(t, err)
+	assert.Equal(t, "new-access", got.AccessToken)
+	assert.Equal(t, "refresh", got.RefreshToken)
+}
+
+func TestTokenSource_Authorize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: valid token", func(t *testing.T) {
+		t.Parallel()
+
+		flow := NewFlow(webapiclient.NewClient("https://auth.example.com"), testConfig)
+		source := flow.TokenSource(&Token{AccessToken: "access", TokenType: "bearer", Expiry: time.Now().Add(time.Hour)})
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.example.com", nil)
+		require.NoError(t, err)
+		require.NoError(t, source.Authorize(req))
+		assert.Equal(t, "Bearer access", req.Header.Get("Authorization"))
+	})
+
+	t.Run("success: expired token is refreshed", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := newTokenEndpoint(t, http.StatusOK, `{"access_token":"new-access","expires_in":3600}`, func(form url.Values) {
+			calls++
+		})
+		flow := NewFlow(webapiclient.NewClient("https://auth.example.com", webapiclient.WithDoFunc(do)), testConfig)
+		source := flow.TokenSource(&Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)})
+
+		for range 2 {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.example.com", nil)
+			require.NoError(t, err)
+			require.NoError(t, source.Authorize(req))
+			assert.Equal(t, "Bearer new-access", req.Header.Get("Authorization"))
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("failure: expired token without refresh token", func(t *testing.T) {
+		t.Parallel()
+
+		flow := NewFlow(webapiclient.NewClient("https://auth.example.com"), testConfig)
+		source := flow.TokenSource(&Token{AccessToken: "access", Expiry: time.Now().Add(-time.Minute)})
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.example.com", nil)
+		require.NoError(t, err)
+		assert.Error(t, source.Authorize(req))
+	})
+}
+
+func TestTokenSource_Token_TokenRefreshed(t *testing.T) {
+	t.Parallel()
+
+	do := newTokenEndpoint(t, http.StatusOK, `{"access_token":"new-access","expires_in":3600}`, nil)
+	flow := NewFlow(webapiclient.NewClient("https://auth.example.com", webapiclient.WithDoFunc(do)), testConfig)
+	source := flow.TokenSource(&Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)})
+
+	bus := webapiclient.NewEventBus()
+
+	var subscriberToken *Token
+
+	webapiclient.SubscribeTo(bus, func(event webapiclient.TokenRefreshed) {
+		// A subscriber calling back into the source must not deadlock.
+		token, err := source.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, token.Expiry, event.Expiry)
+
+		subscriberToken = token
+	})
+
+	api := webapiclient.NewClient("https://api.example.com", webapiclient.WithEventBus(bus),
+		webapiclient.WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "Bearer new-access", req.Header.Get("Authorization"))
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}),
+	)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		response, err := api.Do(context.Background(), &webapiclient.Request{Method: http.MethodGet, Path: "/me"}, source.Authorize)
+		if assert.NoError(t, err) {
+			_ = response.Body.Close()
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock: the subscriber could not call Token")
+	}
+
+	require.NotNil(t, subscriberToken)
+	assert.Equal(t, "new-access", subscriberToken.AccessToken)
+}
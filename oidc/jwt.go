@@ -0,0 +1,251 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA-256 for crypto.Hash
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// leeway is the clock skew tolerated when validating time based claims.
+	leeway = time.Minute
+	// tokenParts is the number of dot separated parts of a JWS compact serialization.
+	tokenParts = 3
+)
+
+// Claims are the registered claims of a verified token together with all raw claims.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	NotBefore time.Time
+	Nonce     string
+	Raw       map[string]any
+}
+
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Verify verifies the signature of a JWT such as an ID token issued by the provider using
+// its published signing keys, and validates the issuer, audience and time based claims.
+// audience is the client ID the token must be issued to; it must not be empty.
+func (p *Provider) Verify(ctx context.Context, token string, audience string) (*Claims, error) {
+	if audience == "" {
+		return nil, errors.New("oidc: audience must not be empty")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != tokenParts {
+		return nil, errors.New("oidc: malformed token")
+	}
+
+	var header tokenHeader
+
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	key, err := p.Key(ctx, header.KeyID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = verifySignature(header.Algorithm, key, []byte(parts[0]+"."+parts[1]), signature)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	claims, err := parseClaims(parts[1])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = p.validateClaims(claims, audience, time.Now())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) validateClaims(claims *Claims, audience string, now time.Time) error {
+	if strings.TrimSuffix(claims.Issuer, "/") != p.issuer {
+		return errors.Errorf("oidc: unexpected issuer: %s", claims.Issuer)
+	}
+
+	if !slices.Contains(claims.Audience, audience) {
+		return errors.Errorf("oidc: unexpected audience: %v", claims.Audience)
+	}
+
+	if claims.ExpiresAt.IsZero() {
+		return errors.New("oidc: token has no expiry")
+	}
+
+	if now.After(claims.ExpiresAt.Add(leeway)) {
+		return errors.Errorf("oidc: token expired at %s", claims.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if !claims.NotBefore.IsZero() && now.Before(claims.NotBefore.Add(-leeway)) {
+		return errors.Errorf("oidc: token not valid before %s", claims.NotBefore.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func verifySignature(algorithm string, key crypto.PublicKey, signingInput []byte, signature []byte) error {
+	hash, err := hashByAlgorithm(algorithm)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	hasher := hash.New()
+	_, _ = hasher.Write(signingInput)
+	digest := hasher.Sum(nil)
+
+	switch algorithm[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.Errorf("oidc: key does not match algorithm: %s", algorithm)
+		}
+
+		if algorithm[0] == 'P' {
+			return errors.WithStack(rsa.VerifyPSS(rsaKey, hash, digest, signature, nil))
+		}
+
+		return errors.WithStack(rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature))
+	case "ES":
+		ecdsaKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.Errorf("oidc: key does not match algorithm: %s", algorithm)
+		}
+
+		size := (ecdsaKey.Curve.Params().BitSize + 7) / 8 //nolint:mnd
+		if len(signature) != 2*size {
+			return errors.New("oidc: invalid signature length")
+		}
+
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+
+		if !ecdsa.Verify(ecdsaKey, digest, r, s) {
+			return errors.New("oidc: invalid signature")
+		}
+
+		return nil
+	default:
+		return errors.Errorf("oidc: unsupported algorithm: %s", algorithm)
+	}
+}
+
+func hashByAlgorithm(algorithm string) (crypto.Hash, error) {
+	if len(algorithm) != len("RS256") || !slices.Contains([]string{"RS", "PS", "ES"}, algorithm[:2]) {
+		return 0, errors.Errorf("oidc: unsupported algorithm: %s", algorithm)
+	}
+
+	switch algorithm[2:] {
+	case "256":
+		return crypto.SHA256, nil
+	case "384":
+		return crypto.SHA384, nil
+	case "512":
+		return crypto.SHA512, nil
+	default:
+		return 0, errors.Errorf("oidc: unsupported algorithm: %s", algorithm)
+	}
+}
+
+func parseClaims(segment string) (*Claims, error) {
+	var raw map[string]any
+
+	err := decodeSegment(segment, &raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	claims := &Claims{Raw: raw}
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Nonce, _ = raw["nonce"].(string)
+
+	switch audience := raw["aud"].(type) {
+	case string:
+		claims.Audience = []string{audience}
+	case []any:
+		for _, value := range audience {
+			if s, ok := value.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+
+	claims.ExpiresAt, err = numericDate(raw, "exp")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	claims.IssuedAt, err = numericDate(raw, "iat")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	claims.NotBefore, err = numericDate(raw, "nbf")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return claims, nil
+}
+
+func numericDate(raw map[string]any, name string) (time.Time, error) {
+	value, ok := raw[name]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, errors.Errorf("oidc: claim %s is not a number", name)
+	}
+
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, errors.WithStack(err)
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return errors.WithStack(decoder.Decode(v))
+}
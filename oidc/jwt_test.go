@@ -0,0 +1,166 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signToken(t *testing.T, algorithm string, keyID string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": algorithm, "kid": keyID, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestProvider_Verify(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, rsaJSONWebKey := newRSAKey(t, "rsa")
+	ecdsaKey, ecdsaJSONWebKey := newECDSAKey(t, "ecdsa")
+	_, otherJSONWebKey := newRSAKey(t, "other")
+	otherJSONWebKey.KeyID = "rsa-other"
+
+	now := time.Now()
+	validClaims := func(overrides map[string]any) map[string]any {
+		claims := map[string]any{
+			"iss":   testIssuer,
+			"sub":   "user-1",
+			"aud":   []string{"client", "other"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "nonce",
+		}
+		for key, value := range overrides {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{
+			name:  "success: RS256",
+			token: signToken(t, "RS256", "rsa", rsaKey, validClaims(nil)),
+		},
+		{
+			name:  "success: ES256 with string audience",
+			token: signToken(t, "ES256", "ecdsa", ecdsaKey, validClaims(map[string]any{"aud": "client"})),
+		},
+		{
+			name:    "failure: signed by another key",
+			token:   signToken(t, "RS256", "rsa-other", rsaKey, validClaims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "failure: algorithm does not match key",
+			token:   signToken(t, "ES256", "rsa", ecdsaKey, validClaims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "failure: unsupported algorithm",
+			token:   signToken(t, "HS256", "rsa", rsaKey, validClaims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "failure: expired",
+			token:   signToken(t, "RS256", "rsa", rsaKey, validClaims(map[string]any{"exp": now.Add(-time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "failure: not yet valid",
+			token:   signToken(t, "RS256", "rsa", rsaKey, validClaims(map[string]any{"nbf": now.Add(time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "failure: unexpected audience",
+			token:   signToken(t, "RS256", "rsa", rsaKey, validClaims(map[string]any{"aud": "someone-else"})),
+			wantErr: true,
+		},
+		{
+			name:    "failure: unexpected issuer",
+			token:   signToken(t, "RS256", "rsa", rsaKey, validClaims(map[string]any{"iss": "https://evil.example.com"})),
+			wantErr: true,
+		},
+		{
+			name:    "failure: malformed",
+			token:   "not-a-token",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newTestServer(rsaJSONWebKey, ecdsaJSONWebKey, otherJSONWebKey)
+			provider := NewProvider(webapiclient.NewClient(testIssuer, webapiclient.WithDoFunc(server.do)), testIssuer, time.Hour)
+
+			got, err := provider.Verify(context.Background(), tt.token, "client")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testIssuer, got.Issuer)
+			assert.Equal(t, "user-1", got.Subject)
+			assert.Contains(t, got.Audience, "client")
+			assert.Equal(t, "nonce", got.Nonce)
+			assert.Equal(t, now.Add(time.Hour).Unix(), got.ExpiresAt.Unix())
+			assert.Equal(t, now.Unix(), got.IssuedAt.Unix())
+		})
+	}
+}
+
+func TestProvider_Verify_EmptyAudience(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, rsaJSONWebKey := newRSAKey(t, "rsa")
+	server := newTestServer(rsaJSONWebKey)
+	provider := NewProvider(webapiclient.NewClient(testIssuer, webapiclient.WithDoFunc(server.do)), testIssuer, time.Hour)
+
+	token := signToken(t, "RS256", "rsa", rsaKey, map[string]any{
+		"iss": testIssuer,
+		"sub": "user-1",
+		"aud": "someone-else",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	_, err := provider.Verify(context.Background(), token, "")
+	require.ErrorContains(t, err, "audience must not be empty")
+	assert.Zero(t, server.count("/jwks"))
+}
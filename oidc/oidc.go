@@ -0,0 +1,301 @@
+// Package oidc provides OpenID Connect discovery and JWKS based token validation.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/pkg/errors"
+)
+
+const (
+	// discoveryPath is the well-known path of the OpenID Provider configuration document.
+	discoveryPath = "/.well-known/openid-configuration"
+	// minKeyRefetchInterval is the minimum time between two fetches of the key set for unknown key IDs, so that
+	// tokens with random key IDs cannot make the provider fetch it for every token.
+	minKeyRefetchInterval = time.Minute
+)
+
+// Discovery is the subset of the OpenID Provider configuration document used by this package.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"` //nolint:tagliatelle
+	TokenEndpoint         string `json:"token_endpoint"`         //nolint:tagliatelle
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`      //nolint:tagliatelle
+	JWKSURI               string `json:"jwks_uri"`               //nolint:tagliatelle
+}
+
+// JSONWebKey is a single key of a JSON Web Key Set.
+type JSONWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// Provider fetches and caches the discovery document and signing keys of an OpenID Provider.
+// Documents are fetched without holding the lock guarding the cache, and concurrent callers needing
+// the key set share a single fetch.
+type Provider struct {
+	client webapiclient.Client
+	issuer string
+	ttl    time.Duration
+
+	mu                 sync.Mutex
+	discovery          *Discovery
+	discoveryFetchedAt time.Time
+	// keys is replaced, never modified, so that it can be read without holding mu.
+	keys            map[string]crypto.PublicKey
+	keysFetchedAt   time.Time
+	keysAttemptedAt time.Time
+	// keysFetching is closed when the fetch of the key set in flight, if any, is done.
+	keysFetching chan struct{}
+}
+
+// NewProvider creates a new Provider for issuer that sends requests through client
+// and caches fetched documents for ttl.
+func NewProvider(client webapiclient.Client, issuer string, ttl time.Duration) *Provider {
+	return &Provider{
+		client: client,
+		issuer: strings.TrimSuffix(issuer, "/"),
+		ttl:    ttl,
+	}
+}
+
+// Discovery returns the discovery document of the provider.
+func (p *Provider) Discovery(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	discovery, fetchedAt := p.discovery, p.discoveryFetchedAt
+	p.mu.Unlock()
+
+	if discovery != nil && time.Since(fetchedAt) <= p.ttl {
+		return discovery, nil
+	}
+
+	discovery = &Discovery{}
+
+	err := p.getJSON(ctx, p.issuer+discoveryPath, discovery)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.issuer {
+		return nil, errors.Errorf("oidc: issuer mismatch: %s", discovery.Issuer)
+	}
+
+	p.mu.Lock()
+	p.discovery = discovery
+	p.discoveryFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	return discovery, nil
+}
+
+// Key returns the signing key identified by keyID. The key set is fetched again when the key is unknown,
+// at most once a minute, so that rotated keys are picked up without waiting for the cache to expire.
+func (p *Provider) Key(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	keys, err := p.keySet(ctx, false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	key, ok := keys[keyID]
+	if ok {
+		return key, nil
+	}
+
+	keys, err = p.keySet(ctx, true)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	key, ok = keys[keyID]
+	if !ok {
+		return nil, errors.Errorf("oidc: unknown key id: %s", keyID)
+	}
+
+	return key, nil
+}
+
+// keySet returns the cached key set, fetching it when it is missing or expired, or when refetch is set and
+// it was not fetched within minKeyRefetchInterval. Callers arriving during a fetch wait for its result.
+func (p *Provider) keySet(ctx context.Context, refetch bool) (map[string]crypto.PublicKey, error) {
+	p.mu.Lock()
+
+	for p.keysFetching != nil {
+		fetching := p.keysFetching
+		p.mu.Unlock()
+
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, errors.WithStack(ctx.Err())
+		}
+
+		p.mu.Lock()
+	}
+
+	expired := p.keys == nil || time.Since(p.keysFetchedAt) > p.ttl
+	if !expired && (!refetch || time.Since(p.keysAttemptedAt) < minKeyRefetchInterval) {
+		keys := p.keys
+		p.mu.Unlock()
+
+		return keys, nil
+	}
+
+	fetching := make(chan struct{})
+	p.keysFetching = fetching
+	p.keysAttemptedAt = time.Now()
+	p.mu.Unlock()
+
+	keys, err := p.fetchKeys(ctx)
+
+	p.mu.Lock()
+	if err == nil {
+		p.keys = keys
+		p.keysFetchedAt = time.Now()
+	}
+
+	p.keysFetching = nil
+	close(fetching)
+	p.mu.Unlock()
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return keys, nil
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	discovery, err := p.Discovery(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var keySet struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+
+	err = p.getJSON(ctx, discovery.JWKSURI, &keySet)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(keySet.Keys))
+	for _, jsonWebKey := range keySet.Keys {
+		if jsonWebKey.Use != "" && jsonWebKey.Use != "sig" {
+			continue
+		}
+
+		key, err := jsonWebKey.PublicKey()
+		if err != nil {
+			continue
+		}
+
+		keys[jsonWebKey.KeyID] = key
+	}
+
+	return keys, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, path string, v any) error {
+	response, err := p.client.Do(ctx, &webapiclient.Request{
+		Method:               http.MethodGet,
+		Path:                 path,
+		Headers:              map[string][]string{"Accept": {"application/json"}},
+		ExpectedStatusCodes:  []int{http.StatusOK},
+		ExpectedContentTypes: []string{"application/json", "application/jwk-set+json"},
+	}, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	err = json.NewDecoder(response.Body).Decode(v)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// PublicKey returns the RSA or ECDSA public key described by the JSON Web Key.
+func (k *JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, err := curveByName(k.Curve)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("oidc: unsupported key type: %s", k.KeyType)
+	}
+}
+
+func curveByName(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, errors.Errorf("oidc: unsupported curve: %s", name)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("oidc: empty key parameter")
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
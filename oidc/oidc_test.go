@@ -0,0 +1,268 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "https://issuer.example.com"
+
+type testServer struct {
+	mu        sync.Mutex
+	issuer    string
+	keys      []JSONWebKey
+	calls     map[string]int
+	keysCalls int
+}
+
+func newTestServer(keys ...JSONWebKey) *testServer {
+	return &testServer{issuer: testIssuer, keys: keys, calls: map[string]int{}}
+}
+
+func (s *testServer) setKeys(keys ...JSONWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+}
+
+func (s *testServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[path]
+}
+
+func (s *testServer) do(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[req.URL.Path]++
+
+	var body any
+	switch req.URL.Path {
+	case "/.well-known/openid-configuration":
+		body = Discovery{Issuer: s.issuer, JWKSURI: testIssuer + "/jwks"}
+	case "/jwks":
+		body = map[string]any{"keys": s.keys}
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+	}, nil
+}
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func newRSAKey(t *testing.T, keyID string) (*rsa.PrivateKey, JSONWebKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return key, JSONWebKey{
+		KeyType: "RSA",
+		KeyID:   keyID,
+		Use:     "sig",
+		N:       encodeBigInt(key.N),
+		E:       encodeBigInt(big.NewInt(int64(key.E))),
+	}
+}
+
+func newECDSAKey(t *testing.T, keyID string) (*ecdsa.PrivateKey, JSONWebKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key, JSONWebKey{
+		KeyType: "EC",
+		KeyID:   keyID,
+		Curve:   "P-256",
+		X:       encodeBigInt(key.X),
+		Y:       encodeBigInt(key.Y),
+	}
+}
+
+func TestProvider_Discovery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: cached", func(t *testing.T) {
+		t.Parallel()
+
+		server := newTestServer()
+		provider := NewProvider(webapiclient.NewClient(testIssuer, webapiclient.WithDoFunc(server.do)), testIssuer+"/", time.Hour)
+
+		for range 2 {
+			got, err := provider.Discovery(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, testIssuer+"/jwks", got.JWKSURI)
+		}
+		assert.Equal(t, 1, server.count("/.well-known/openid-configuration"))
+	})
+
+	t.Run("failure: issuer mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		server := newTestServer()
+		server.issuer = "https://other.example.com"
+		provider := NewProvider(webapiclient.NewClient(testIssuer, webapiclient.WithDoFunc(server.do)), testIssuer, time.Hour)
+
+		_, err := provider.Discovery(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestProvider_Key(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: cached", func(t *testing.T) {
+		t.Parallel()
+
+		_, jsonWebKey := newRSAKey(t, "key-1")
+		server := newTestServer(jsonWebKey)
+		provider := NewProvider(webapiclient.NewClient(testIssuer, webapiclient.WithDoFunc(server.do)), testIssuer, time.Hour)
+
+		for range 2 {
+			got, err := provider.Key(context.Background(), "key-1")
+			require.NoError(t, err)
+			assert.IsType(t, &rsa.PublicKey{}, got)
+		}
+		assert.Equal(t, 1, server.count("/jwks"))
+	})
+
+	t.Run("success: rotated key is fetched", func(t *testing.T) {
+		t.Parallel()
+
+		_, oldKey := newRSAKey(t, "key-1")
+		_, newKey := newECDSAKey(t, "key-2")
+		server := newTestServer(oldKey)
+		provider := NewProvider(webapiclient.NewClient(testIssuer, webapiclient.WithDoFunc(server.do)), testIssuer, time.Hour)
+
+		_, err := provider.Key(context.Background(), "key-1")
+		require.NoError(t, err)
+
+		server.setKeys(oldKey, newKey)
+		_, err = provider.Key(context.Background(), "key-2")
+		require.Error(t, err, "the key set is not fetched again within the minimum interval")
+		assert.Equal(t, 1, server.count("/jwks"))
+
+		provider.mu.Lock()
+		provider.keysAttemptedAt = provider.keysAttemptedAt.Add(-minKeyRefetchInterval)
+		provider.mu.Unlock()
+
+		got, err := provider.Key(context.Background(), "key-2")
+		require.NoError(t, err)
+		assert.IsType(t, &ecdsa.PublicKey{}, got)
+		assert.Equal(t, 2, server.count("/jwks"))
+	})
+
+	t.Run("success: concurrent callers share a fetch without blocking the cache", func(t *testing.T) {
+		t.Parallel()
+
+		_, jsonWebKey := newRSAKey(t, "key-1")
+		server := newTestServer(jsonWebKey)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		provider := NewProvider(webapiclient.NewClient(testIssuer, webapiclient.WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/jwks" {
+				close(started)
+				<-release
+			}
+
+			return server.do(req)
+		})), testIssuer, time.Hour)
+
+		var wg sync.WaitGroup
+
+		errs := make(chan error, 3)
+
+		for range 3 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := provider.Key(context.Background(), "key-1")
+				errs <- err
+			}()
+		}
+
+		<-started
+
+		// The discovery document is served from the cache while the key set is being fetched.
+		_, err := provider.Discovery(context.Background())
+		require.NoError(t, err)
+
+		close(release)
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, server.count("/jwks"))
+	})
+
+	t.Run("failure: unknown key", func(t *testing.T) {
+		t.Parallel()
+
+		_, jsonWebKey := newRSAKey(t, "key-1")
+		server := newTestServer(jsonWebKey)
+		provider := NewProvider(webapiclient.NewClient(testIssuer, webapiclient.WithDoFunc(server.do)), testIssuer, time.Hour)
+
+		for _, keyID := range []string{"unknown-1", "unknown-2", "unknown-3"} {
+			_, err := provider.Key(context.Background(), keyID)
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 1, server.count("/jwks"), "unknown key IDs do not refetch the key set within the minimum interval")
+	})
+}
+
+func TestJSONWebKey_PublicKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		key  JSONWebKey
+	}{
+		{name: "failure: unsupported key type", key: JSONWebKey{KeyType: "oct"}},
+		{name: "failure: unsupported curve", key: JSONWebKey{KeyType: "EC", Curve: "P-192", X: "AQ", Y: "AQ"}},
+		{name: "failure: missing modulus", key: JSONWebKey{KeyType: "RSA", E: "AQAB"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.key.PublicKey()
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,515 @@
+// Package openapimock answers HTTP requests with the examples of an OpenAPI 3 document, or with values generated
+// from its schemas, so that clients can be developed and tested against an API that is not built yet.
+package openapimock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// preferCode and preferExample are the parameters of the Prefer request header selecting a response.
+	preferCode    = "code"
+	preferExample = "example"
+)
+
+// formatExamples are the values generated for string schemas with a well-known format.
+var formatExamples = map[string]string{
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"uuid":      "00000000-0000-0000-0000-000000000000",
+}
+
+// document is the subset of an OpenAPI 3 document used to answer requests.
+type document struct {
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas  map[string]*schema  `json:"schemas"`
+		Examples map[string]*example `json:"examples"`
+	} `json:"components"`
+}
+
+type operation struct {
+	Responses map[string]*response `json:"responses"`
+}
+
+type response struct {
+	Headers map[string]*struct {
+		Schema  *schema         `json:"schema"`
+		Example json.RawMessage `json:"example"`
+	} `json:"headers"`
+	Content map[string]*mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema   *schema             `json:"schema"`
+	Example  json.RawMessage     `json:"example"`
+	Examples map[string]*example `json:"examples"`
+}
+
+type example struct {
+	Ref   string          `json:"$ref"`
+	Value json.RawMessage `json:"value"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       any                `json:"type"`
+	Format     string             `json:"format"`
+	Example    json.RawMessage    `json:"example"`
+	Examples   []json.RawMessage  `json:"examples"`
+	Default    json.RawMessage    `json:"default"`
+	Const      json.RawMessage    `json:"const"`
+	Enum       []json.RawMessage  `json:"enum"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+	AllOf      []*schema          `json:"allOf"`
+	OneOf      []*schema          `json:"oneOf"`
+	AnyOf      []*schema          `json:"anyOf"`
+}
+
+// route is an operation of the document with the segments of its path template.
+type route struct {
+	segments  []string
+	method    string
+	operation *operation
+}
+
+// Mock answers requests with the responses of the operations of an OpenAPI 3 document.
+// It is safe for concurrent use.
+type Mock struct {
+	document *document
+	basePath string
+	routes   []route
+}
+
+// New creates a Mock from an OpenAPI 3 document in JSON. YAML documents must be converted to JSON first.
+func New(data []byte) (*Mock, error) {
+	var doc document
+
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "openapimock: decode document")
+	}
+
+	m := &Mock{document: &doc}
+
+	if len(doc.Servers) > 0 {
+		serverURL, err := url.Parse(doc.Servers[0].URL)
+		if err != nil {
+			return nil, errors.Wrap(err, "openapimock: parse server URL")
+		}
+
+		m.basePath = strings.TrimSuffix(serverURL.Path, "/")
+	}
+
+	for path, item := range doc.Paths {
+		for method, raw := range item {
+			if !isMethod(method) {
+				continue
+			}
+
+			var op operation
+
+			err := json.Unmarshal(raw, &op)
+			if err != nil {
+				return nil, errors.Wrapf(err, "openapimock: decode operation %s %s", strings.ToUpper(method), path)
+			}
+
+			m.routes = append(m.routes, route{
+				segments:  strings.Split(strings.Trim(path, "/"), "/"),
+				method:    strings.ToUpper(method),
+				operation: &op,
+			})
+		}
+	}
+
+	// '{' sorts after the characters of literal segments, so that literal segments take precedence over
+	// templated ones, as required by the specification.
+	slices.SortFunc(m.routes, func(a, b route) int {
+		return strings.Compare(strings.Join(a.segments, "/"), strings.Join(b.segments, "/"))
+	})
+
+	return m, nil
+}
+
+// ServeHTTP answers r with the response of the matching operation: 404 Not Found when no path matches and
+// 405 Method Not Allowed when the path has no operation for the method. The lowest 2xx response, or else
+// the default or lowest response, is returned unless the request has a Prefer header such as
+// "code=404, example=notFound", which selects the response of a status code and a named example.
+// The body is the example of the media type, the first named example, or a value generated from the schema.
+func (m *Mock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op, found := m.match(r)
+	if op == nil {
+		status := http.StatusNotFound
+		if found {
+			status = http.StatusMethodNotAllowed
+		}
+
+		http.Error(w, http.StatusText(status), status)
+
+		return
+	}
+
+	preferences := parsePrefer(r.Header.Values("Prefer"))
+
+	status, resp := selectResponse(op, preferences[preferCode])
+	if resp == nil {
+		w.WriteHeader(status)
+
+		return
+	}
+
+	for name, header := range resp.Headers {
+		value := header.Example
+		if value == nil && header.Schema != nil {
+			value, _ = json.Marshal(m.generate(header.Schema, nil))
+		}
+
+		if value != nil {
+			w.Header().Set(name, textValue(value))
+		}
+	}
+
+	contentType, media := selectMediaType(resp.Content, r.Header.Get("Accept"))
+	if media == nil {
+		w.WriteHeader(status)
+
+		return
+	}
+
+	body, err := m.body(media, preferences[preferExample])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	if !isJSON(contentType) {
+		body = []byte(textValue(body))
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// Do answers httpRequest without a network round trip. It can be passed to webapiclient.WithDoFunc.
+func (m *Mock) Do(httpRequest *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	m.ServeHTTP(recorder, httpRequest)
+
+	httpResponse := recorder.Result()
+	httpResponse.Request = httpRequest
+
+	return httpResponse, nil
+}
+
+// match returns the operation matching the method and path of r, and whether any operation matched the path.
+func (m *Mock) match(r *http.Request) (*operation, bool) {
+	path, ok := strings.CutPrefix(r.URL.Path, m.basePath)
+	if !ok {
+		return nil, false
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	found := false
+
+	for _, route := range m.routes {
+		if !matchSegments(route.segments, segments) {
+			continue
+		}
+
+		found = true
+
+		if route.method == r.Method || (r.Method == http.MethodHead && route.method == http.MethodGet) {
+			return route.operation, true
+		}
+	}
+
+	return nil, found
+}
+
+// body returns the example named name, or else the example of media, its first named example, or
+// a value generated from its schema.
+func (m *Mock) body(media *mediaType, name string) ([]byte, error) {
+	if named, ok := media.Examples[name]; ok && name != "" {
+		return m.exampleValue(named)
+	}
+
+	if media.Example != nil {
+		return media.Example, nil
+	}
+
+	if len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+
+		slices.Sort(names)
+
+		return m.exampleValue(media.Examples[names[0]])
+	}
+
+	body, err := json.Marshal(m.generate(media.Schema, nil))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return body, nil
+}
+
+// exampleValue returns the value of e, resolving a reference to the examples of the components.
+func (m *Mock) exampleValue(e *example) ([]byte, error) {
+	if e.Ref != "" {
+		name, ok := strings.CutPrefix(e.Ref, "#/components/examples/")
+		if !ok || m.document.Components.Examples[name] == nil {
+			return nil, errors.Errorf("openapimock: unresolved example %s", e.Ref)
+		}
+
+		e = m.document.Components.Examples[name]
+	}
+
+	return e.Value, nil
+}
+
+// generate returns a value conforming to s: its example, default, constant or first enumerated value when
+// it has one, and otherwise a value built from its type, properties and items. refs are the references being
+// resolved; a recursive reference generates null.
+func (m *Mock) generate(s *schema, refs []string) any {
+	if s == nil {
+		return nil
+	}
+
+	if s.Ref != "" {
+		if slices.Contains(refs, s.Ref) {
+			return nil
+		}
+
+		name, _ := strings.CutPrefix(s.Ref, "#/components/schemas/")
+
+		return m.generate(m.document.Components.Schemas[name], append(refs, s.Ref))
+	}
+
+	for _, value := range [][]byte{s.Example, s.Const, s.Default} {
+		if value != nil {
+			return json.RawMessage(value)
+		}
+	}
+
+	if len(s.Examples) > 0 {
+		return s.Examples[0]
+	}
+
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+
+	if len(s.AllOf) > 0 {
+		merged := map[string]any{}
+
+		for _, part := range s.AllOf {
+			if object, ok := m.generate(part, refs).(map[string]any); ok {
+				for name, value := range object {
+					merged[name] = value
+				}
+			}
+		}
+
+		return merged
+	}
+
+	if len(s.OneOf) > 0 {
+		return m.generate(s.OneOf[0], refs)
+	}
+
+	if len(s.AnyOf) > 0 {
+		return m.generate(s.AnyOf[0], refs)
+	}
+
+	switch schemaType(s) {
+	case "object":
+		object := make(map[string]any, len(s.Properties))
+		for name, property := range s.Properties {
+			object[name] = m.generate(property, refs)
+		}
+
+		return object
+	case "array":
+		return []any{m.generate(s.Items, refs)}
+	case "string":
+		if value, ok := formatExamples[s.Format]; ok {
+			return value
+		}
+
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	default:
+		return nil
+	}
+}
+
+// schemaType returns the type of s, the first non-null type of an OpenAPI 3.1 type list, or "object" for
+// untyped schemas with properties.
+func schemaType(s *schema) string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, item := range t {
+			if name, ok := item.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+
+	if s.Properties != nil {
+		return "object"
+	}
+
+	return ""
+}
+
+// selectResponse returns the response of the status code code, or else of the lowest 2xx status code,
+// the default response or the response of the lowest status code.
+func selectResponse(op *operation, code string) (int, *response) {
+	if resp, ok := op.Responses[code]; ok && code != "" {
+		status, _ := strconv.Atoi(code)
+
+		return status, resp
+	}
+
+	codes := make([]int, 0, len(op.Responses))
+	for key := range op.Responses {
+		if status, err := strconv.Atoi(key); err == nil {
+			codes = append(codes, status)
+		}
+	}
+
+	slices.Sort(codes)
+
+	for _, status := range codes {
+		if status >= http.StatusOK && status < http.StatusMultipleChoices {
+			return status, op.Responses[strconv.Itoa(status)]
+		}
+	}
+
+	if resp, ok := op.Responses["default"]; ok {
+		return http.StatusOK, resp
+	}
+
+	if len(codes) > 0 {
+		return codes[0], op.Responses[strconv.Itoa(codes[0])]
+	}
+
+	return http.StatusNoContent, nil
+}
+
+// selectMediaType returns the media type accepted by accept, or else application/json or the first media type.
+func selectMediaType(content map[string]*mediaType, accept string) (string, *mediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+
+	for item := range strings.SplitSeq(accept, ",") {
+		contentType, _, _ := strings.Cut(strings.TrimSpace(item), ";")
+		if media, ok := content[contentType]; ok {
+			return contentType, media
+		}
+	}
+
+	if media, ok := content["application/json"]; ok {
+		return "application/json", media
+	}
+
+	contentTypes := make([]string, 0, len(content))
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+
+	slices.Sort(contentTypes)
+
+	return contentTypes[0], content[contentTypes[0]]
+}
+
+// parsePrefer returns the parameters of Prefer header values such as "code=404, example=notFound".
+func parsePrefer(values []string) map[string]string {
+	preferences := map[string]string{}
+
+	for _, value := range values {
+		for item := range strings.SplitSeq(value, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+			preferences[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+
+	return preferences
+}
+
+// matchSegments reports whether the segments of a path match those of a path template.
+func matchSegments(template []string, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+
+	for i, segment := range template {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return false
+			}
+
+			continue
+		}
+
+		if segment != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// textValue returns a JSON value as text, without the quotes of strings.
+func textValue(value json.RawMessage) string {
+	var text string
+	if json.Unmarshal(value, &text) == nil {
+		return text
+	}
+
+	return string(value)
+}
+
+// isJSON reports whether contentType is application/json or a media type with the +json suffix.
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+func isMethod(name string) bool {
+	switch strings.ToUpper(name) {
+	case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+		http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
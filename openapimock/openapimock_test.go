@@ -0,0 +1,255 @@
+package openapimock
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDocument = `{
+  "openapi": "3.1.0",
+  "servers": [{"url": "https://api.example.com/v1"}],
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "responses": {
+          "200": {
+            "headers": {"X-Rate-Limit": {"schema": {"type": "integer", "example": 100}}},
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}
+          },
+          "404": {
+            "content": {
+              "application/problem+json": {
+                "examples": {
+                  "deleted": {"value": {"title": "Deleted"}},
+                  "missing": {"$ref": "#/components/examples/Missing"}
+                }
+              }
+            }
+          }
+        }
+      },
+      "delete": {"responses": {"204": {"description": "Deleted"}}}
+    },
+    "/users/me": {
+      "get": {
+        "responses": {
+          "200": {"content": {"application/json": {"example": {"id": "me", "name": "Me"}}}}
+        }
+      }
+    },
+    "/users": {
+      "get": {
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/User"}}},
+              "text/csv": {"example": "id,name\n1,Jane"}
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "User": {
+        "allOf": [
+          {"type": "object", "properties": {"id": {"type": "string", "format": "uuid"}}},
+          {
+            "properties": {
+              "name": {"type": "string"},
+              "email": {"type": "string", "format": "email"},
+              "age": {"type": ["integer", "null"]},
+              "role": {"type": "string", "enum": ["admin", "member"]},
+              "active": {"type": "boolean", "default": true},
+              "manager": {"$ref": "#/components/schemas/User"}
+            }
+          }
+        ]
+      }
+    },
+    "examples": {
+      "Missing": {"value": {"title": "Not Found"}}
+    }
+  }
+}`
+
+func TestMock_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	mock, err := New([]byte(testDocument))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		headers         map[string]string
+		wantStatus      int
+		wantContentType string
+		wantHeaders     map[string]string
+		wantBody        string
+	}{
+		{
+			name:            "success: value generated from the schema",
+			method:          http.MethodGet,
+			path:            "/v1/users/42",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantHeaders:     map[string]string{"X-Rate-Limit": "100"},
+			wantBody:        `{"active":true,"age":0,"email":"user@example.com","id":"00000000-0000-0000-0000-000000000000","manager":null,"name":"string","role":"admin"}`,
+		},
+		{
+			name:            "success: literal path takes precedence",
+			method:          http.MethodGet,
+			path:            "/v1/users/me",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"id": "me", "name": "Me"}`,
+		},
+		{
+			name:            "success: first named example of the preferred code",
+			method:          http.MethodGet,
+			path:            "/v1/users/42",
+			headers:         map[string]string{"Prefer": "code=404"},
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"title": "Deleted"}`,
+		},
+		{
+			name:            "success: preferred example by reference",
+			method:          http.MethodGet,
+			path:            "/v1/users/42",
+			headers:         map[string]string{"Prefer": `code=404, example="missing"`},
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"title": "Not Found"}`,
+		},
+		{
+			name:            "success: accepted media type",
+			method:          http.MethodGet,
+			path:            "/v1/users",
+			headers:         map[string]string{"Accept": "text/csv"},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv",
+			wantBody:        "id,name\n1,Jane",
+		},
+		{
+			name:       "success: no content",
+			method:     http.MethodDelete,
+			path:       "/v1/users/42",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:            "failure: unknown path",
+			method:          http.MethodGet,
+			path:            "/v1/groups",
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Not Found\n",
+		},
+		{
+			name:            "failure: method not allowed",
+			method:          http.MethodPost,
+			path:            "/v1/users/42",
+			wantStatus:      http.StatusMethodNotAllowed,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Method Not Allowed\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			mock.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+
+			for name, value := range tt.wantHeaders {
+				assert.Equal(t, value, w.Header().Get(name))
+			}
+
+			if tt.wantContentType == "application/json" || tt.wantContentType == "application/problem+json" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			} else {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMock_Do(t *testing.T) {
+	t.Parallel()
+
+	mock, err := New([]byte(testDocument))
+	require.NoError(t, err)
+
+	client := webapiclient.NewClient("https://api.example.com", webapiclient.WithDoFunc(mock.Do))
+
+	response, err := client.Do(context.Background(), &webapiclient.Request{
+		Method:               http.MethodGet,
+		Path:                 "/v1/users/me",
+		ExpectedStatusCodes:  []int{http.StatusOK},
+		ExpectedContentTypes: []string{"application/json"},
+	}, nil)
+	require.NoError(t, err)
+
+	var user struct {
+		ID string `json:"id"`
+	}
+
+	require.NoError(t, response.DecodeJSON(&user))
+	assert.Equal(t, "me", user.ID)
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "success: empty document",
+			data: `{"openapi": "3.0.3"}`,
+		},
+		{
+			name:    "failure: not JSON",
+			data:    "openapi: 3.0.3",
+			wantErr: true,
+		},
+		{
+			name:    "failure: malformed operation",
+			data:    `{"paths": {"/": {"get": []}}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New([]byte(tt.data))
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package webapiclient
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// WithDoFunc makes the client send requests with do instead of http.DefaultClient.
+func WithDoFunc(do DoFunc) Option {
+	return func(c *client) {
+		c.do = do
+		c.httpClient = nil
+	}
+}
+
+// WithHTTPClient makes the client send requests with httpClient instead of http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.do = httpClient.Do
+		c.httpClient = httpClient
+	}
+}
+
+// WithDefaultHeaders sets headers on every request that does not set them itself.
+func WithDefaultHeaders(headers map[string][]string) Option {
+	canonicalHeaders := make(http.Header, len(headers))
+	for key, values := range headers {
+		canonicalHeaders[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	return func(c *client) {
+		if c.defaultHeaders == nil {
+			c.defaultHeaders = http.Header{}
+		}
+
+		for key, values := range canonicalHeaders {
+			c.defaultHeaders[key] = values
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request that does not set it itself.
+func WithUserAgent(userAgent string) Option {
+	return WithDefaultHeaders(map[string][]string{"User-Agent": {userAgent}})
+}
+
+// WithDefaultQuery sets query parameters, such as an API version or key, on every request URL that does not set them itself.
+func WithDefaultQuery(query map[string][]string) Option {
+	clonedQuery := make(url.Values, len(query))
+	for key, values := range query {
+		clonedQuery[key] = append([]string(nil), values...)
+	}
+
+	return func(c *client) {
+		if c.defaultQuery == nil {
+			c.defaultQuery = url.Values{}
+		}
+
+		for key, values := range clonedQuery {
+			c.defaultQuery[key] = values
+		}
+	}
+}
+
+// WithResponseOnError makes Do return the response together with the error when the response has an unexpected
+// status code or content type, instead of closing it, so that callers can read the body of a 409 or 422 to build
+// user-facing messages. The body of the returned response must be closed.
+func WithResponseOnError() Option {
+	return func(c *client) {
+		c.responseOnError = true
+	}
+}
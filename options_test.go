@@ -0,0 +1,200 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDefaultHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options []Option
+		headers map[string][]string
+		want    http.Header
+	}{
+		{
+			name:    "success: default headers are set",
+			options: []Option{WithDefaultHeaders(map[string][]string{"x-api-version": {"2"}})},
+			want:    http.Header{"X-Api-Version": {"2"}},
+		},
+		{
+			name:    "success: request headers take precedence",
+			options: []Option{WithDefaultHeaders(map[string][]string{"X-Api-Version": {"2"}})},
+			headers: map[string][]string{"x-api-version": {"3"}},
+			want:    http.Header{"X-Api-Version": {"3"}},
+		},
+		{
+			name:    "success: user agent",
+			options: []Option{WithUserAgent("example/1.0")},
+			want:    http.Header{"User-Agent": {"example/1.0"}},
+		},
+		{
+			name: "success: later options override earlier ones",
+			options: []Option{
+				WithUserAgent("example/1.0"),
+				WithDefaultHeaders(map[string][]string{"User-Agent": {"example/2.0"}, "Accept": {"application/json"}}),
+			},
+			want: http.Header{"User-Agent": {"example/2.0"}, "Accept": {"application/json"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", tt.options...)
+
+			got, err := client.DryRun(context.Background(), &Request{Method: http.MethodGet, Path: "/test", Headers: tt.headers}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Header)
+		})
+	}
+}
+
+func TestWithDefaultQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options []Option
+		path    string
+		query   map[string][]string
+		want    string
+	}{
+		{
+			name:    "success: default query is appended",
+			options: []Option{WithDefaultQuery(map[string][]string{"api-version": {"2024-01-01"}})},
+			path:    "/test",
+			want:    "http://example.com/test?api-version=2024-01-01",
+		},
+		{
+			name:    "success: merged with the query of the path",
+			options: []Option{WithDefaultQuery(map[string][]string{"api-version": {"2024-01-01"}})},
+			path:    "/test?page=2",
+			want:    "http://example.com/test?api-version=2024-01-01&page=2",
+		},
+		{
+			name:    "success: query of the path takes precedence",
+			options: []Option{WithDefaultQuery(map[string][]string{"api-version": {"2024-01-01"}})},
+			path:    "/test?api-version=2023-01-01",
+			want:    "http://example.com/test?api-version=2023-01-01",
+		},
+		{
+			name: "success: no default query",
+			path: "/test?b=1&a=2",
+			want: "http://example.com/test?b=1&a=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", tt.options...)
+
+			got, err := client.DryRun(context.Background(), &Request{Method: http.MethodGet, Path: tt.path, Query: tt.query}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.URL.String())
+		})
+	}
+}
+
+func TestWithDoFunc(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	do := func(req *http.Request) (*http.Response, error) {
+		calls++
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}
+
+	for _, client := range []Client{
+		NewClient("http://example.com", WithDoFunc(do)),
+		NewClientWithDoFunc(do, "http://example.com"),
+	} {
+		response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	}
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHTTPClient(server.Client()))
+
+	response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
+
+func TestWithResponseOnError(t *testing.T) {
+	t.Parallel()
+
+	do := func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(`{"error":"name is required"}`))),
+		}, nil
+	}
+
+	tests := []struct {
+		name     string
+		options  []Option
+		wantBody bool
+	}{
+		{name: "success: response is closed by default", options: []Option{WithDoFunc(do)}},
+		{name: "success: response is returned", options: []Option{WithDoFunc(do), WithResponseOnError()}, wantBody: true},
+		{
+			name:     "success: response is returned after capturing an artifact",
+			options:  []Option{WithDoFunc(do), WithResponseOnError(), WithArtifactSink(&memoryArtifactSink{})},
+			wantBody: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", tt.options...)
+
+			response, err := client.Do(context.Background(), &Request{
+				Method:              http.MethodPost,
+				Path:                "/users",
+				ExpectedStatusCodes: []int{http.StatusCreated},
+			}, nil)
+			require.Error(t, err)
+			assert.Equal(t, CategoryHTTPStatus, ClassifyError(err))
+
+			if !tt.wantBody {
+				assert.Nil(t, response)
+
+				return
+			}
+
+			require.NotNil(t, response)
+			assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+
+			body, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"error":"name is required"}`, string(body))
+		})
+	}
+}
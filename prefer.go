@@ -0,0 +1,84 @@
+package webapiclient
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preference is a single preference of the Prefer header defined in RFC 7240.
+type Preference struct {
+	Name  string
+	Value string
+}
+
+// String returns the preference formatted as it appears in the Prefer header.
+func (p Preference) String() string {
+	if p.Value == "" {
+		return p.Name
+	}
+
+	if isToken(p.Value) {
+		return p.Name + "=" + p.Value
+	}
+
+	return p.Name + "=" + strconv.Quote(p.Value)
+}
+
+// PreferReturnMinimal asks the server to return a minimal response.
+func PreferReturnMinimal() Preference {
+	return Preference{Name: "return", Value: "minimal"}
+}
+
+// PreferReturnRepresentation asks the server to return the full representation of the resource.
+func PreferReturnRepresentation() Preference {
+	return Preference{Name: "return", Value: "representation"}
+}
+
+// PreferRespondAsync asks the server to process the request asynchronously, typically answering 202 Accepted.
+func PreferRespondAsync() Preference {
+	return Preference{Name: "respond-async"}
+}
+
+// PreferWait asks the server to respond within d, in whole seconds.
+func PreferWait(d time.Duration) Preference {
+	return Preference{Name: "wait", Value: strconv.FormatInt(int64(d/time.Second), 10)}
+}
+
+// FormatPrefer returns the value of a Prefer header expressing preferences.
+func FormatPrefer(preferences ...Preference) string {
+	values := make([]string, 0, len(preferences))
+	for _, preference := range preferences {
+		values = append(values, preference.String())
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// PreferencesApplied returns the preferences the server reported as applied in the
+// Preference-Applied header, keyed by lower-cased preference name.
+func PreferencesApplied(response *Response) map[string]string {
+	applied := map[string]string{}
+
+	for _, header := range response.Headers["Preference-Applied"] {
+		for _, preference := range strings.Split(header, ",") {
+			preference, _, _ = strings.Cut(preference, ";")
+
+			name, value, _ := strings.Cut(preference, "=")
+
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+
+			value = strings.TrimSpace(value)
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+
+			applied[name] = value
+		}
+	}
+
+	return applied
+}
@@ -0,0 +1,79 @@
+package webapiclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPrefer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		preferences []Preference
+		want        string
+	}{
+		{
+			name:        "success: single preference",
+			preferences: []Preference{PreferReturnMinimal()},
+			want:        "return=minimal",
+		},
+		{
+			name:        "success: multiple preferences",
+			preferences: []Preference{PreferRespondAsync(), PreferWait(10 * time.Second), PreferReturnRepresentation()},
+			want:        "respond-async, wait=10, return=representation",
+		},
+		{
+			name:        "success: quoted value",
+			preferences: []Preference{{Name: "handling", Value: "lenient mode"}},
+			want:        `handling="lenient mode"`,
+		},
+		{
+			name:        "success: no preferences",
+			preferences: nil,
+			want:        "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, FormatPrefer(tt.preferences...))
+		})
+	}
+}
+
+func TestPreferencesApplied(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		want    map[string]string
+	}{
+		{
+			name:    "success: single preference",
+			headers: map[string][]string{"Preference-Applied": {"return=minimal"}},
+			want:    map[string]string{"return": "minimal"},
+		},
+		{
+			name:    "success: multiple headers and values",
+			headers: map[string][]string{"Preference-Applied": {"Respond-Async, wait=10", `handling="lenient"; x=1`}},
+			want:    map[string]string{"respond-async": "", "wait": "10", "handling": "lenient"},
+		},
+		{
+			name:    "success: no header",
+			headers: map[string][]string{},
+			want:    map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, PreferencesApplied(&Response{Headers: tt.headers}))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package webapiclient
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// problemDetailsMediaType is the media type of RFC 7807 problem details.
+const problemDetailsMediaType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 (RFC 9457) problem details object. It is decoded from the body of responses
+// with an unexpected status code and the Content-Type application/problem+json, unless an error decoder
+// registered with WithErrorDecoder or Request.ErrorInto decodes it, and can be found with errors.As.
+type ProblemDetails struct {
+	// Type is a URI reference identifying the problem type.
+	Type string
+	// Title is a short summary of the problem type.
+	Title string
+	// Status is the status code set by the server.
+	Status int
+	// Detail is an explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance is a URI reference identifying this occurrence of the problem.
+	Instance string
+	// Extensions are the other members of the object, such as "errors" or "traceId".
+	Extensions map[string]json.RawMessage
+}
+
+// Error returns the title, followed by the detail when there is one.
+func (p *ProblemDetails) Error() string {
+	title := p.Title
+	if title == "" {
+		title = "problem"
+		if p.Type != "" && p.Type != "about:blank" {
+			title += " " + p.Type
+		}
+
+		if p.Status != 0 {
+			title += " (status " + strconv.Itoa(p.Status) + ")"
+		}
+	}
+
+	if p.Detail == "" {
+		return title
+	}
+
+	return title + ": " + p.Detail
+}
+
+// UnmarshalJSON decodes a problem details object. Standard members with a value of the wrong type are ignored,
+// as required by the RFC, and every other member is kept in Extensions.
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &members)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	*p = ProblemDetails{}
+
+	for name, value := range members {
+		switch name {
+		case "type":
+			_ = json.Unmarshal(value, &p.Type)
+		case "title":
+			_ = json.Unmarshal(value, &p.Title)
+		case "status":
+			_ = json.Unmarshal(value, &p.Status)
+		case "detail":
+			_ = json.Unmarshal(value, &p.Detail)
+		case "instance":
+			_ = json.Unmarshal(value, &p.Instance)
+		default:
+			if p.Extensions == nil {
+				p.Extensions = map[string]json.RawMessage{}
+			}
+
+			p.Extensions[name] = value
+		}
+	}
+
+	return nil
+}
+
+// DecodeExtension decodes the extension member name into v, and reports whether the member is present.
+func (p *ProblemDetails) DecodeExtension(name string, v any) (bool, error) {
+	value, ok := p.Extensions[name]
+	if !ok {
+		return false, nil
+	}
+
+	err := json.Unmarshal(value, v)
+	if err != nil {
+		return true, errors.Wrapf(err, "problem extension %q", name)
+	}
+
+	return true, nil
+}
+
+// ProblemDetailsDecoder is an ErrorDecoderFunc decoding a body into a *ProblemDetails.
+// Bodies that are not JSON objects are not decoded.
+func ProblemDetailsDecoder(_ int, body []byte) error {
+	var problem ProblemDetails
+
+	err := json.Unmarshal(body, &problem)
+	if err != nil {
+		return nil
+	}
+
+	return &problem
+}
+
+// isProblemDetails reports whether contentType is the media type of problem details.
+func isProblemDetails(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+
+	return strings.EqualFold(strings.TrimSpace(mediaType), problemDetailsMediaType)
+}
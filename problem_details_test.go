@@ -0,0 +1,173 @@
+package webapiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetails_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    ProblemDetails
+		wantErr bool
+	}{
+		{
+			name: "success: standard and extension members",
+			data: `{
+				"type": "https://example.com/probs/out-of-credit",
+				"title": "You do not have enough credit.",
+				"status": 403,
+				"detail": "Your current balance is 30, but that costs 50.",
+				"instance": "/account/12345/msgs/abc",
+				"balance": 30,
+				"accounts": ["/account/12345", "/account/67890"]
+			}`,
+			want: ProblemDetails{
+				Type:     "https://example.com/probs/out-of-credit",
+				Title:    "You do not have enough credit.",
+				Status:   http.StatusForbidden,
+				Detail:   "Your current balance is 30, but that costs 50.",
+				Instance: "/account/12345/msgs/abc",
+				Extensions: map[string]json.RawMessage{
+					"balance":  json.RawMessage(`30`),
+					"accounts": json.RawMessage(`["/account/12345", "/account/67890"]`),
+				},
+			},
+		},
+		{
+			name: "success: members of the wrong type are ignored",
+			data: `{"title": 42, "status": "403", "detail": "denied"}`,
+			want: ProblemDetails{Detail: "denied"},
+		},
+		{
+			name:    "failure: not an object",
+			data:    `["problem"]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got ProblemDetails
+
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProblemDetails_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		problem *ProblemDetails
+		want    string
+	}{
+		{name: "success: title and detail", problem: &ProblemDetails{Title: "Forbidden", Detail: "denied"}, want: "Forbidden: denied"},
+		{name: "success: title", problem: &ProblemDetails{Title: "Forbidden"}, want: "Forbidden"},
+		{name: "success: type and status", problem: &ProblemDetails{Type: "urn:x", Status: 409}, want: "problem urn:x (status 409)"},
+		{name: "success: blank type", problem: &ProblemDetails{Type: "about:blank"}, want: "problem"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.problem.Error())
+		})
+	}
+}
+
+func TestProblemDetails_DecodeExtension(t *testing.T) {
+	t.Parallel()
+
+	problem := &ProblemDetails{Extensions: map[string]json.RawMessage{"balance": json.RawMessage(`30`)}}
+
+	var balance int
+
+	ok, err := problem.DecodeExtension("balance", &balance)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 30, balance)
+
+	ok, err = problem.DecodeExtension("missing", &balance)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	var name string
+
+	_, err = problem.DecodeExtension("balance", &name)
+	require.Error(t, err)
+}
+
+func TestClientImpl_Do_ProblemDetails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		options     []Option
+		contentType string
+		wantProblem bool
+	}{
+		{
+			name:        "success: decoded by default",
+			contentType: "application/problem+json; charset=utf-8",
+			wantProblem: true,
+		},
+		{
+			name:        "success: registered decoder takes precedence",
+			options:     []Option{WithErrorDecoder("+json", JSONErrorDecoder[vendorError]())},
+			contentType: "application/problem+json",
+		},
+		{
+			name:        "success: other content type",
+			contentType: "application/json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", append([]Option{WithDoFunc(func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusConflict,
+					Header:     http.Header{"Content-Type": {tt.contentType}},
+					Body:       io.NopCloser(strings.NewReader(`{"title":"Conflict","status":409,"code":"E1"}`)),
+				}, nil
+			})}, tt.options...)...)
+
+			_, err := client.Do(context.Background(), &Request{Path: "/", ExpectedStatusCodes: []int{http.StatusOK}}, nil)
+			require.ErrorIs(t, err, ErrUnexpectedStatusCode)
+
+			var problem *ProblemDetails
+			if !tt.wantProblem {
+				assert.False(t, errors.As(err, &problem))
+
+				return
+			}
+
+			require.ErrorAs(t, err, &problem)
+			assert.Equal(t, "Conflict", problem.Title)
+			assert.Equal(t, json.RawMessage(`"E1"`), problem.Extensions["code"])
+		})
+	}
+}
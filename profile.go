@@ -0,0 +1,140 @@
+package webapiclient
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Route is an entry of a profile allowlist.
+type Route struct {
+	// Method is the allowed method, or empty for any method.
+	Method string
+	// Path is a path.Match pattern of the allowed paths; a trailing "/**" matches any path below the prefix.
+	Path string
+}
+
+// Profile bundles the settings of one vendor integration and guards it against calls to other endpoints.
+type Profile struct {
+	// Name identifies the profile in errors.
+	Name string
+	// BaseURL is the base URL of the vendor API; requests to other origins are rejected.
+	BaseURL string
+	// Authorize, which may be nil, adds the credentials of the vendor to every request.
+	Authorize EditRequestFunc
+	// RateLimiter, which may be nil, paces the requests of the profile.
+	RateLimiter RateLimiter
+	// Routes is the allowlist of methods and paths; requests matching no route are rejected.
+	Routes []Route
+}
+
+// ProfileViolationError is returned for requests outside the profile of the client.
+type ProfileViolationError struct {
+	Profile string
+	Method  string
+	URL     string
+}
+
+// Error returns a message describing the rejected request.
+func (e *ProfileViolationError) Error() string {
+	return "request outside profile " + e.Profile + ": " + e.Method + " " + e.URL
+}
+
+// NewProfileClient creates a new client for profile, rejecting requests to other origins or outside its routes
+// with a *ProfileViolationError before they are sent.
+func NewProfileClient(profile Profile, options ...Option) Client {
+	return NewClient(profile.BaseURL, append([]Option{withProfile(profile)}, options...)...)
+}
+
+func withProfile(profile Profile) Option {
+	return func(c *client) {
+		c.profile = &profile
+
+		// The base URL is parsed once here; a malformed one fails every request like the base URL of the client.
+		baseURL, err := url.Parse(profile.BaseURL)
+		if err != nil {
+			c.baseURLErr = errors.WithStack(err)
+		}
+
+		c.profileBaseURL = baseURL
+
+		if profile.RateLimiter != nil {
+			WithRateLimiter(profile.RateLimiter)(c)
+		}
+	}
+}
+
+// checkProfile authorizes httpRequest for the profile, and returns a *ProfileViolationError when it is outside it.
+func (c *client) checkProfile(httpRequest *http.Request) error {
+	if c.profile == nil {
+		return nil
+	}
+
+	violation := &ProfileViolationError{
+		Profile: c.profile.Name,
+		Method:  httpRequest.Method,
+		URL:     httpRequest.URL.Redacted(),
+	}
+
+	baseURL := c.profileBaseURL
+	if baseURL == nil || !strings.EqualFold(httpRequest.URL.Scheme, baseURL.Scheme) ||
+		!strings.EqualFold(httpRequest.URL.Host, baseURL.Host) {
+		return violation
+	}
+
+	if !isCanonicalPath(httpRequest.URL) || !c.profile.allows(httpRequest.Method, httpRequest.URL.Path) {
+		return violation
+	}
+
+	if c.profile.Authorize != nil {
+		err := c.profile.Authorize(httpRequest)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Profile) allows(method string, requestPath string) bool {
+	for _, route := range p.Routes {
+		if route.Method != "" && !strings.EqualFold(route.Method, method) {
+			continue
+		}
+
+		if prefix, ok := strings.CutSuffix(route.Path, "/**"); ok {
+			if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+				return true
+			}
+
+			continue
+		}
+
+		if matched, err := path.Match(route.Path, requestPath); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCanonicalPath reports whether the path of requestURL has no dot segments and no encoded slashes or backslashes.
+// Servers resolve such paths, "/v1/%2e%2e/admin" into "/admin" for example, so matching them against the routes
+// could let requests escape the allowlist.
+func isCanonicalPath(requestURL *url.URL) bool {
+	escapedPath := strings.ToLower(requestURL.EscapedPath())
+	if strings.Contains(escapedPath, "%2f") || strings.Contains(escapedPath, "%5c") {
+		return false
+	}
+
+	for segment := range strings.SplitSeq(requestURL.Path, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	return true
+}
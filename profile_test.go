@@ -0,0 +1,120 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProfileClient(t *testing.T) {
+	t.Parallel()
+
+	profile := Profile{
+		Name:    "billing",
+		BaseURL: "https://billing.example.com/v1/",
+		Authorize: func(httpRequest *http.Request) error {
+			httpRequest.Header.Set("Authorization", "Bearer billing-token")
+
+			return nil
+		},
+		Routes: []Route{
+			{Method: http.MethodGet, Path: "/v1/invoices/**"},
+			{Method: http.MethodPost, Path: "/v1/invoices"},
+			{Path: "/v1/customers/*"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   bool
+	}{
+		{name: "success: prefix route", method: http.MethodGet, path: "invoices/42/lines", want: true},
+		{name: "success: prefix route root", method: http.MethodGet, path: "invoices", want: true},
+		{name: "success: exact route", method: http.MethodPost, path: "invoices", want: true},
+		{name: "success: any method", method: http.MethodDelete, path: "customers/7", want: true},
+		{name: "failure: method not allowed", method: http.MethodDelete, path: "invoices/42", want: false},
+		{name: "failure: path not allowed", method: http.MethodGet, path: "payouts", want: false},
+		{name: "failure: pattern depth", method: http.MethodGet, path: "customers/7/cards", want: false},
+		{name: "failure: encoded dot segments", method: http.MethodGet, path: "/v1/invoices/%2e%2e/%2E%2E/admin", want: false},
+		{name: "failure: encoded dot segment", method: http.MethodGet, path: "/v1/invoices/%2e/42", want: false},
+		{name: "failure: encoded slash", method: http.MethodGet, path: "/v1/invoices/..%2f..%2fadmin", want: false},
+		{name: "failure: encoded backslash", method: http.MethodGet, path: "/v1/invoices/..%5c..%5cadmin", want: false},
+		{name: "failure: other origin", method: http.MethodGet, path: "https://crm.example.com/v1/invoices/1", want: false},
+		{name: "failure: other scheme", method: http.MethodGet, path: "http://billing.example.com/v1/invoices/1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sent *http.Request
+			do := func(req *http.Request) (*http.Response, error) {
+				sent = req
+
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+			}
+
+			_, err := NewProfileClient(profile, WithDoFunc(do)).Do(context.Background(), &Request{Method: tt.method, Path: tt.path}, nil)
+			if !tt.want {
+				var violation *ProfileViolationError
+				require.ErrorAs(t, err, &violation)
+				assert.Equal(t, "billing", violation.Profile)
+				assert.Equal(t, CategoryValidation, ClassifyError(err))
+				assert.Nil(t, sent)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "Bearer billing-token", sent.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestNewProfileClient_RateLimiter(t *testing.T) {
+	t.Parallel()
+
+	do := func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}
+	client := NewProfileClient(Profile{
+		Name:        "crm",
+		BaseURL:     "https://crm.example.com",
+		RateLimiter: NewPacer(time.Hour),
+		Routes:      []Route{{Path: "/**"}},
+	}, WithDoFunc(do),
+	)
+
+	_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/contacts"}, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = client.Do(ctx, &Request{Method: http.MethodGet, Path: "/contacts"}, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewProfileClient_MalformedBaseURL(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	do := func(req *http.Request) (*http.Response, error) {
+		calls++
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}
+	client := NewProfileClient(Profile{Name: "crm", BaseURL: "https://crm.example.com/%zz", Routes: []Route{{Path: "/**"}}}, WithDoFunc(do))
+
+	_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "https://crm.example.com/contacts"}, nil)
+	require.Error(t, err)
+	assert.Equal(t, CategoryValidation, ClassifyError(err))
+	assert.Zero(t, calls)
+}
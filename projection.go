@@ -0,0 +1,125 @@
+package webapiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ProjectJSON decodes the values at selected paths of a JSON document into the fields of the struct pointed to by v,
+// flattening deeply enveloped responses. Each field to fill is tagged with the dot-separated path of its value,
+// such as `apipath:"data.attributes.name"`, where numeric segments index arrays, as in "data.items.0.id".
+// Values are decoded with encoding/json into the type of the field. Fields whose path is missing or null are left
+// unchanged, and untagged fields of embedded structs are filled as if they were fields of the outer struct.
+func ProjectJSON(data []byte, v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("apipath: %T is not a pointer to a struct", v)
+	}
+
+	var document json.RawMessage
+
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return projectStruct(document, value.Elem())
+}
+
+// DecodeProjection reads the body of the response, closes it, and decodes the values at the paths tagged
+// on the fields of v, as described by ProjectJSON.
+// A body that cannot be decoded is reported with a *DecodeError classified as CategoryDecode.
+func (r *Response) DecodeProjection(v any) error {
+	return r.decode(v, ProjectJSON)
+}
+
+func projectStruct(document json.RawMessage, value reflect.Value) error {
+	valueType := value.Type()
+
+	for i := range valueType.NumField() {
+		field := valueType.Field(i)
+
+		path, tagged := field.Tag.Lookup("apipath")
+		if !tagged {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				err := projectStruct(document, value.Field(i))
+				if err != nil {
+					return errors.WithStack(err)
+				}
+			}
+
+			continue
+		}
+
+		if !field.IsExported() || path == "" || path == "-" {
+			continue
+		}
+
+		raw, err := lookupJSONPath(document, path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if raw == nil {
+			continue
+		}
+
+		err = json.Unmarshal(raw, value.Field(i).Addr().Interface())
+		if err != nil {
+			return errors.Wrapf(err, "apipath %q", path)
+		}
+	}
+
+	return nil
+}
+
+// lookupJSONPath returns the value at path in document, or nil when it is missing or null.
+func lookupJSONPath(document json.RawMessage, path string) (json.RawMessage, error) {
+	current := document
+
+	for segment := range strings.SplitSeq(path, ".") {
+		switch trimmed := bytes.TrimSpace(current); {
+		case len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")):
+			return nil, nil
+		case trimmed[0] == '{':
+			var object map[string]json.RawMessage
+
+			err := json.Unmarshal(trimmed, &object)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			current = object[segment]
+		case trimmed[0] == '[':
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, errors.Errorf("apipath %q: %q does not index an array", path, segment)
+			}
+
+			var array []json.RawMessage
+
+			err = json.Unmarshal(trimmed, &array)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			current = nil
+			if index >= 0 && index < len(array) {
+				current = array[index]
+			}
+		default:
+			return nil, errors.Errorf("apipath %q: %q is not in an object or array", path, segment)
+		}
+	}
+
+	if trimmed := bytes.TrimSpace(current); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+
+	return current, nil
+}
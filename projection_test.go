@@ -0,0 +1,130 @@
+package webapiclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type projectedMeta struct {
+	Total int `apipath:"meta.total"`
+}
+
+type projectedUser struct {
+	projectedMeta
+
+	ID        string    `apipath:"data.id"`
+	Name      string    `apipath:"data.attributes.name"`
+	Tags      []string  `apipath:"data.attributes.tags"`
+	CreatedAt time.Time `apipath:"data.attributes.created_at"`
+	FirstRole string    `apipath:"data.relationships.roles.0.name"`
+	Missing   string    `apipath:"data.attributes.missing"`
+	Nickname  *string   `apipath:"data.attributes.nickname"`
+	Ignored   string
+}
+
+func TestProjectJSON(t *testing.T) {
+	t.Parallel()
+
+	document := `{
+  "data": {
+    "id": "42",
+    "attributes": {"name": "Jane", "tags": ["a", "b"], "created_at": "2026-01-02T03:04:05Z", "nickname": null},
+    "relationships": {"roles": [{"name": "admin"}, {"name": "editor"}]}
+  },
+  "meta": {"total": 1}
+}`
+
+	tests := []struct {
+		name    string
+		data    string
+		v       any
+		want    any
+		wantErr bool
+	}{
+		{
+			name: "success: projected fields",
+			data: document,
+			v:    &projectedUser{Missing: "default", Ignored: "kept"},
+			want: &projectedUser{
+				projectedMeta: projectedMeta{Total: 1},
+				ID:            "42",
+				Name:          "Jane",
+				Tags:          []string{"a", "b"},
+				CreatedAt:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				FirstRole:     "admin",
+				Missing:       "default",
+				Ignored:       "kept",
+			},
+		},
+		{
+			name: "success: null envelope",
+			data: `{"data": null}`,
+			v:    &projectedUser{},
+			want: &projectedUser{},
+		},
+		{
+			name:    "failure: type mismatch",
+			data:    `{"data": {"id": 42}}`,
+			v:       &projectedUser{},
+			wantErr: true,
+		},
+		{
+			name:    "failure: path through a scalar",
+			data:    `{"data": "42"}`,
+			v:       &projectedUser{},
+			wantErr: true,
+		},
+		{
+			name:    "failure: not a pointer to a struct",
+			data:    document,
+			v:       projectedUser{},
+			wantErr: true,
+		},
+		{
+			name:    "failure: invalid JSON",
+			data:    `{"data":`,
+			v:       &projectedUser{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ProjectJSON([]byte(tt.data), tt.v)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tt.v)
+		})
+	}
+}
+
+func TestResponse_DecodeProjection(t *testing.T) {
+	t.Parallel()
+
+	response := &Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"data": {"id": "1", "attributes": {"name": "Jane"}}}`)),
+	}
+
+	var user projectedUser
+	require.NoError(t, response.DecodeProjection(&user))
+	assert.Equal(t, projectedUser{ID: "1", Name: "Jane"}, user)
+
+	response.Body = io.NopCloser(strings.NewReader(`{"data": {"id": 1}}`))
+
+	err := response.DecodeProjection(&user)
+	require.Error(t, err)
+	assert.Equal(t, CategoryDecode, ClassifyError(err))
+}
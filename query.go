@@ -0,0 +1,188 @@
+package webapiclient
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// EncodeQuery encodes the fields of the struct v, or of the struct v points to, tagged with `query:"name"`
+// into query parameters, for use as Request.Query. Untagged fields are skipped, except embedded structs
+// whose fields are encoded as if they belonged to v.
+//
+// The tag option "omitempty" skips zero values. Nil pointers are always skipped, so pointers can express
+// optional values. Slices and arrays are encoded as repeated parameters. time.Time is formatted with the
+// layout of the `layout:"..."` tag, time.RFC3339 by default, and types implementing encoding.TextMarshaler
+// with their MarshalText method. Strings, booleans and numbers are formatted as with strconv.
+func EncodeQuery(v any) (url.Values, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return url.Values{}, nil
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, errors.Errorf("query: cannot encode %s, a struct is required", value.Kind())
+	}
+
+	query := url.Values{}
+
+	err := encodeQueryStruct(query, value)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return query, nil
+}
+
+func encodeQueryStruct(query url.Values, value reflect.Value) error {
+	valueType := value.Type()
+
+	for i := range valueType.NumField() {
+		field := valueType.Field(i)
+
+		tag, tagged := field.Tag.Lookup("query")
+		if !tagged {
+			if field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct {
+				embedded := indirect(value.Field(i))
+				if !embedded.IsValid() {
+					continue
+				}
+
+				err := encodeQueryStruct(query, embedded)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+			}
+
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldValue := value.Field(i)
+		if options == "omitempty" && fieldValue.IsZero() {
+			continue
+		}
+
+		values, err := formatQueryValues(fieldValue, field.Tag.Get("layout"))
+		if err != nil {
+			return errors.Wrapf(err, "query: field %s", field.Name)
+		}
+
+		if len(values) > 0 {
+			query[name] = append(query[name], values...)
+		}
+	}
+
+	return nil
+}
+
+func formatQueryValues(value reflect.Value, layout string) ([]string, error) {
+	value = indirect(value)
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		values := make([]string, 0, value.Len())
+		for i := range value.Len() {
+			elem := indirect(value.Index(i))
+			if !elem.IsValid() {
+				continue
+			}
+
+			formatted, err := formatQueryValue(elem, layout)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			values = append(values, formatted)
+		}
+
+		return values, nil
+	}
+
+	formatted, err := formatQueryValue(value, layout)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return []string{formatted}, nil
+}
+
+func formatQueryValue(value reflect.Value, layout string) (string, error) {
+	if value.Type() == timeType {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+
+		return value.Interface().(time.Time).Format(layout), nil //nolint:forcetypeassert
+	}
+
+	if value.Type().Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText() //nolint:forcetypeassert
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		return string(text), nil
+	}
+
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()), nil
+	default:
+		return "", errors.Errorf("unsupported type %s", value.Type())
+	}
+}
+
+// indirect follows pointers from value, returning the zero Value when it reaches a nil pointer.
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+
+		value = value.Elem()
+	}
+
+	return value
+}
+
+// indirectType returns the type pointers of t point to.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
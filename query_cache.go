@@ -0,0 +1,277 @@
+package webapiclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultQueryCacheEntries is the maximum number of responses kept by a QueryCache.
+const defaultQueryCacheEntries = 1024
+
+// QueryCache caches successful responses of POST endpoints that tunnel reads, such as GraphQL or search,
+// keyed by URL and a hash of the request body. A response is only served for requests matching the
+// request headers named by its Vary header and the Authorization header of the request it was stored for,
+// so that variants do not leak between locales or users. Responses with "Vary: *" are not cached.
+// At most 1024 responses are kept; the ones expiring first are dropped to make room for new ones.
+type QueryCache struct {
+	ttl        time.Duration
+	endpoints  []string
+	invalidate func(httpRequest *http.Request) bool
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string][]*queryCacheEntry
+}
+
+type queryCacheEntry struct {
+	path     string
+	response *http.Response
+	body     []byte
+	expires  time.Time
+	// vary are the request headers selecting the variant, and variant is the hash of their values.
+	vary    []string
+	variant string
+}
+
+// NewQueryCache creates a new QueryCache keeping responses of POST requests to the endpoint paths for ttl.
+// invalidate, which may be nil, is called for every other successful request and drops all entries when it returns true,
+// for example on writes that change the results of queries.
+func NewQueryCache(ttl time.Duration, endpoints []string, invalidate func(httpRequest *http.Request) bool) *QueryCache {
+	return &QueryCache{
+		ttl:        ttl,
+		endpoints:  endpoints,
+		invalidate: invalidate,
+		maxEntries: defaultQueryCacheEntries,
+		entries:    map[string][]*queryCacheEntry{},
+	}
+}
+
+// Invalidate drops the entries of the endpoint path.
+func (c *QueryCache) Invalidate(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, variants := range c.entries {
+		if variants[0].path == path {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// InvalidateAll drops all entries.
+func (c *QueryCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
+
+// Wrap wraps do so that responses of the designated POST endpoints are served from the cache while fresh.
+// Each caller receives its own copy of the cached body.
+func (c *QueryCache) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		if httpRequest.Method != http.MethodPost || !slices.Contains(c.endpoints, httpRequest.URL.Path) {
+			return c.passThrough(do, httpRequest)
+		}
+
+		// The body is buffered into a clone so that the request received is not modified.
+		httpRequest = httpRequest.Clone(httpRequest.Context())
+
+		requestBody, err := readBodyPreserving(httpRequest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		digest := sha256.Sum256(requestBody)
+		key := httpRequest.URL.String() + " " + hex.EncodeToString(digest[:])
+
+		if entry, ok := c.lookup(key, httpRequest, time.Now()); ok {
+			PublishEvent(httpRequest.Context(), CacheHit{URL: sanitizeURL(httpRequest.URL)})
+
+			return entry.copyResponse(httpRequest), nil
+		}
+
+		httpResponse, err := do(httpRequest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		vary, cacheable := varyHeaders(httpResponse.Header)
+		if !cacheable || httpResponse.StatusCode < http.StatusOK || httpResponse.StatusCode >= http.StatusMultipleChoices {
+			return httpResponse, nil
+		}
+
+		defer func() {
+			_ = httpResponse.Body.Close()
+		}()
+
+		body, err := io.ReadAll(httpResponse.Body)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		entry := &queryCacheEntry{
+			path:     httpRequest.URL.Path,
+			response: httpResponse,
+			body:     body,
+			expires:  time.Now().Add(c.ttl),
+			vary:     vary,
+			variant:  variantOf(httpRequest, vary),
+		}
+
+		c.store(key, entry, time.Now())
+
+		return entry.copyResponse(httpRequest), nil
+	}
+}
+
+func (c *QueryCache) passThrough(do DoFunc, httpRequest *http.Request) (*http.Response, error) {
+	httpResponse, err := do(httpRequest)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if c.invalidate != nil &&
+		httpResponse.StatusCode >= http.StatusOK && httpResponse.StatusCode < http.StatusMultipleChoices &&
+		c.invalidate(httpRequest) {
+		c.InvalidateAll()
+	}
+
+	return httpResponse, nil
+}
+
+// lookup returns the fresh entry of key for the variant selected by httpRequest, dropping expired entries.
+func (c *QueryCache) lookup(key string, httpRequest *http.Request, now time.Time) (*queryCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	variants := slices.DeleteFunc(c.entries[key], func(entry *queryCacheEntry) bool {
+		return !now.Before(entry.expires)
+	})
+	if len(variants) == 0 {
+		delete(c.entries, key)
+
+		return nil, false
+	}
+
+	c.entries[key] = variants
+
+	for _, entry := range variants {
+		if entry.variant == variantOf(httpRequest, entry.vary) {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
+
+// store adds entry to the variants of key, replacing the entry of the same variant. Expired entries are dropped,
+// and so are the entries expiring first when the cache is full.
+func (c *QueryCache) store(key string, entry *queryCacheEntry, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = slices.DeleteFunc(c.entries[key], func(cached *queryCacheEntry) bool {
+		return slices.Equal(cached.vary, entry.vary) && cached.variant == entry.variant
+	})
+
+	size := 0
+
+	for cachedKey, variants := range c.entries {
+		variants = slices.DeleteFunc(variants, func(cached *queryCacheEntry) bool {
+			return !now.Before(cached.expires)
+		})
+		if len(variants) == 0 {
+			delete(c.entries, cachedKey)
+
+			continue
+		}
+
+		c.entries[cachedKey] = variants
+		size += len(variants)
+	}
+
+	for ; size >= c.maxEntries && size > 0; size-- {
+		c.evictOldest()
+	}
+
+	c.entries[key] = append(c.entries[key], entry)
+}
+
+// evictOldest drops the entry expiring first.
+func (c *QueryCache) evictOldest() {
+	var (
+		oldestKey   string
+		oldestIndex int
+		oldest      *queryCacheEntry
+	)
+
+	for key, variants := range c.entries {
+		for i, entry := range variants {
+			if oldest == nil || entry.expires.Before(oldest.expires) {
+				oldestKey, oldestIndex, oldest = key, i, entry
+			}
+		}
+	}
+
+	variants := slices.Delete(c.entries[oldestKey], oldestIndex, oldestIndex+1)
+	if len(variants) == 0 {
+		delete(c.entries, oldestKey)
+
+		return
+	}
+
+	c.entries[oldestKey] = variants
+}
+
+// varyHeaders returns the sorted request headers named by the Vary header of a response and Authorization,
+// and false when the response varies on everything.
+func varyHeaders(header http.Header) ([]string, bool) {
+	vary := []string{"Authorization"}
+
+	for _, value := range header.Values("Vary") {
+		for name := range strings.SplitSeq(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return nil, false
+			}
+
+			if name != "" && !slices.Contains(vary, name) {
+				vary = append(vary, name)
+			}
+		}
+	}
+
+	slices.Sort(vary)
+
+	return vary, true
+}
+
+// variantOf returns a hash of the values of the headers of httpRequest named by vary.
+// Values are hashed so that credentials are not kept in the cache.
+func variantOf(httpRequest *http.Request, vary []string) string {
+	hash := sha256.New()
+	for _, name := range vary {
+		_, _ = io.WriteString(hash, name+":"+strings.Join(httpRequest.Header.Values(name), ",")+"\n")
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (e *queryCacheEntry) copyResponse(httpRequest *http.Request) *http.Response {
+	httpResponse := *e.response
+	httpResponse.Header = e.response.Header.Clone()
+	httpResponse.Body = io.NopCloser(bytes.NewReader(e.body))
+	httpResponse.Request = httpRequest
+
+	return &httpResponse
+}
@@ -0,0 +1,234 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCache_Wrap(t *testing.T) {
+	t.Parallel()
+
+	newDo := func(calls *int, status int) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			*calls++
+
+			body := ""
+			if req.Body != nil {
+				b, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				body = string(b)
+			}
+
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"X-Call": []string{strconv.Itoa(*calls)}},
+				Body:       io.NopCloser(strings.NewReader("result of " + body)),
+			}, nil
+		}
+	}
+
+	send := func(t *testing.T, do DoFunc, method, path, body string) (string, string) {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(context.Background(), method, "http://example.com"+path, strings.NewReader(body))
+		require.NoError(t, err)
+
+		resp, err := do(req)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		got, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return string(got), resp.Header.Get("X-Call")
+	}
+
+	t.Run("success: same body is served from cache", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := NewQueryCache(time.Hour, []string{"/graphql"}, nil).Wrap(newDo(&calls, http.StatusOK))
+
+		body, call := send(t, do, http.MethodPost, "/graphql", "{a}")
+		assert.Equal(t, "result of {a}", body)
+		assert.Equal(t, "1", call)
+
+		body, call = send(t, do, http.MethodPost, "/graphql", "{a}")
+		assert.Equal(t, "result of {a}", body)
+		assert.Equal(t, "1", call)
+
+		_, call = send(t, do, http.MethodPost, "/graphql", "{b}")
+		assert.Equal(t, "2", call)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("success: other endpoints are not cached", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := NewQueryCache(time.Hour, []string{"/graphql"}, nil).Wrap(newDo(&calls, http.StatusOK))
+
+		send(t, do, http.MethodPost, "/orders", "{a}")
+		send(t, do, http.MethodPost, "/orders", "{a}")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("success: errors are not cached", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := NewQueryCache(time.Hour, []string{"/graphql"}, nil).Wrap(newDo(&calls, http.StatusBadGateway))
+
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("success: the request received is not modified", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := NewQueryCache(time.Hour, []string{"/graphql"}, nil).Wrap(newDo(&calls, http.StatusOK))
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com/graphql", io.NopCloser(strings.NewReader("{a}")))
+		require.NoError(t, err)
+
+		body := req.Body
+
+		resp, err := do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		assert.True(t, body == req.Body, "the body is not replaced")
+		assert.Nil(t, req.GetBody)
+	})
+
+	t.Run("success: bounded number of entries", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		cache := NewQueryCache(time.Hour, []string{"/graphql"}, nil)
+		cache.maxEntries = 2
+		do := cache.Wrap(newDo(&calls, http.StatusOK))
+
+		for _, body := range []string{"{a}", "{b}", "{c}"} {
+			send(t, do, http.MethodPost, "/graphql", body)
+		}
+		assert.Len(t, cache.entries, 2)
+
+		send(t, do, http.MethodPost, "/graphql", "{c}")
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		assert.Equal(t, 4, calls, "the entry expiring first was dropped")
+	})
+
+	t.Run("success: expired", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := NewQueryCache(time.Millisecond, []string{"/graphql"}, nil).Wrap(newDo(&calls, http.StatusOK))
+
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		time.Sleep(5 * time.Millisecond)
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("success: invalidation", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		cache := NewQueryCache(time.Hour, []string{"/graphql", "/search"}, func(req *http.Request) bool {
+			return req.Method == http.MethodPut
+		})
+		do := cache.Wrap(newDo(&calls, http.StatusOK))
+
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		send(t, do, http.MethodPost, "/search", "q")
+		cache.Invalidate("/search")
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		send(t, do, http.MethodPost, "/search", "q")
+		assert.Equal(t, 3, calls)
+
+		send(t, do, http.MethodGet, "/items", "")
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		assert.Equal(t, 4, calls)
+
+		send(t, do, http.MethodPut, "/items/1", "x")
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		assert.Equal(t, 6, calls)
+
+		cache.InvalidateAll()
+		send(t, do, http.MethodPost, "/search", "q")
+		assert.Equal(t, 7, calls)
+	})
+	t.Run("success: Vary and Authorization select variants", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := NewQueryCache(time.Hour, []string{"/graphql"}, nil).Wrap(func(req *http.Request) (*http.Response, error) {
+			calls++
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Vary": {"accept-language, Accept"}},
+				Body:       io.NopCloser(strings.NewReader(req.Header.Get("Accept-Language") + " " + req.Header.Get("Authorization"))),
+			}, nil
+		})
+
+		sendWith := func(headers map[string]string) string {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com/graphql", strings.NewReader("{a}"))
+			require.NoError(t, err)
+
+			for name, value := range headers {
+				req.Header.Set(name, value)
+			}
+
+			resp, err := do(req)
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			return string(got)
+		}
+
+		assert.Equal(t, "en Bearer a", sendWith(map[string]string{"Accept-Language": "en", "Authorization": "Bearer a"}))
+		assert.Equal(t, "ja Bearer a", sendWith(map[string]string{"Accept-Language": "ja", "Authorization": "Bearer a"}))
+		assert.Equal(t, "en Bearer b", sendWith(map[string]string{"Accept-Language": "en", "Authorization": "Bearer b"}))
+		assert.Equal(t, 3, calls)
+
+		assert.Equal(t, "en Bearer a", sendWith(map[string]string{"Accept-Language": "en", "Authorization": "Bearer a"}))
+		assert.Equal(t, "ja Bearer a", sendWith(map[string]string{"Accept-Language": "ja", "Authorization": "Bearer a"}))
+		assert.Equal(t, 3, calls)
+
+		sendWith(map[string]string{"Accept-Language": "en", "Authorization": "Bearer a", "Accept": "application/xml"})
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("success: Vary * is not cached", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := NewQueryCache(time.Hour, []string{"/graphql"}, nil).Wrap(func(_ *http.Request) (*http.Response, error) {
+			calls++
+
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Vary": {"*"}}, Body: http.NoBody}, nil
+		})
+
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		send(t, do, http.MethodPost, "/graphql", "{a}")
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,115 @@
+package webapiclient
+
+import (
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pagination struct {
+	Page    int `query:"page,omitempty"`
+	PerPage int `query:"per_page"`
+}
+
+type listFilter struct {
+	pagination
+
+	Query    string     `query:"q,omitempty"`
+	Tags     []string   `query:"tag"`
+	Archived *bool      `query:"archived"`
+	Since    time.Time  `query:"since,omitempty"`
+	Until    *time.Time `query:"until" layout:"2006-01-02"`
+	Score    float64    `query:"score,omitempty"`
+	IDs      []*uint    `query:"id"`
+	Addr     netip.Addr `query:"addr,omitempty"`
+	Ignored  string     `query:"-"`
+	Untagged string
+	Default  string `query:""`
+	internal string `query:"internal"` //nolint:unused
+}
+
+func TestEncodeQuery(t *testing.T) {
+	t.Parallel()
+
+	archived := false
+	until := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	id := uint(7)
+
+	tests := []struct {
+		name    string
+		v       any
+		want    url.Values
+		wantErr bool
+	}{
+		{
+			name: "success: all field kinds",
+			v: &listFilter{
+				pagination: pagination{Page: 2, PerPage: 50},
+				Query:      "a&b",
+				Tags:       []string{"x", "y"},
+				Archived:   &archived,
+				Since:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Until:      &until,
+				Score:      0.5,
+				IDs:        []*uint{&id, nil},
+				Addr:       netip.MustParseAddr("192.0.2.1"),
+				Ignored:    "ignored",
+				Untagged:   "untagged",
+				Default:    "default",
+			},
+			want: url.Values{
+				"page":     {"2"},
+				"per_page": {"50"},
+				"q":        {"a&b"},
+				"tag":      {"x", "y"},
+				"archived": {"false"},
+				"since":    {"2024-01-01T00:00:00Z"},
+				"until":    {"2024-01-31"},
+				"score":    {"0.5"},
+				"id":       {"7"},
+				"addr":     {"192.0.2.1"},
+				"Default":  {"default"},
+			},
+		},
+		{
+			name: "success: zero values",
+			v:    listFilter{},
+			want: url.Values{"per_page": {"0"}, "Default": {""}},
+		},
+		{
+			name: "success: nil pointer",
+			v:    (*listFilter)(nil),
+			want: url.Values{},
+		},
+		{
+			name:    "failure: not a struct",
+			v:       map[string]string{"q": "a"},
+			wantErr: true,
+		},
+		{
+			name: "failure: unsupported field type",
+			v: struct {
+				M map[string]string `query:"m"`
+			}{M: map[string]string{}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := EncodeQuery(tt.v)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
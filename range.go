@@ -0,0 +1,106 @@
+package webapiclient
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RangeBytes returns the value of a Range header requesting the bytes from through to, both inclusive.
+func RangeBytes(from int64, to int64) (string, error) {
+	if from < 0 || to < from {
+		return "", errors.Errorf("invalid byte range: %d-%d", from, to)
+	}
+
+	return "bytes=" + strconv.FormatInt(from, 10) + "-" + strconv.FormatInt(to, 10), nil
+}
+
+// RangeFrom returns the value of a Range header requesting the bytes from offset from to the end.
+func RangeFrom(from int64) (string, error) {
+	if from < 0 {
+		return "", errors.Errorf("invalid byte range start: %d", from)
+	}
+
+	return "bytes=" + strconv.FormatInt(from, 10) + "-", nil
+}
+
+// RangeLast returns the value of a Range header requesting the last n bytes.
+func RangeLast(n int64) (string, error) {
+	if n <= 0 {
+		return "", errors.Errorf("invalid byte range suffix length: %d", n)
+	}
+
+	return "bytes=-" + strconv.FormatInt(n, 10), nil
+}
+
+// ContentRange is the parsed Content-Range header of a 206 Partial Content or 416 Range Not Satisfiable response.
+type ContentRange struct {
+	// Start is the offset of the first byte of the body, or -1 when the range was not satisfiable.
+	Start int64
+	// End is the offset of the last byte of the body, or -1 when the range was not satisfiable.
+	End int64
+	// Size is the complete length of the representation, or -1 when unknown.
+	Size int64
+}
+
+// Satisfied reports whether the response carries a part of the representation.
+func (r ContentRange) Satisfied() bool {
+	return r.Start >= 0
+}
+
+// ParseContentRange parses the Content-Range header of response, such as "bytes 0-499/1234",
+// "bytes 0-499/*" or "bytes */1234".
+func ParseContentRange(response *Response) (ContentRange, error) {
+	value := getHeader(response.Headers, "Content-Range")
+
+	unit, spec, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok || unit != "bytes" {
+		return ContentRange{}, invalidContentRange(value)
+	}
+
+	rangeSpec, sizeSpec, ok := strings.Cut(spec, "/")
+	if !ok {
+		return ContentRange{}, invalidContentRange(value)
+	}
+
+	contentRange := ContentRange{Start: -1, End: -1, Size: -1}
+
+	if sizeSpec != "*" {
+		size, err := strconv.ParseInt(sizeSpec, 10, 64)
+		if err != nil || size < 0 {
+			return ContentRange{}, invalidContentRange(value)
+		}
+
+		contentRange.Size = size
+	}
+
+	if rangeSpec == "*" {
+		if contentRange.Size < 0 {
+			return ContentRange{}, invalidContentRange(value)
+		}
+
+		return contentRange, nil
+	}
+
+	startSpec, endSpec, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return ContentRange{}, invalidContentRange(value)
+	}
+
+	start, startErr := strconv.ParseInt(startSpec, 10, 64)
+	end, endErr := strconv.ParseInt(endSpec, 10, 64)
+
+	if startErr != nil || endErr != nil || start < 0 || end < start || (contentRange.Size >= 0 && end >= contentRange.Size) {
+		return ContentRange{}, invalidContentRange(value)
+	}
+
+	contentRange.Start = start
+	contentRange.End = end
+
+	return contentRange, nil
+}
+
+func invalidContentRange(value string) error {
+	return errors.WithStack(newError(CategoryDecode, errors.Errorf("invalid Content-Range: %q", value)))
+}
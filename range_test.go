@@ -0,0 +1,82 @@
+package webapiclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		got     func() (string, error)
+		want    string
+		wantErr bool
+	}{
+		{name: "success: bytes", got: func() (string, error) { return RangeBytes(0, 499) }, want: "bytes=0-499"},
+		{name: "success: single byte", got: func() (string, error) { return RangeBytes(10, 10) }, want: "bytes=10-10"},
+		{name: "success: from", got: func() (string, error) { return RangeFrom(500) }, want: "bytes=500-"},
+		{name: "success: last", got: func() (string, error) { return RangeLast(100) }, want: "bytes=-100"},
+		{name: "failure: negative start", got: func() (string, error) { return RangeBytes(-1, 10) }, wantErr: true},
+		{name: "failure: end before start", got: func() (string, error) { return RangeBytes(10, 9) }, wantErr: true},
+		{name: "failure: negative from", got: func() (string, error) { return RangeFrom(-1) }, wantErr: true},
+		{name: "failure: zero suffix", got: func() (string, error) { return RangeLast(0) }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.got()
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		value         string
+		want          ContentRange
+		wantSatisfied bool
+		wantErr       bool
+	}{
+		{name: "success: partial content", value: "bytes 0-499/1234", want: ContentRange{Start: 0, End: 499, Size: 1234}, wantSatisfied: true},
+		{name: "success: unknown size", value: "bytes 500-999/*", want: ContentRange{Start: 500, End: 999, Size: -1}, wantSatisfied: true},
+		{name: "success: not satisfiable", value: "bytes */1234", want: ContentRange{Start: -1, End: -1, Size: 1234}},
+		{name: "failure: missing", value: "", wantErr: true},
+		{name: "failure: other unit", value: "items 0-9/100", wantErr: true},
+		{name: "failure: end beyond size", value: "bytes 0-100/100", wantErr: true},
+		{name: "failure: end before start", value: "bytes 9-0/100", wantErr: true},
+		{name: "failure: unknown range and size", value: "bytes */*", wantErr: true},
+		{name: "failure: malformed", value: "bytes abc/100", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseContentRange(&Response{Headers: map[string][]string{"Content-Range": {tt.value}}})
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, CategoryDecode, ClassifyError(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantSatisfied, got.Satisfied())
+		})
+	}
+}
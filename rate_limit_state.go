@@ -0,0 +1,124 @@
+package webapiclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// minEpochReset is the smallest rate limit reset value read as a Unix time rather than a number of seconds.
+const minEpochReset = 1_000_000_000
+
+// rateLimitHeaders are the limit, remaining and reset headers, in order of preference:
+// the IETF RateLimit header fields and the widespread X-RateLimit headers.
+var rateLimitHeaders = [][3]string{
+	{"Ratelimit-Limit", "Ratelimit-Remaining", "Ratelimit-Reset"},
+	{"X-Ratelimit-Limit", "X-Ratelimit-Remaining", "X-Ratelimit-Reset"},
+}
+
+// RateLimitState is the rate limit of a host as last reported by its responses.
+type RateLimitState struct {
+	// Limit is the number of requests allowed in the current window, or -1 when not reported.
+	Limit int
+	// Remaining is the number of requests left in the current window, or -1 when not reported.
+	Remaining int
+	// Reset is the time the window resets, or zero when not reported.
+	Reset time.Time
+	// UpdatedAt is the time of the response the state was read from.
+	UpdatedAt time.Time
+}
+
+// rateLimitStates tracks the RateLimitState of every host.
+type rateLimitStates struct {
+	mu     sync.Mutex
+	states map[string]RateLimitState
+}
+
+// RateLimit returns the rate limit last reported by the responses of host, including its port if any,
+// so that schedulers can plan work without probe requests. It reports false when no response of host
+// carried RateLimit-* or X-RateLimit-* headers.
+func (c *client) RateLimit(host string) (RateLimitState, bool) {
+	c.rateLimits.mu.Lock()
+	defer c.rateLimits.mu.Unlock()
+
+	state, ok := c.rateLimits.states[host]
+
+	return state, ok
+}
+
+// observe wraps do so that the rate limit headers of every response update the state of its host.
+func (s *rateLimitStates) observe(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		httpResponse, err := do(httpRequest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		state, ok := parseRateLimitState(httpResponse.Header, time.Now())
+		if ok {
+			s.mu.Lock()
+			if s.states == nil {
+				s.states = map[string]RateLimitState{}
+			}
+			s.states[httpRequest.URL.Host] = state
+			s.mu.Unlock()
+		}
+
+		return httpResponse, nil
+	}
+}
+
+// parseRateLimitState reads the rate limit headers of a response received at now.
+func parseRateLimitState(header http.Header, now time.Time) (RateLimitState, bool) {
+	for _, names := range rateLimitHeaders {
+		limit, hasLimit := parseRateLimitValue(header.Get(names[0]))
+		remaining, hasRemaining := parseRateLimitValue(header.Get(names[1]))
+		reset, hasReset := parseRateLimitValue(header.Get(names[2]))
+
+		if !hasLimit && !hasRemaining && !hasReset {
+			continue
+		}
+
+		state := RateLimitState{Limit: -1, Remaining: -1, UpdatedAt: now}
+		if hasLimit {
+			state.Limit = limit
+		}
+
+		if hasRemaining {
+			state.Remaining = remaining
+		}
+
+		switch {
+		case !hasReset:
+		case reset >= minEpochReset:
+			state.Reset = time.Unix(int64(reset), 0)
+		default:
+			state.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+
+		return state, true
+	}
+
+	return RateLimitState{}, false
+}
+
+// parseRateLimitValue parses the leading integer of a rate limit header, ignoring parameters such as ";w=60".
+func parseRateLimitValue(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	value, _, _ = strings.Cut(value, ",")
+	value, _, _ = strings.Cut(value, ";")
+
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return n, true
+}
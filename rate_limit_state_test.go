@@ -0,0 +1,86 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitState(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   RateLimitState
+		wantOK bool
+	}{
+		{
+			name:   "success: IETF headers with delta reset",
+			header: http.Header{"Ratelimit-Limit": {"100, 100;w=60"}, "Ratelimit-Remaining": {"42"}, "Ratelimit-Reset": {"30"}},
+			want:   RateLimitState{Limit: 100, Remaining: 42, Reset: now.Add(30 * time.Second), UpdatedAt: now},
+			wantOK: true,
+		},
+		{
+			name:   "success: X-RateLimit headers with epoch reset",
+			header: http.Header{"X-Ratelimit-Limit": {"5000"}, "X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1704067260"}},
+			want:   RateLimitState{Limit: 5000, Remaining: 0, Reset: time.Unix(1704067260, 0), UpdatedAt: now},
+			wantOK: true,
+		},
+		{
+			name:   "success: partial headers",
+			header: http.Header{"X-Ratelimit-Remaining": {"7"}},
+			want:   RateLimitState{Limit: -1, Remaining: 7, UpdatedAt: now},
+			wantOK: true,
+		},
+		{
+			name:   "failure: no headers",
+			header: http.Header{},
+		},
+		{
+			name:   "failure: invalid values",
+			header: http.Header{"X-Ratelimit-Limit": {"many"}, "X-Ratelimit-Remaining": {"-1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseRateLimitState(tt.header, now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClientImpl_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	remaining := []string{"1", "0"}
+	client := NewClient("http://example.com:8080", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{"X-Ratelimit-Limit": {"2"}, "X-Ratelimit-Remaining": {remaining[0]}}
+		remaining = remaining[1:]
+
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: header, Body: http.NoBody}, nil
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []int{http.StatusTooManyRequests}}))
+
+	_, ok := client.RateLimit("example.com:8080")
+	assert.False(t, ok)
+
+	_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test", ExpectedStatusCodes: []int{http.StatusOK}}, nil)
+	require.Error(t, err)
+
+	got, ok := client.RateLimit("example.com:8080")
+	require.True(t, ok)
+	assert.Equal(t, 2, got.Limit)
+	assert.Equal(t, 0, got.Remaining)
+
+	_, ok = client.RateLimit("example.com")
+	assert.False(t, ok)
+}
@@ -0,0 +1,37 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Compile-time check to ensure Pacer implements RateLimiter interface.
+var _ RateLimiter = (*Pacer)(nil)
+
+// RateLimiter paces requests. It is satisfied by *rate.Limiter of golang.org/x/time/rate.
+type RateLimiter interface {
+	// Wait blocks until a request may be sent or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// WithRateLimiter makes the client wait for limiter before sending every request, including retries.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(c *client) {
+		c.middlewares = append(c.middlewares, func(next DoFunc) DoFunc {
+			return rateLimited(next, limiter)
+		})
+	}
+}
+
+func rateLimited(do DoFunc, limiter RateLimiter) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		err := limiter.Wait(httpRequest.Context())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return do(httpRequest)
+	}
+}
@@ -0,0 +1,80 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *countingLimiter) Wait(_ context.Context) error {
+	l.waits++
+
+	return l.err
+}
+
+func TestWithRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	newDo := func(calls *int, statuses ...int) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			status := statuses[*calls]
+			*calls++
+
+			return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+	}
+
+	t.Run("success: waits before every attempt", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		limiter := &countingLimiter{}
+		client := NewClient("http://example.com", WithDoFunc(newDo(&calls, http.StatusServiceUnavailable, http.StatusOK)), WithRateLimiter(limiter),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 2}),
+		)
+
+		response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, 2, limiter.waits)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("success: paced", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		client := NewClient("http://example.com", WithDoFunc(newDo(&calls, http.StatusOK, http.StatusOK, http.StatusOK)), WithRateLimiter(NewPacer(10*time.Millisecond)))
+
+		started := time.Now()
+		for range 3 {
+			_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+			require.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(started), 20*time.Millisecond)
+	})
+
+	t.Run("failure: limiter error", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		errLimited := errors.New("would exceed context deadline")
+		client := NewClient("http://example.com", WithDoFunc(newDo(&calls, http.StatusOK)), WithRateLimiter(&countingLimiter{err: errLimited}))
+
+		_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+		assert.ErrorIs(t, err, errLimited)
+		assert.Zero(t, calls)
+	})
+}
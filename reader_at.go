@@ -0,0 +1,204 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrRemoteResourceChanged is matched by the errors of RemoteReaderAt.ReadAt when the resource changed since
+// the RemoteReaderAt was created, so that bytes of different versions are never mixed.
+var ErrRemoteResourceChanged = errors.New("remote resource changed")
+
+// Compile-time check to ensure RemoteReaderAt implements io.ReaderAt interface.
+var _ io.ReaderAt = (*RemoteReaderAt)(nil)
+
+// RemoteReaderAt exposes a response body for random access, for example to read
+// the central directory of a zip archive over HTTP.
+//
+// Bodies up to the buffer limit are buffered in memory; larger bodies of servers
+// accepting byte ranges are read with a ranged request per ReadAt call.
+type RemoteReaderAt struct {
+	ctx      context.Context //nolint:containedctx
+	client   Client
+	request  Request
+	size     int64
+	buffered *bytes.Reader
+	// validator is the strong ETag or the Last-Modified date of the resource, sent as If-Range.
+	validator string
+}
+
+// NewRemoteReaderAt sends request and returns a RemoteReaderAt for its response body.
+// The body is buffered when it is at most maxBufferSize bytes long or the server does not accept byte ranges,
+// in which case an error is returned when it exceeds maxBufferSize.
+// ctx is used for the ranged requests of subsequent ReadAt calls, which are conditional on the ETag or
+// Last-Modified date of the response and fail with ErrRemoteResourceChanged when the resource changed.
+func NewRemoteReaderAt(ctx context.Context, client Client, request *Request, maxBufferSize int64) (*RemoteReaderAt, error) {
+	response, err := client.Do(ctx, request, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	size, err := strconv.ParseInt(getHeader(response.Headers, "Content-Length"), 10, 64)
+	if err == nil && size > maxBufferSize && acceptsByteRanges(response.Headers) {
+		return &RemoteReaderAt{
+			ctx:       ctx,
+			client:    client,
+			request:   *request,
+			size:      size,
+			validator: rangeValidator(response.Headers),
+		}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxBufferSize+1))
+	if err != nil {
+		return nil, errors.WithStack(classifyTransportError(err))
+	}
+
+	if int64(len(body)) > maxBufferSize {
+		return nil, errors.WithStack(newError(
+			CategoryValidation,
+			errors.Errorf("response body exceeds %d bytes and the server does not accept byte ranges", maxBufferSize),
+		))
+	}
+
+	return &RemoteReaderAt{
+		size:     int64(len(body)),
+		buffered: bytes.NewReader(body),
+	}, nil
+}
+
+// Size returns the size of the response body.
+func (r *RemoteReaderAt) Size() int64 {
+	return r.size
+}
+
+// Buffered reports whether the response body is held in memory.
+func (r *RemoteReaderAt) Buffered() bool {
+	return r.buffered != nil
+}
+
+// ReadAt reads len(p) bytes of the response body starting at offset off.
+func (r *RemoteReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if r.buffered != nil {
+		n, err := r.buffered.ReadAt(p, off)
+		if err == io.EOF { //nolint:errorlint
+			// io.ReaderAt callers compare the error with io.EOF, so it must not be wrapped.
+			return n, io.EOF
+		}
+
+		if err != nil {
+			return n, errors.WithStack(err)
+		}
+
+		return n, nil
+	}
+
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	end := min(off+int64(len(p)), r.size)
+
+	request := r.request
+	request.Headers = maps.Clone(r.request.Headers)
+	if request.Headers == nil {
+		request.Headers = map[string][]string{}
+	}
+
+	byteRange, err := RangeBytes(off, end-1)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	request.Headers["Range"] = []string{byteRange}
+	if r.validator != "" {
+		request.Headers["If-Range"] = []string{r.validator}
+	}
+
+	// A server answers 200 OK with the whole body when the resource no longer matches If-Range.
+	request.ExpectedStatusCodes = []int{http.StatusPartialContent, http.StatusOK}
+
+	response, err := r.client.Do(r.ctx, &request, nil)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	err = r.checkContentRange(response, off, end)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	n, err := io.ReadFull(response.Body, p[:end-off])
+	if err != nil {
+		return n, errors.WithStack(classifyTransportError(err))
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// checkContentRange returns an error matching ErrRemoteResourceChanged unless response carries the bytes
+// from off to end of a resource of the size read by NewRemoteReaderAt.
+func (r *RemoteReaderAt) checkContentRange(response *Response, off int64, end int64) error {
+	if response.StatusCode != http.StatusPartialContent {
+		return newError(CategoryValidation, ErrRemoteResourceChanged)
+	}
+
+	contentRange, err := ParseContentRange(response)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if contentRange.Start != off || contentRange.End != end-1 || (contentRange.Size >= 0 && contentRange.Size != r.size) {
+		return newError(CategoryValidation, errors.Wrapf(ErrRemoteResourceChanged,
+			"unexpected Content-Range %d-%d/%d for bytes %d-%d", contentRange.Start, contentRange.End, contentRange.Size, off, end-1))
+	}
+
+	return nil
+}
+
+// rangeValidator returns the strong ETag of headers, or else their Last-Modified date, for use as If-Range.
+func rangeValidator(headers map[string][]string) string {
+	etag := getHeader(headers, "ETag")
+	if etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return getHeader(headers, "Last-Modified")
+}
+
+func acceptsByteRanges(headers map[string][]string) bool {
+	for _, unit := range strings.Split(getHeader(headers, "Accept-Ranges"), ",") {
+		if strings.EqualFold(strings.TrimSpace(unit), "bytes") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,209 @@
+package webapiclient
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRemoteReaderAt(t *testing.T) {
+	t.Parallel()
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(strings.Repeat(name, 100)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	newServer := func(t *testing.T, ranges bool, requests *atomic.Int32) *httptest.Server {
+		t.Helper()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+
+			if !ranges {
+				w.Header().Set("Content-Length", strconv.Itoa(archive.Len()))
+				_, _ = w.Write(archive.Bytes())
+
+				return
+			}
+
+			http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(archive.Bytes()))
+		}))
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	readArchive := func(t *testing.T, readerAt *RemoteReaderAt) []string {
+		t.Helper()
+
+		reader, err := zip.NewReader(readerAt, readerAt.Size())
+		require.NoError(t, err)
+
+		contents := []string{}
+		for _, file := range reader.File {
+			rc, err := file.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			_ = rc.Close()
+			contents = append(contents, file.Name+":"+string(content[:5]))
+		}
+
+		return contents
+	}
+
+	t.Run("success: ranged requests", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int32
+		server := newServer(t, true, &requests)
+		client := NewClient(server.URL)
+
+		readerAt, err := NewRemoteReaderAt(context.Background(), client, &Request{Method: http.MethodGet, Path: "/archive.zip"}, 16)
+		require.NoError(t, err)
+
+		assert.False(t, readerAt.Buffered())
+		assert.Equal(t, int64(archive.Len()), readerAt.Size())
+		assert.Equal(t, []string{"a.txt:a.txt", "b.txt:b.txt"}, readArchive(t, readerAt))
+		assert.Greater(t, requests.Load(), int32(1))
+	})
+
+	t.Run("success: buffered", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int32
+		server := newServer(t, true, &requests)
+		client := NewClient(server.URL)
+
+		readerAt, err := NewRemoteReaderAt(context.Background(), client, &Request{Method: http.MethodGet, Path: "/archive.zip"}, 1<<20)
+		require.NoError(t, err)
+
+		assert.True(t, readerAt.Buffered())
+		assert.Equal(t, []string{"a.txt:a.txt", "b.txt:b.txt"}, readArchive(t, readerAt))
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("success: read past the end", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int32
+		server := newServer(t, true, &requests)
+		client := NewClient(server.URL)
+
+		readerAt, err := NewRemoteReaderAt(context.Background(), client, &Request{Method: http.MethodGet, Path: "/archive.zip"}, 16)
+		require.NoError(t, err)
+
+		p := make([]byte, 10)
+		n, err := readerAt.ReadAt(p, readerAt.Size()-4)
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, archive.Bytes()[archive.Len()-4:], p[:n])
+	})
+
+	t.Run("success: buffered read past the end returns io.EOF", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int32
+		server := newServer(t, true, &requests)
+		client := NewClient(server.URL)
+
+		readerAt, err := NewRemoteReaderAt(context.Background(), client, &Request{Method: http.MethodGet, Path: "/archive.zip"}, 1<<20)
+		require.NoError(t, err)
+		require.True(t, readerAt.Buffered())
+
+		p := make([]byte, 10)
+		n, err := readerAt.ReadAt(p, readerAt.Size()-4)
+		assert.Equal(t, io.EOF, err) //nolint:errorlint,testifylint
+		assert.Equal(t, 4, n)
+
+		got, err := io.ReadAll(io.NewSectionReader(readerAt, 0, readerAt.Size()+10))
+		require.NoError(t, err)
+		assert.Equal(t, archive.Bytes(), got)
+	})
+
+	t.Run("failure: resource changed", func(t *testing.T) {
+		t.Parallel()
+
+		var version atomic.Int32
+		var ifRange atomic.Value
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if value := r.Header.Get("If-Range"); value != "" {
+				ifRange.Store(value)
+			}
+
+			w.Header().Set("ETag", `"v`+strconv.Itoa(int(version.Load()))+`"`)
+			http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(archive.Bytes()))
+		}))
+		t.Cleanup(server.Close)
+
+		readerAt, err := NewRemoteReaderAt(context.Background(), NewClient(server.URL), &Request{Method: http.MethodGet, Path: "/archive.zip"}, 16)
+		require.NoError(t, err)
+
+		_, err = readerAt.ReadAt(make([]byte, 4), 0)
+		require.NoError(t, err)
+		assert.Equal(t, `"v0"`, ifRange.Load())
+
+		version.Store(1)
+
+		_, err = readerAt.ReadAt(make([]byte, 4), 0)
+		require.ErrorIs(t, err, ErrRemoteResourceChanged)
+		assert.Equal(t, CategoryValidation, ClassifyError(err))
+	})
+
+	t.Run("failure: unexpected Content-Range", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") == "" {
+				w.Header().Set("Accept-Ranges", "bytes")
+				w.Header().Set("Content-Length", strconv.Itoa(archive.Len()))
+				_, _ = w.Write(archive.Bytes())
+
+				return
+			}
+
+			w.Header().Set("Content-Range", "bytes 0-3/"+strconv.Itoa(archive.Len()))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(archive.Bytes()[:4])
+		}))
+		t.Cleanup(server.Close)
+
+		readerAt, err := NewRemoteReaderAt(context.Background(), NewClient(server.URL), &Request{Method: http.MethodGet, Path: "/archive.zip"}, 16)
+		require.NoError(t, err)
+
+		_, err = readerAt.ReadAt(make([]byte, 4), 0)
+		require.NoError(t, err)
+
+		_, err = readerAt.ReadAt(make([]byte, 4), 8)
+		require.ErrorIs(t, err, ErrRemoteResourceChanged)
+	})
+
+	t.Run("failure: too large without byte ranges", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int32
+		server := newServer(t, false, &requests)
+		client := NewClient(server.URL)
+
+		_, err := NewRemoteReaderAt(context.Background(), client, &Request{Method: http.MethodGet, Path: "/archive.zip"}, 16)
+		require.Error(t, err)
+		assert.Equal(t, CategoryValidation, ClassifyError(err))
+	})
+}
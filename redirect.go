@@ -0,0 +1,50 @@
+package webapiclient
+
+import (
+	"net/http"
+	"slices"
+)
+
+// hopHeaders are the response headers recorded for each redirect hop.
+var hopHeaders = []string{"Location", "Cache-Control", "Date"}
+
+// Hop is a single redirect followed while sending a request.
+type Hop struct {
+	// StatusCode is the redirect status code, such as 301 or 302.
+	StatusCode int
+	// URL is the URL that answered with the redirect.
+	URL string
+	// Headers is a subset of the redirect response headers (Location, Cache-Control and Date).
+	Headers map[string][]string
+}
+
+// redirectHistory returns the redirects followed before httpResponse, oldest first,
+// using the chain of redirect responses net/http records on each request.
+func redirectHistory(httpResponse *http.Response) []Hop {
+	var hops []Hop
+
+	for request := httpResponse.Request; request != nil && request.Response != nil; request = request.Response.Request {
+		redirect := request.Response
+
+		hop := Hop{
+			StatusCode: redirect.StatusCode,
+			Headers:    map[string][]string{},
+		}
+
+		if redirect.Request != nil && redirect.Request.URL != nil {
+			hop.URL = redirect.Request.URL.String()
+		}
+
+		for _, key := range hopHeaders {
+			if values := redirect.Header.Values(key); len(values) > 0 {
+				hop.Headers[key] = slices.Clone(values)
+			}
+		}
+
+		hops = append(hops, hop)
+	}
+
+	slices.Reverse(hops)
+
+	return hops
+}
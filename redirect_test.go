@@ -0,0 +1,64 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientImpl_Do_Redirects(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Set-Cookie", "secret=1")
+		http.Redirect(w, r, "/intermediate", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/intermediate", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, WithDoFunc(server.Client().Do))
+
+	t.Run("success: redirects recorded oldest first", func(t *testing.T) {
+		t.Parallel()
+
+		response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/short"}, nil)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+
+		require.Len(t, response.Redirects, 2)
+
+		first := response.Redirects[0]
+		assert.Equal(t, http.StatusMovedPermanently, first.StatusCode)
+		assert.Equal(t, server.URL+"/short", first.URL)
+		assert.Equal(t, []string{"/intermediate"}, first.Headers["Location"])
+		assert.Equal(t, []string{"max-age=60"}, first.Headers["Cache-Control"])
+		assert.NotContains(t, first.Headers, "Set-Cookie")
+
+		second := response.Redirects[1]
+		assert.Equal(t, http.StatusFound, second.StatusCode)
+		assert.Equal(t, server.URL+"/intermediate", second.URL)
+		assert.Equal(t, []string{"/final"}, second.Headers["Location"])
+	})
+
+	t.Run("success: no redirects", func(t *testing.T) {
+		t.Parallel()
+
+		response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/final"}, nil)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+
+		assert.Empty(t, response.Redirects)
+	})
+}
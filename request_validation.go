@@ -0,0 +1,197 @@
+package webapiclient
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	minStatusCode = 100
+	maxStatusCode = 599
+)
+
+// FieldError describes a single invalid field of a Request.
+type FieldError struct {
+	// Path is the location of the invalid field, such as "Headers[X-Api-Key][0]".
+	Path string
+	// Reason describes why the field is invalid.
+	Reason string
+}
+
+// ValidationError is returned when a Request is rejected before it is sent.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error returns a message listing every invalid field.
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Path+": "+field.Reason)
+	}
+
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+// validateRequest checks request and returns a *ValidationError listing every invalid field.
+func validateRequest(request *Request) error {
+	if request == nil {
+		return &ValidationError{Fields: []FieldError{{Path: "Request", Reason: "must not be nil"}}}
+	}
+
+	var fields []FieldError
+
+	if request.Method != "" && !isToken(request.Method) {
+		fields = append(fields, FieldError{Path: "Method", Reason: "must be a valid HTTP method"})
+	}
+
+	_, err := url.Parse(request.Path)
+	if err != nil {
+		fields = append(fields, FieldError{Path: "Path", Reason: "must be a valid URL reference"})
+	}
+
+	fields = append(fields, validateHeaders(request.Headers)...)
+
+	for i, statusCode := range request.ExpectedStatusCodes {
+		if (statusCode < minStatusCode || statusCode > maxStatusCode) && !isStatusClass(statusCode) {
+			fields = append(fields, FieldError{
+				Path:   fmt.Sprintf("ExpectedStatusCodes[%d]", i),
+				Reason: "must be a valid HTTP status code",
+			})
+		}
+	}
+
+	for i, contentType := range request.ExpectedContentTypes {
+		if contentType == "" {
+			fields = append(fields, FieldError{Path: fmt.Sprintf("ExpectedContentTypes[%d]", i), Reason: "must not be empty"})
+		}
+	}
+
+	if request.JSON != nil && request.Body != nil {
+		fields = append(fields, FieldError{Path: "JSON", Reason: "must not be set together with Body"})
+	}
+
+	if request.XML != nil && (request.Body != nil || request.JSON != nil) {
+		fields = append(fields, FieldError{Path: "XML", Reason: "must not be set together with Body or JSON"})
+	}
+
+	if request.Form != nil && (request.Body != nil || request.JSON != nil || request.XML != nil) {
+		fields = append(fields, FieldError{Path: "Form", Reason: "must not be set together with Body, JSON or XML"})
+	}
+
+	if request.Multipart != nil && (request.Body != nil || request.JSON != nil || request.XML != nil || request.Form != nil) {
+		fields = append(fields, FieldError{Path: "Multipart", Reason: "must not be set together with Body, JSON, XML or Form"})
+	}
+
+	if request.ContentEncoding != "" {
+		if _, ok := lookupCodec(request.ContentEncoding); !ok {
+			fields = append(fields, FieldError{Path: "ContentEncoding", Reason: "must be a registered content encoding"})
+		}
+	}
+
+	if request.RetryPolicy != nil {
+		fields = append(fields, validateRetryPolicy(request.RetryPolicy)...)
+	}
+
+	if request.Timeout < 0 {
+		fields = append(fields, FieldError{Path: "Timeout", Reason: "must not be negative"})
+	}
+
+	if request.ErrorInto != nil {
+		if value := reflect.ValueOf(request.ErrorInto); value.Kind() != reflect.Pointer || value.IsNil() {
+			fields = append(fields, FieldError{Path: "ErrorInto", Reason: "must be a non-nil pointer"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+// validateHeaders returns the invalid headers in key order. The keys are only sorted when a header is invalid,
+// keeping the common path free of allocations.
+func validateHeaders(headers map[string][]string) []FieldError {
+	valid := true
+	for key, values := range headers {
+		valid = valid && validHeader(key, values)
+	}
+
+	if valid {
+		return nil
+	}
+
+	var fields []FieldError
+
+	for _, key := range slices.Sorted(maps.Keys(headers)) {
+		if !isToken(key) {
+			fields = append(fields, FieldError{Path: fmt.Sprintf("Headers[%s]", key), Reason: "must be a valid header name"})
+		}
+
+		for i, value := range headers[key] {
+			if !validHeaderValue(value) {
+				fields = append(fields, FieldError{
+					Path:   fmt.Sprintf("Headers[%s][%d]", key, i),
+					Reason: "must not contain line breaks or NUL characters",
+				})
+			}
+		}
+	}
+
+	return fields
+}
+
+func validHeader(key string, values []string) bool {
+	return isToken(key) && !slices.ContainsFunc(values, func(value string) bool { return !validHeaderValue(value) })
+}
+
+func validHeaderValue(value string) bool {
+	return !strings.ContainsAny(value, "\r\n\x00")
+}
+
+func validateRetryPolicy(policy *RetryPolicy) []FieldError {
+	var fields []FieldError
+
+	if policy.MaxAttempts < 0 {
+		fields = append(fields, FieldError{Path: "RetryPolicy.MaxAttempts", Reason: "must not be negative"})
+	}
+
+	if policy.InitialBackoff < 0 {
+		fields = append(fields, FieldError{Path: "RetryPolicy.InitialBackoff", Reason: "must not be negative"})
+	}
+
+	if policy.MaxBackoff < 0 {
+		fields = append(fields, FieldError{Path: "RetryPolicy.MaxBackoff", Reason: "must not be negative"})
+	}
+
+	for i, statusCode := range policy.RetryableStatusCodes {
+		if statusCode < minStatusCode || statusCode > maxStatusCode {
+			fields = append(fields, FieldError{
+				Path:   fmt.Sprintf("RetryPolicy.RetryableStatusCodes[%d]", i),
+				Reason: "must be a valid HTTP status code",
+			})
+		}
+	}
+
+	return fields
+}
+
+// isToken reports whether s is a valid token as defined in RFC 9110.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r > '~' || r <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,203 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		request *Request
+		want    []FieldError
+	}{
+		{
+			name: "success: valid request",
+			request: &Request{
+				Method:               http.MethodGet,
+				Path:                 "/test",
+				Headers:              map[string][]string{"Accept": {"application/json"}},
+				ExpectedStatusCodes:  []int{http.StatusOK},
+				ExpectedContentTypes: []string{"application/json"},
+			},
+			want: nil,
+		},
+		{
+			name:    "success: status classes",
+			request: &Request{Path: "/test", ExpectedStatusCodes: []int{Status2xx, http.StatusNotModified}},
+			want:    nil,
+		},
+		{
+			name:    "success: empty method",
+			request: &Request{Path: "/test"},
+			want:    nil,
+		},
+		{
+			name:    "failure: nil request",
+			request: nil,
+			want:    []FieldError{{Path: "Request", Reason: "must not be nil"}},
+		},
+		{
+			name: "failure: multiple invalid fields",
+			request: &Request{
+				Method: "GE T",
+				Path:   "%zz",
+				Headers: map[string][]string{
+					"X Bad":    {"value"},
+					"X-Inject": {"ok", "bad\r\nX-Evil: 1"},
+				},
+				ExpectedStatusCodes:  []int{http.StatusOK, 42},
+				ExpectedContentTypes: []string{""},
+			},
+			want: []FieldError{
+				{Path: "Method", Reason: "must be a valid HTTP method"},
+				{Path: "Path", Reason: "must be a valid URL reference"},
+				{Path: "Headers[X Bad]", Reason: "must be a valid header name"},
+				{Path: "Headers[X-Inject][1]", Reason: "must not contain line breaks or NUL characters"},
+				{Path: "ExpectedStatusCodes[1]", Reason: "must be a valid HTTP status code"},
+				{Path: "ExpectedContentTypes[0]", Reason: "must not be empty"},
+			},
+		},
+		{
+			name: "failure: JSON with Body",
+			request: &Request{
+				Method: http.MethodPost,
+				Path:   "/test",
+				Body:   bytes.NewReader(nil),
+				JSON:   map[string]string{},
+			},
+			want: []FieldError{{Path: "JSON", Reason: "must not be set together with Body"}},
+		},
+		{
+			name: "failure: XML with JSON",
+			request: &Request{
+				Method: http.MethodPost,
+				Path:   "/test",
+				JSON:   map[string]string{},
+				XML:    struct{}{},
+			},
+			want: []FieldError{{Path: "XML", Reason: "must not be set together with Body or JSON"}},
+		},
+		{
+			name: "failure: Form with XML",
+			request: &Request{
+				Method: http.MethodPost,
+				Path:   "/test",
+				XML:    struct{}{},
+				Form:   map[string][]string{"a": {"1"}},
+			},
+			want: []FieldError{{Path: "Form", Reason: "must not be set together with Body, JSON or XML"}},
+		},
+		{
+			name: "failure: Multipart with Form",
+			request: &Request{
+				Method:    http.MethodPost,
+				Path:      "/test",
+				Form:      map[string][]string{"a": {"1"}},
+				Multipart: NewMultipartBody(),
+			},
+			want: []FieldError{{Path: "Multipart", Reason: "must not be set together with Body, JSON, XML or Form"}},
+		},
+		{
+			name:    "failure: ErrorInto is not a pointer",
+			request: &Request{Method: http.MethodGet, Path: "/test", ErrorInto: problemError{}},
+			want:    []FieldError{{Path: "ErrorInto", Reason: "must be a non-nil pointer"}},
+		},
+		{
+			name:    "failure: ErrorInto is a nil pointer",
+			request: &Request{Method: http.MethodGet, Path: "/test", ErrorInto: (*problemError)(nil)},
+			want:    []FieldError{{Path: "ErrorInto", Reason: "must be a non-nil pointer"}},
+		},
+		{
+			name:    "failure: negative timeout",
+			request: &Request{Method: http.MethodGet, Path: "/test", Timeout: -time.Second},
+			want:    []FieldError{{Path: "Timeout", Reason: "must not be negative"}},
+		},
+		{
+			name: "failure: invalid retry policy",
+			request: &Request{
+				Method: http.MethodGet,
+				Path:   "/test",
+				RetryPolicy: &RetryPolicy{
+					MaxAttempts:          -1,
+					InitialBackoff:       -time.Second,
+					MaxBackoff:           -time.Second,
+					RetryableStatusCodes: []int{http.StatusServiceUnavailable, 1000},
+				},
+			},
+			want: []FieldError{
+				{Path: "RetryPolicy.MaxAttempts", Reason: "must not be negative"},
+				{Path: "RetryPolicy.InitialBackoff", Reason: "must not be negative"},
+				{Path: "RetryPolicy.MaxBackoff", Reason: "must not be negative"},
+				{Path: "RetryPolicy.RetryableStatusCodes[1]", Reason: "must be a valid HTTP status code"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateRequest(tt.request)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			assert.Equal(t, tt.want, validationError.Fields)
+		})
+	}
+}
+
+func TestClientImpl_Do_ValidationError(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	}))
+
+	_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test", ExpectedStatusCodes: []int{0}}, nil)
+
+	var validationError *ValidationError
+	require.ErrorAs(t, err, &validationError)
+	assert.Equal(t, []FieldError{{Path: "ExpectedStatusCodes[0]", Reason: "must be a valid HTTP status code"}}, validationError.Fields)
+	assert.Equal(t, "invalid request: ExpectedStatusCodes[0]: must be a valid HTTP status code", validationError.Error())
+	assert.Equal(t, CategoryValidation, ClassifyError(err))
+	assert.Zero(t, calls)
+}
+
+func BenchmarkValidateRequest(b *testing.B) {
+	request := &Request{
+		Method: http.MethodGet,
+		Path:   "/users/1",
+		Headers: map[string][]string{
+			"Accept":        {"application/json"},
+			"Authorization": {"Bearer token"},
+			"X-Request-Id":  {"1"},
+		},
+		ExpectedStatusCodes:  []int{http.StatusOK},
+		ExpectedContentTypes: []string{"application/json"},
+	}
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		err := validateRequest(request)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
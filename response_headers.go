@@ -0,0 +1,35 @@
+package webapiclient
+
+import (
+	"net/http"
+)
+
+// WithResponseHeaders makes the client copy only the named headers into Response.Headers instead of
+// cloning every header, reducing allocations for high-volume callers. Helpers reading Response.Headers,
+// such as Filename or NewMultipartReader, need their headers to be listed.
+func WithResponseHeaders(names ...string) Option {
+	canonicalNames := make([]string, 0, len(names))
+	for _, name := range names {
+		canonicalNames = append(canonicalNames, http.CanonicalHeaderKey(name))
+	}
+
+	return func(c *client) {
+		c.responseHeaders = canonicalNames
+	}
+}
+
+// copyResponseHeaders returns the headers of httpResponse to expose in Response.Headers.
+func (c *client) copyResponseHeaders(header http.Header) map[string][]string {
+	if c.responseHeaders == nil {
+		return header.Clone()
+	}
+
+	copied := make(map[string][]string, len(c.responseHeaders))
+	for _, name := range c.responseHeaders {
+		if values, ok := header[name]; ok {
+			copied[name] = values
+		}
+	}
+
+	return copied
+}
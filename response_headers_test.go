@@ -0,0 +1,99 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResponseHeaders(t *testing.T) {
+	t.Parallel()
+
+	do := func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+				"Etag":         []string{`"v1"`},
+				"X-Request-Id": []string{"abc"},
+				"Server":       []string{"nginx"},
+			},
+			Body: io.NopCloser(bytes.NewReader(nil)),
+		}, nil
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    map[string][]string
+	}{
+		{
+			name: "success: all headers by default",
+			want: map[string][]string{
+				"Content-Type": {"application/json"},
+				"Etag":         {`"v1"`},
+				"X-Request-Id": {"abc"},
+				"Server":       {"nginx"},
+			},
+		},
+		{
+			name:    "success: allowlisted headers",
+			options: []Option{WithResponseHeaders("content-type", "ETag", "X-Missing")},
+			want: map[string][]string{
+				"Content-Type": {"application/json"},
+				"Etag":         {`"v1"`},
+			},
+		},
+		{
+			name:    "success: no headers",
+			options: []Option{WithResponseHeaders()},
+			want:    map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response, err := NewClient("http://example.com", append([]Option{WithDoFunc(do)}, tt.options...)...).Do(context.Background(), &Request{
+				Method:               http.MethodGet,
+				Path:                 "/test",
+				ExpectedContentTypes: []string{"application/json"},
+			}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, response.Headers)
+		})
+	}
+}
+
+func BenchmarkClientImpl_copyResponseHeaders(b *testing.B) {
+	header := http.Header{
+		"Content-Type":   {"application/json"},
+		"Content-Length": {"11"},
+		"Date":           {"Mon, 02 Jan 2006 15:04:05 GMT"},
+		"Etag":           {`"abc"`},
+		"Cache-Control":  {"no-cache"},
+	}
+
+	for _, bm := range []struct {
+		name    string
+		options []Option
+	}{
+		{name: "all headers"},
+		{name: "allowlist", options: []Option{WithResponseHeaders("Content-Type", "ETag")}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			c := NewClient("http://example.com", bm.options...).(*client)
+
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_ = c.copyResponseHeaders(header)
+			}
+		})
+	}
+}
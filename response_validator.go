@@ -0,0 +1,59 @@
+package webapiclient
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ResponseValidator checks a response after its status code and content type were validated, and returns
+// an error to reject it. A validator reading the body must put the bytes it read back in front of it.
+// Errors that are not an *Error are classified as CategoryValidation.
+type ResponseValidator func(httpResponse *http.Response, request *Request) error
+
+// WithResponseValidators adds validators run in order on every response, such as checks of required headers,
+// of a JSON envelope or of a body signature. The first error rejects the response like an unexpected status code.
+func WithResponseValidators(validators ...ResponseValidator) Option {
+	return func(c *client) {
+		c.responseValidators = append(c.responseValidators, validators...)
+	}
+}
+
+// RequireHeaders returns a ResponseValidator rejecting responses missing any of the headers names.
+func RequireHeaders(names ...string) ResponseValidator {
+	return func(httpResponse *http.Response, _ *Request) error {
+		var missing []string
+
+		for _, name := range names {
+			if httpResponse.Header.Get(name) == "" {
+				missing = append(missing, http.CanonicalHeaderKey(name))
+			}
+		}
+
+		if len(missing) > 0 {
+			return errors.Errorf("missing response headers: %s", strings.Join(missing, ", "))
+		}
+
+		return nil
+	}
+}
+
+// runResponseValidators returns the error of the first validator rejecting httpResponse.
+func (c *client) runResponseValidators(httpResponse *http.Response, request *Request) error {
+	for _, validator := range c.responseValidators {
+		err := validator(httpResponse, request)
+		if err == nil {
+			continue
+		}
+
+		var clientError *Error
+		if errors.As(err, &clientError) {
+			return err
+		}
+
+		return newError(CategoryValidation, err)
+	}
+
+	return nil
+}
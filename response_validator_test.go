@@ -0,0 +1,103 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusOKEnvelope rejects JSON bodies whose "status" member is not "ok", and puts the body back.
+func statusOKEnvelope(httpResponse *http.Response, _ *Request) error {
+	body, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	httpResponse.Body = io.NopCloser(bytes.NewReader(body))
+
+	var envelope struct {
+		Status string `json:"status"`
+	}
+
+	err = json.Unmarshal(body, &envelope)
+	if err != nil || envelope.Status != "ok" {
+		return errors.Errorf("envelope status %q", envelope.Status)
+	}
+
+	return nil
+}
+
+func TestClientImpl_Do_ResponseValidators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		validators   []ResponseValidator
+		header       http.Header
+		body         string
+		wantErr      string
+		wantCategory ErrorCategory
+	}{
+		{
+			name:       "success: every validator passes",
+			validators: []ResponseValidator{RequireHeaders("x-request-id"), statusOKEnvelope},
+			header:     http.Header{"X-Request-Id": {"1"}},
+			body:       `{"status":"ok"}`,
+		},
+		{
+			name:         "failure: missing headers",
+			validators:   []ResponseValidator{RequireHeaders("X-Request-Id", "x-signature"), statusOKEnvelope},
+			header:       http.Header{},
+			body:         `{"status":"ok"}`,
+			wantErr:      "missing response headers: X-Request-Id, X-Signature",
+			wantCategory: CategoryValidation,
+		},
+		{
+			name:         "failure: envelope",
+			validators:   []ResponseValidator{statusOKEnvelope},
+			body:         `{"status":"error"}`,
+			wantErr:      `envelope status "error"`,
+			wantCategory: CategoryValidation,
+		},
+		{
+			name: "failure: classified error is kept",
+			validators: []ResponseValidator{func(_ *http.Response, _ *Request) error {
+				return errors.WithStack(newError(CategoryDecode, errors.New("bad body")))
+			}},
+			wantErr:      "bad body",
+			wantCategory: CategoryDecode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewClient("http://example.com", WithResponseOnError(), WithResponseValidators(tt.validators...), WithDoFunc(
+				func(_ *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Header: tt.header, Body: io.NopCloser(strings.NewReader(tt.body))}, nil
+				},
+			))
+
+			response, err := client.Do(context.Background(), &Request{Path: "/"}, nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				assert.Equal(t, tt.wantCategory, ClassifyError(err))
+			} else {
+				require.NoError(t, err)
+			}
+
+			body, readErr := io.ReadAll(response.Body)
+			require.NoError(t, readErr)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
@@ -0,0 +1,274 @@
+// Package resumable implements Google-style resumable uploads, as used by Google Cloud Storage, Google Drive
+// and YouTube, on top of webapiclient: a session is initiated with a POST request, and the content is sent
+// in chunks with PUT requests carrying a Content-Range header, acknowledged with 308 Resume Incomplete.
+package resumable
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/hidori/go-webapiclient/backoff"
+	"github.com/pkg/errors"
+)
+
+const (
+	// chunkAlignment is the multiple of which every chunk but the last must be sized.
+	chunkAlignment = 256 << 10
+	// defaultChunkSize is the size of the chunks sent when Config.ChunkSize is not positive.
+	defaultChunkSize = 8 << 20
+	// statusResumeIncomplete is the status code acknowledging a chunk of an incomplete upload.
+	statusResumeIncomplete = http.StatusPermanentRedirect
+)
+
+// ErrSessionExpired is matched by the errors of requests to an upload session that no longer exists,
+// which must be restarted with a new session.
+var ErrSessionExpired = errors.New("resumable: upload session expired")
+
+// Config describes how sessions are initiated and chunks are sent.
+type Config struct {
+	// Endpoint is the URL initiating sessions, such as "/upload/storage/v1/b/bucket/o?uploadType=resumable&name=object",
+	// resolved against the base URL of the client.
+	Endpoint string
+	// ChunkSize is the maximum number of bytes sent by a PUT request, rounded up to a multiple of 256 KiB;
+	// zero means 8 MiB. Each chunk is held in memory while it is sent.
+	ChunkSize int64
+	// MaxRetries is the number of times a failed chunk is resumed from the offset reported by the server.
+	MaxRetries int
+	// Backoff is the delay between resumptions.
+	Backoff backoff.Policy
+}
+
+// StatusError is returned for responses with an unexpected status code.
+type StatusError struct {
+	Method     string
+	StatusCode int
+}
+
+// Error returns the method and status code of the response.
+func (e *StatusError) Error() string {
+	return "resumable: " + e.Method + ": unexpected status code: " + strconv.Itoa(e.StatusCode)
+}
+
+// Is reports whether target is ErrSessionExpired for 404 Not Found and 410 Gone responses.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrSessionExpired && (e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone) //nolint:errorlint
+}
+
+// temporary reports whether the request may succeed when the upload is resumed.
+func (e *StatusError) temporary() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= http.StatusInternalServerError
+}
+
+// Uploader sends resumable uploads through a webapiclient.Client.
+// The client must not follow 308 responses as redirects; responses without a Location header,
+// such as those of upload sessions, are not followed by net/http.
+type Uploader struct {
+	client webapiclient.Client
+	config Config
+}
+
+// NewUploader creates a new Uploader that sends requests through client.
+func NewUploader(client webapiclient.Client, config Config) *Uploader {
+	return &Uploader{
+		client: client,
+		config: config,
+	}
+}
+
+// Upload initiates a session for the size bytes of r of contentType, with metadata, which may be nil,
+// marshaled as the JSON body of the initiation, and sends them. It returns the URL of the session,
+// which can be passed to Resume if the upload is interrupted, and the final response, whose body,
+// describing the uploaded resource, must be closed by the caller.
+func (u *Uploader) Upload(
+	ctx context.Context, r io.ReaderAt, size int64, contentType string, metadata any,
+) (string, *webapiclient.Response, error) {
+	sessionURL, err := u.Initiate(ctx, size, contentType, metadata)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	response, err := u.Resume(ctx, sessionURL, r, size)
+	if err != nil {
+		return sessionURL, nil, errors.WithStack(err)
+	}
+
+	return sessionURL, response, nil
+}
+
+// Initiate initiates a session for size bytes of contentType, with metadata, which may be nil,
+// marshaled as the JSON body of the request, and returns the URL of the session.
+func (u *Uploader) Initiate(ctx context.Context, size int64, contentType string, metadata any) (string, error) {
+	headers := map[string][]string{
+		"X-Upload-Content-Length": {strconv.FormatInt(size, 10)},
+	}
+
+	if contentType != "" {
+		headers["X-Upload-Content-Type"] = []string{contentType}
+	}
+
+	response, err := u.client.Do(ctx, &webapiclient.Request{
+		Method:  http.MethodPost,
+		Path:    u.config.Endpoint,
+		Headers: headers,
+		JSON:    metadata,
+	}, nil)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return "", errors.WithStack(&StatusError{Method: http.MethodPost, StatusCode: response.StatusCode})
+	}
+
+	sessionURL := http.Header(response.Headers).Get("Location")
+	if sessionURL == "" {
+		return "", errors.New("resumable: initiation response has no Location header")
+	}
+
+	return sessionURL, nil
+}
+
+// Status returns the number of bytes of the upload of size bytes received by the session at sessionURL.
+// When the upload is complete, it returns size and the final response, whose body must be closed by the caller.
+func (u *Uploader) Status(ctx context.Context, sessionURL string, size int64) (int64, *webapiclient.Response, error) {
+	return u.put(ctx, sessionURL, nil, "bytes */"+strconv.FormatInt(size, 10))
+}
+
+// Resume sends the bytes of r the session at sessionURL has not received yet, in chunks, and returns the final
+// response, whose body must be closed by the caller. Failed chunks are resumed from the offset reported by the
+// session up to Config.MaxRetries times; errors of expired sessions match ErrSessionExpired.
+func (u *Uploader) Resume(ctx context.Context, sessionURL string, r io.ReaderAt, size int64) (*webapiclient.Response, error) {
+	chunkSize := u.config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	chunkSize = (chunkSize + chunkAlignment - 1) / chunkAlignment * chunkAlignment
+
+	delays := backoff.New(u.config.Backoff)
+	retries := 0
+
+	offset, response, err := u.Status(ctx, sessionURL, size)
+
+	for err == nil && response == nil {
+		length := min(chunkSize, size-offset)
+
+		var next int64
+
+		next, response, err = u.putChunk(ctx, sessionURL, r, offset, length, size)
+		if err == nil {
+			if next <= offset && response == nil {
+				return nil, errors.Errorf("resumable: upload offset did not advance from %d", offset)
+			}
+
+			offset = next
+			retries = 0
+
+			delays.Reset()
+
+			continue
+		}
+
+		var statusErr *StatusError
+		if retries >= u.config.MaxRetries || ctx.Err() != nil || (errors.As(err, &statusErr) && !statusErr.temporary()) {
+			break
+		}
+
+		retries++
+
+		timer := time.NewTimer(delays.Next())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, errors.WithStack(ctx.Err())
+		case <-timer.C:
+		}
+
+		offset, response, err = u.Status(ctx, sessionURL, size)
+	}
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return response, nil
+}
+
+// putChunk sends the length bytes of r at offset.
+func (u *Uploader) putChunk(
+	ctx context.Context, sessionURL string, r io.ReaderAt, offset int64, length int64, size int64,
+) (int64, *webapiclient.Response, error) {
+	if length <= 0 {
+		return u.Status(ctx, sessionURL, size)
+	}
+
+	chunk, err := io.ReadAll(io.NewSectionReader(r, offset, length))
+	if err != nil {
+		return 0, nil, errors.WithStack(err)
+	}
+
+	contentRange := "bytes " + strconv.FormatInt(offset, 10) + "-" + strconv.FormatInt(offset+length-1, 10) +
+		"/" + strconv.FormatInt(size, 10)
+
+	return u.put(ctx, sessionURL, chunk, contentRange)
+}
+
+// put sends body with contentRange to the session, and returns the offset acknowledged by a 308 response,
+// or size and the final response when the upload is complete.
+func (u *Uploader) put(
+	ctx context.Context, sessionURL string, body []byte, contentRange string,
+) (int64, *webapiclient.Response, error) {
+	response, err := u.client.Do(ctx, &webapiclient.Request{
+		Method:  http.MethodPut,
+		Path:    sessionURL,
+		Headers: map[string][]string{"Content-Range": {contentRange}},
+		Body:    bytes.NewReader(body),
+	}, nil)
+	if err != nil {
+		return 0, nil, errors.WithStack(err)
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		size, _ := strconv.ParseInt(contentRange[strings.LastIndexByte(contentRange, '/')+1:], 10, 64)
+
+		return size, response, nil
+	case statusResumeIncomplete:
+		_ = response.Body.Close()
+
+		offset, err := parseRange(http.Header(response.Headers).Get("Range"))
+
+		return offset, nil, errors.WithStack(err)
+	default:
+		_ = response.Body.Close()
+
+		return 0, nil, errors.WithStack(&StatusError{Method: http.MethodPut, StatusCode: response.StatusCode})
+	}
+}
+
+// parseRange returns the offset following the bytes acknowledged by the Range header "bytes=0-<last>" of a
+// 308 response. A missing header means that nothing was received.
+func parseRange(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	first, last, ok := strings.Cut(strings.TrimPrefix(value, "bytes="), "-")
+
+	end, err := strconv.ParseInt(last, 10, 64)
+	if !ok || first != "0" || err != nil || end < 0 {
+		return 0, errors.Errorf("resumable: invalid Range header: %q", value)
+	}
+
+	return end + 1, nil
+}
@@ -0,0 +1,247 @@
+package resumable
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server is an in-memory resumable upload server storing a single session.
+type server struct {
+	mu       sync.Mutex
+	size     int64
+	metadata string
+	data     []byte
+	puts     int
+	// failPut makes the chunk PUT request with this number, counted from one, fail after storing half of the chunk.
+	failPut int
+	// gone makes the session respond 410 Gone.
+	gone bool
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/o":
+		s.size, _ = strconv.ParseInt(r.Header.Get("X-Upload-Content-Length"), 10, 64)
+		s.metadata = r.Header.Get("X-Upload-Content-Type") + " " + string(body)
+		w.Header().Set("Location", "https://uploads.example.com/upload/o?upload_id=1")
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPut && r.URL.Path == "/upload/o" && s.gone:
+		w.WriteHeader(http.StatusGone)
+	case r.Method == http.MethodPut && r.URL.Path == "/upload/o":
+		s.put(w, r.Header.Get("Content-Range"), body)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *server) put(w http.ResponseWriter, contentRange string, body []byte) {
+	if contentRange != "bytes */"+strconv.FormatInt(s.size, 10) {
+		s.puts++
+
+		var start, end, size int64
+
+		_, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &size)
+		if err != nil || start != int64(len(s.data)) || end-start+1 != int64(len(body)) || size != s.size {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		if s.puts == s.failPut {
+			s.data = append(s.data, body[:len(body)/2]...)
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		s.data = append(s.data, body...)
+	}
+
+	if int64(len(s.data)) == s.size {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"name":"object"}`))
+
+		return
+	}
+
+	if len(s.data) > 0 {
+		w.Header().Set("Range", "bytes=0-"+strconv.Itoa(len(s.data)-1))
+	}
+
+	w.WriteHeader(statusResumeIncomplete)
+}
+
+func newClient(handler http.Handler) webapiclient.Client {
+	return webapiclient.NewClient("https://uploads.example.com/", webapiclient.WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Result(), nil
+	}))
+}
+
+func TestUploader_Upload(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(strings.Repeat("0123456789abcdef", 40<<10)) // 640 KiB
+
+	tests := []struct {
+		name     string
+		server   *server
+		config   Config
+		wantPuts int
+		wantErr  error
+	}{
+		{
+			name:     "success: chunks aligned to 256 KiB",
+			server:   &server{},
+			config:   Config{Endpoint: "/upload/o?uploadType=resumable", ChunkSize: 100 << 10},
+			wantPuts: 3,
+		},
+		{
+			name:     "success: single chunk",
+			server:   &server{},
+			config:   Config{Endpoint: "/upload/o?uploadType=resumable"},
+			wantPuts: 1,
+		},
+		{
+			name:     "success: resumed after a failed chunk",
+			server:   &server{failPut: 2},
+			config:   Config{Endpoint: "/upload/o?uploadType=resumable", ChunkSize: 256 << 10, MaxRetries: 1},
+			wantPuts: 3,
+		},
+		{
+			name:     "failure: retries exhausted",
+			server:   &server{failPut: 1},
+			config:   Config{Endpoint: "/upload/o?uploadType=resumable", ChunkSize: 256 << 10},
+			wantPuts: 1,
+			wantErr:  &StatusError{Method: http.MethodPut, StatusCode: http.StatusServiceUnavailable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uploader := NewUploader(newClient(tt.server), tt.config)
+
+			sessionURL, response, err := uploader.Upload(
+				context.Background(), bytes.NewReader(content), int64(len(content)), "text/plain", map[string]string{"name": "object"},
+			)
+			assert.Equal(t, "https://uploads.example.com/upload/o?upload_id=1", sessionURL)
+			assert.Equal(t, tt.wantPuts, tt.server.puts)
+			assert.Equal(t, `text/plain {"name":"object"}`, tt.server.metadata)
+
+			if tt.wantErr != nil {
+				var statusErr *StatusError
+				require.ErrorAs(t, err, &statusErr)
+				assert.Equal(t, tt.wantErr, statusErr)
+				assert.Nil(t, response)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, content, tt.server.data)
+			assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+			body, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"name":"object"}`, string(body))
+		})
+	}
+}
+
+func TestUploader_Resume(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("hello, world")
+	sessionURL := "https://uploads.example.com/upload/o?upload_id=1"
+
+	t.Run("success: sends only the missing bytes", func(t *testing.T) {
+		t.Parallel()
+
+		s := &server{size: int64(len(content)), data: []byte("hello")}
+		uploader := NewUploader(newClient(s), Config{})
+
+		offset, response, err := uploader.Status(context.Background(), sessionURL, int64(len(content)))
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), offset)
+		assert.Nil(t, response)
+
+		response, err = uploader.Resume(context.Background(), sessionURL, bytes.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+		assert.Equal(t, 1, s.puts)
+		assert.Equal(t, content, s.data)
+	})
+
+	t.Run("success: already complete", func(t *testing.T) {
+		t.Parallel()
+
+		s := &server{size: int64(len(content)), data: content}
+		uploader := NewUploader(newClient(s), Config{})
+
+		response, err := uploader.Resume(context.Background(), sessionURL, bytes.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Zero(t, s.puts)
+	})
+
+	t.Run("failure: session expired", func(t *testing.T) {
+		t.Parallel()
+
+		uploader := NewUploader(newClient(&server{gone: true}), Config{MaxRetries: 3})
+
+		_, err := uploader.Resume(context.Background(), sessionURL, bytes.NewReader(content), int64(len(content)))
+		require.ErrorIs(t, err, ErrSessionExpired)
+	})
+}
+
+func TestParseRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "success: missing", value: "", want: 0},
+		{name: "success: range", value: "bytes=0-42", want: 43},
+		{name: "failure: not from zero", value: "bytes=1-42", wantErr: true},
+		{name: "failure: malformed", value: "bytes=0-x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseRange(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/hidori/go-webapiclient/backoff"
+	"github.com/pkg/errors"
+)
+
+// defaultRetryableStatusCodes are the status codes retried when RetryPolicy.RetryableStatusCodes is empty.
+var defaultRetryableStatusCodes = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy controls how transient failures are retried.
+//
+// Network errors, timeouts of individual attempts and retryable status codes are retried
+// with exponential backoff. Only idempotent requests with a rewindable body are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one; retries are disabled when it is at most one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts; zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the delay after every retry; values not greater than one mean 2.
+	Multiplier float64
+	// Jitter selects how the delay is randomized; the zero value disables jitter.
+	Jitter backoff.Jitter
+	// RetryableStatusCodes are the status codes that are retried; empty means 502, 503 and 504.
+	RetryableStatusCodes []int
+	// RespectRetryAfter retries 429 and 503 responses carrying a Retry-After header after the delay it requests
+	// instead of the backoff, unless the delay would exceed the deadline of the request context.
+	RespectRetryAfter bool
+}
+
+// WithRetryPolicy makes the client retry transient failures according to policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// backoff returns the delay before the retry following the attempt-th attempt, counted from zero.
+// previous is the delay before the attempt, which is used by backoff.DecorrelatedJitter.
+func (p *RetryPolicy) backoff(attempt int, previous time.Duration) time.Duration {
+	return backoff.Policy{
+		Initial:    p.InitialBackoff,
+		Max:        p.MaxBackoff,
+		Multiplier: p.Multiplier,
+		Jitter:     p.Jitter,
+	}.Delay(attempt, previous)
+}
+
+func (p *RetryPolicy) retryableStatusCode(statusCode int) bool {
+	statusCodes := p.RetryableStatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = defaultRetryableStatusCodes
+	}
+
+	return slices.Contains(statusCodes, statusCode)
+}
+
+// retryAfter returns the delay requested by the Retry-After header of a 429 or 503 response
+// when the policy respects it.
+func (p *RetryPolicy) retryAfter(httpResponse *http.Response, now time.Time) (time.Duration, bool) {
+	if !p.RespectRetryAfter ||
+		(httpResponse.StatusCode != http.StatusTooManyRequests && httpResponse.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	return parseRetryAfter(httpResponse.Header.Get("Retry-After"), now)
+}
+
+// sendWithRetry sends httpRequest and retries it according to policy, which may be nil.
+// meta is the allowlisted call metadata published with the RetryScheduled events.
+func sendWithRetry(do DoFunc, httpRequest *http.Request, policy *RetryPolicy, meta map[string]string) (*http.Response, error) {
+	if policy == nil || policy.MaxAttempts <= 1 || !isIdempotent(httpRequest) || !canRewind(httpRequest) {
+		return sendWithStaleConnectionRetry(do, httpRequest)
+	}
+
+	ctx := httpRequest.Context()
+
+	// backoffDelay is the delay of the policy, kept apart from the delays requested by Retry-After headers
+	// so that they do not widen the range of DecorrelatedJitter.
+	var backoffDelay time.Duration
+
+	for attempt := 0; ; attempt++ {
+		request := httpRequest
+		if attempt > 0 {
+			var err error
+
+			request, err = rewindRequest(httpRequest)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			request = request.WithContext(withAttempt(ctx, attempt+1))
+		}
+
+		httpResponse, err := sendWithStaleConnectionRetry(do, request)
+
+		backoffDelay = policy.backoff(attempt, backoffDelay)
+		delay := backoffDelay
+
+		last := attempt+1 >= policy.MaxAttempts || ctx.Err() != nil
+		if err != nil {
+			if last || !classifyTransportError(err).Temporary() || !allowRetry(ctx, delay) {
+				return nil, errors.WithStack(err)
+			}
+		} else {
+			if last {
+				return httpResponse, nil
+			}
+
+			retryAfter, ok := policy.retryAfter(httpResponse, time.Now())
+			if !ok && !policy.retryableStatusCode(httpResponse.StatusCode) {
+				return httpResponse, nil
+			}
+
+			if ok {
+				if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Now().Add(retryAfter).After(deadline) {
+					return httpResponse, nil
+				}
+
+				delay = retryAfter
+			}
+
+			if !allowRetry(ctx, delay) {
+				return httpResponse, nil
+			}
+
+			_ = httpResponse.Body.Close()
+		}
+
+		scheduled := RetryScheduled{Method: httpRequest.Method, Attempt: attempt + 2, Delay: delay, Err: err, Meta: meta}
+		if httpResponse != nil {
+			scheduled.StatusCode = httpResponse.StatusCode
+		}
+
+		publishRetryScheduled(ctx, httpRequest, scheduled)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, errors.WithStack(ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// publishRetryScheduled publishes scheduled with the redacted URL of httpRequest on the EventBus of ctx, if any.
+func publishRetryScheduled(ctx context.Context, httpRequest *http.Request, scheduled RetryScheduled) {
+	if ctx.Value(eventBusContextKey{}) == nil {
+		return
+	}
+
+	scheduled.URL = sanitizeURL(httpRequest.URL)
+	PublishEvent(ctx, scheduled)
+}
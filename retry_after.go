@@ -0,0 +1,44 @@
+package webapiclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryAfter returns the delay requested by the Retry-After header of response,
+// given either as a number of seconds or as an HTTP-date.
+func RetryAfter(response *Response) (time.Duration, bool) {
+	return parseRetryAfter(getHeader(response.Headers, "Retry-After"), time.Now())
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP-date, and returns the delay relative to now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	delay := date.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+
+	return delay, true
+}
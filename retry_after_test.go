@@ -0,0 +1,91 @@
+package webapiclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type args struct {
+		value string
+		now   time.Time
+	}
+	type want struct {
+		delay time.Duration
+		ok    bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "success: seconds",
+			args: args{value: "120", now: now},
+			want: want{delay: 120 * time.Second, ok: true},
+		},
+		{
+			name: "success: HTTP-date",
+			args: args{value: "Mon, 01 Jan 2024 00:00:30 GMT", now: now},
+			want: want{delay: 30 * time.Second, ok: true},
+		},
+		{
+			name: "success: HTTP-date in the past",
+			args: args{value: "Sun, 31 Dec 2023 23:59:00 GMT", now: now},
+			want: want{delay: 0, ok: true},
+		},
+		{
+			name: "failure: empty",
+			args: args{value: "", now: now},
+			want: want{delay: 0, ok: false},
+		},
+		{
+			name: "failure: negative seconds",
+			args: args{value: "-1", now: now},
+			want: want{delay: 0, ok: false},
+		},
+		{
+			name: "failure: malformed",
+			args: args{value: "soon", now: now},
+			want: want{delay: 0, ok: false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			delay, ok := parseRetryAfter(tt.args.value, tt.args.now)
+			assert.Equal(t, tt.want.ok, ok)
+			assert.Equal(t, tt.want.delay, delay)
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		headers   map[string][]string
+		wantDelay time.Duration
+		wantOK    bool
+	}{
+		{name: "success: seconds", headers: map[string][]string{"Retry-After": {"30"}}, wantDelay: 30 * time.Second, wantOK: true},
+		{name: "failure: missing", headers: map[string][]string{}, wantDelay: 0, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			delay, ok := RetryAfter(&Response{Headers: tt.headers})
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantDelay, delay)
+		})
+	}
+}
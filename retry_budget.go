@@ -0,0 +1,72 @@
+package webapiclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// retryBudgetContextKey is the context key of the RetryBudget attached with WithRetryBudget.
+type retryBudgetContextKey struct{}
+
+// RetryBudget caps the retries of all the requests of a logical operation spanning many client calls,
+// preventing retries of nested calls from multiplying. It is safe for concurrent use.
+type RetryBudget struct {
+	mu           sync.Mutex
+	maxRetries   int
+	maxRetryTime time.Duration
+	retries      int
+	retryTime    time.Duration
+}
+
+// NewRetryBudget creates a RetryBudget allowing maxRetries retries, waiting maxRetryTime in total before them.
+// A non-positive limit means no limit of its kind.
+func NewRetryBudget(maxRetries int, maxRetryTime time.Duration) *RetryBudget {
+	return &RetryBudget{
+		maxRetries:   maxRetries,
+		maxRetryTime: maxRetryTime,
+	}
+}
+
+// WithRetryBudget returns a copy of ctx carrying budget. Requests sent with it are only retried by
+// the retry policy while the budget lasts; a request whose retry would exceed it returns its last result.
+func WithRetryBudget(ctx context.Context, budget *RetryBudget) context.Context {
+	return context.WithValue(ctx, retryBudgetContextKey{}, budget)
+}
+
+// Used returns the number of retries made and the total time waited before them.
+func (b *RetryBudget) Used() (int, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.retries, b.retryTime
+}
+
+// take consumes a retry waiting delay, and reports whether the budget allowed it.
+func (b *RetryBudget) take(delay time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.maxRetries > 0 && b.retries >= b.maxRetries {
+		return false
+	}
+
+	if b.maxRetryTime > 0 && b.retryTime+delay > b.maxRetryTime {
+		return false
+	}
+
+	b.retries++
+	b.retryTime += delay
+
+	return true
+}
+
+// allowRetry reports whether the retry budget of ctx, if any, allows a retry waiting delay, and consumes it.
+func allowRetry(ctx context.Context, delay time.Duration) bool {
+	budget, ok := ctx.Value(retryBudgetContextKey{}).(*RetryBudget)
+	if !ok {
+		return true
+	}
+
+	return budget.take(delay)
+}
@@ -0,0 +1,86 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBudget_take(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		budget *RetryBudget
+		delays []time.Duration
+		want   []bool
+	}{
+		{
+			name:   "success: retries",
+			budget: NewRetryBudget(2, 0),
+			delays: []time.Duration{time.Hour, time.Hour, time.Second},
+			want:   []bool{true, true, false},
+		},
+		{
+			name:   "success: retry time",
+			budget: NewRetryBudget(0, 3*time.Second),
+			delays: []time.Duration{time.Second, 3 * time.Second, 2 * time.Second, time.Millisecond},
+			want:   []bool{true, false, true, false},
+		},
+		{
+			name:   "success: unlimited",
+			budget: NewRetryBudget(0, 0),
+			delays: []time.Duration{time.Hour, time.Hour},
+			want:   []bool{true, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := make([]bool, 0, len(tt.delays))
+			for _, delay := range tt.delays {
+				got = append(got, tt.budget.take(delay))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClientImpl_Do_RetryBudget(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := NewClient("http://example.com",
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+		WithDoFunc(func(_ *http.Request) (*http.Response, error) {
+			calls++
+
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}),
+	)
+
+	budget := NewRetryBudget(3, 0)
+	ctx := WithRetryBudget(context.Background(), budget)
+
+	for range 3 {
+		response, err := client.Do(ctx, &Request{Path: "/"}, nil)
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	}
+
+	// The first call retries twice, the second once, and the third cannot retry.
+	assert.Equal(t, 6, calls)
+
+	retries, retryTime := budget.Used()
+	assert.Equal(t, 3, retries)
+	assert.Positive(t, retryTime)
+}
@@ -0,0 +1,341 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hidori/go-webapiclient/backoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{
+			name:    "success: first retry",
+			policy:  RetryPolicy{InitialBackoff: 100 * time.Millisecond},
+			attempt: 0,
+			want:    100 * time.Millisecond,
+		},
+		{
+			name:    "success: default multiplier",
+			policy:  RetryPolicy{InitialBackoff: 100 * time.Millisecond},
+			attempt: 3,
+			want:    800 * time.Millisecond,
+		},
+		{
+			name:    "success: custom multiplier",
+			policy:  RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 1.5},
+			attempt: 2,
+			want:    225 * time.Millisecond,
+		},
+		{
+			name:    "success: capped",
+			policy:  RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second},
+			attempt: 10,
+			want:    5 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.policy.backoff(tt.attempt, 0))
+		})
+	}
+}
+
+func TestClientImpl_Do_Retry(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		status int
+		err    error
+	}
+	type args struct {
+		method  string
+		body    io.Reader
+		results []result
+		policy  *RetryPolicy
+	}
+	type want struct {
+		status int
+		calls  int
+		err    bool
+	}
+
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "success: no retry needed",
+			args: args{method: http.MethodGet, results: []result{{status: http.StatusOK}}, policy: policy},
+			want: want{status: http.StatusOK, calls: 1},
+		},
+		{
+			name: "success: retried status codes",
+			args: args{
+				method:  http.MethodPut,
+				body:    strings.NewReader("payload"),
+				results: []result{{status: http.StatusServiceUnavailable}, {status: http.StatusBadGateway}, {status: http.StatusOK}},
+				policy:  policy,
+			},
+			want: want{status: http.StatusOK, calls: 3},
+		},
+		{
+			name: "success: retried network error",
+			args: args{
+				method:  http.MethodGet,
+				results: []result{{err: syscall.ECONNREFUSED}, {status: http.StatusOK}},
+				policy:  policy,
+			},
+			want: want{status: http.StatusOK, calls: 2},
+		},
+		{
+			name: "success: custom retryable status codes",
+			args: args{
+				method:  http.MethodGet,
+				results: []result{{status: http.StatusTooManyRequests}, {status: http.StatusOK}},
+				policy:  &RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []int{http.StatusTooManyRequests}},
+			},
+			want: want{status: http.StatusOK, calls: 2},
+		},
+		{
+			name: "failure: attempts exhausted",
+			args: args{
+				method:  http.MethodGet,
+				results: []result{{status: http.StatusBadGateway}, {status: http.StatusBadGateway}, {status: http.StatusBadGateway}},
+				policy:  policy,
+			},
+			want: want{status: http.StatusBadGateway, calls: 3},
+		},
+		{
+			name: "failure: not retryable status code",
+			args: args{method: http.MethodGet, results: []result{{status: http.StatusInternalServerError}}, policy: policy},
+			want: want{status: http.StatusInternalServerError, calls: 1},
+		},
+		{
+			name: "failure: non-idempotent request",
+			args: args{
+				method:  http.MethodPost,
+				body:    strings.NewReader("payload"),
+				results: []result{{status: http.StatusServiceUnavailable}},
+				policy:  policy,
+			},
+			want: want{status: http.StatusServiceUnavailable, calls: 1},
+		},
+		{
+			name: "failure: no policy",
+			args: args{method: http.MethodGet, results: []result{{status: http.StatusServiceUnavailable}}},
+			want: want{status: http.StatusServiceUnavailable, calls: 1},
+		},
+		{
+			name: "failure: network error exhausted",
+			args: args{
+				method:  http.MethodGet,
+				results: []result{{err: syscall.ECONNREFUSED}, {err: syscall.ECONNREFUSED}, {err: syscall.ECONNREFUSED}},
+				policy:  policy,
+			},
+			want: want{calls: 3, err: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			do := func(req *http.Request) (*http.Response, error) {
+				if req.Body != nil {
+					body, err := io.ReadAll(req.Body)
+					require.NoError(t, err)
+					assert.Equal(t, []byte("payload"), body)
+				}
+
+				result := tt.args.results[calls]
+				calls++
+
+				if result.err != nil {
+					return nil, result.err
+				}
+
+				return &http.Response{StatusCode: result.status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+			}
+
+			options := []Option{WithDoFunc(do)}
+			if tt.args.policy != nil {
+				options = append(options, WithRetryPolicy(*tt.args.policy))
+			}
+
+			response, err := NewClient("http://example.com", options...).Do(context.Background(), &Request{
+				Method: tt.args.method,
+				Path:   "/test",
+				Body:   tt.args.body,
+			}, nil)
+			assert.Equal(t, tt.want.calls, calls)
+
+			if tt.want.err {
+				require.Error(t, err)
+				assert.Equal(t, CategoryNetwork, ClassifyError(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.status, response.StatusCode)
+		})
+	}
+
+	t.Run("success: request policy overrides client policy", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := func(req *http.Request) (*http.Response, error) {
+			calls++
+
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+		client := NewClient("http://example.com", WithDoFunc(do), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+
+		response, err := client.Do(context.Background(), &Request{
+			Method:      http.MethodGet,
+			Path:        "/test",
+			RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+		assert.Equal(t, 1, calls)
+
+		calls = 0
+		_, err = NewClient("http://example.com", WithDoFunc(do)).Do(context.Background(), &Request{
+			Method:      http.MethodGet,
+			Path:        "/health",
+			RetryPolicy: &RetryPolicy{MaxAttempts: 5},
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 5, calls)
+	})
+
+	t.Run("success: Retry-After", func(t *testing.T) {
+		t.Parallel()
+
+		newDo := func(calls *int, status int, retryAfter string) DoFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				*calls++
+				if *calls > 1 {
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+				}
+
+				return &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"Retry-After": []string{retryAfter}},
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		}
+
+		tests := []struct {
+			name       string
+			status     int
+			retryAfter string
+			respect    bool
+			timeout    time.Duration
+			wantStatus int
+			wantCalls  int
+		}{
+			{name: "429 retried", status: http.StatusTooManyRequests, retryAfter: "0", respect: true, wantStatus: http.StatusOK, wantCalls: 2},
+			{name: "503 retried", status: http.StatusServiceUnavailable, retryAfter: "0", respect: true, wantStatus: http.StatusOK, wantCalls: 2},
+			{name: "429 not respected", status: http.StatusTooManyRequests, retryAfter: "0", wantStatus: http.StatusTooManyRequests, wantCalls: 1},
+			{name: "delay beyond deadline", status: http.StatusTooManyRequests, retryAfter: "3600", respect: true, timeout: time.Minute, wantStatus: http.StatusTooManyRequests, wantCalls: 1},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				ctx := context.Background()
+				if tt.timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+					defer cancel()
+				}
+
+				calls := 0
+				client := NewClient("http://example.com", WithDoFunc(newDo(&calls, tt.status, tt.retryAfter)), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, RespectRetryAfter: tt.respect}))
+
+				response, err := client.Do(ctx, &Request{Method: http.MethodGet, Path: "/test"}, nil)
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, response.StatusCode)
+				assert.Equal(t, tt.wantCalls, calls)
+			})
+		}
+	})
+
+	t.Run("success: Retry-After does not widen decorrelated jitter", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		do := func(req *http.Request) (*http.Response, error) {
+			calls++
+
+			header := http.Header{}
+			if calls == 1 {
+				header.Set("Retry-After", "1")
+			}
+
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: header, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+
+		bus := NewEventBus()
+
+		var delays []time.Duration
+
+		SubscribeTo(bus, func(event RetryScheduled) {
+			delays = append(delays, event.Delay)
+		})
+
+		client := NewClient("http://example.com", WithDoFunc(do), WithEventBus(bus), WithRetryPolicy(RetryPolicy{
+			MaxAttempts:       3,
+			InitialBackoff:    time.Millisecond,
+			Jitter:            backoff.DecorrelatedJitter,
+			RespectRetryAfter: true,
+		}))
+
+		_, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/test"}, nil)
+		require.NoError(t, err)
+		require.Len(t, delays, 2)
+		assert.Equal(t, time.Second, delays[0])
+		// The first backoff delay is at most 3ms, so the next one is at most 9ms rather than 3 times Retry-After.
+		assert.LessOrEqual(t, delays[1], 9*time.Millisecond)
+	})
+
+	t.Run("failure: context canceled during backoff", func(t *testing.T) {
+		t.Parallel()
+
+		do := func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+		client := NewClient("http://example.com", WithDoFunc(do), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Do(ctx, &Request{Method: http.MethodGet, Path: "/test"}, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
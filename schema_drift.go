@@ -0,0 +1,210 @@
+package webapiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// JSONShape maps the paths of a JSON document, such as "data.items[].id", to their JSON types
+// ("object", "array", "string", "number", "boolean" or "null").
+type JSONShape map[string]string
+
+// DriftKind is the kind of a SchemaDrift.
+type DriftKind string
+
+const (
+	// DriftAdded reports a field that was not seen before.
+	DriftAdded DriftKind = "added"
+	// DriftTypeChanged reports a field whose JSON type changed.
+	DriftTypeChanged DriftKind = "type_changed"
+)
+
+const jsonTypeNull = "null"
+
+// maxDriftBodySize is the number of bytes of a response body buffered by SchemaDriftDetector.Wrap;
+// larger bodies are passed through without being observed.
+const maxDriftBodySize = 1 << 20
+
+// SchemaDrift is a difference between the recorded and the observed JSON shape of an operation.
+type SchemaDrift struct {
+	Operation string
+	Path      string
+	Kind      DriftKind
+	OldType   string
+	NewType   string
+}
+
+// SchemaDriftDetector records the JSON shape of responses per operation and reports drifts
+// when new fields appear or existing fields change type.
+type SchemaDriftDetector struct {
+	onDrift func(drift SchemaDrift)
+
+	mu     sync.Mutex
+	shapes map[string]JSONShape
+}
+
+// NewSchemaDriftDetector creates a new SchemaDriftDetector starting from shapes recorded in a previous run,
+// which may be nil, and calling onDrift for every drift found.
+func NewSchemaDriftDetector(shapes map[string]JSONShape, onDrift func(drift SchemaDrift)) *SchemaDriftDetector {
+	cloned := make(map[string]JSONShape, len(shapes))
+	for operation, shape := range shapes {
+		cloned[operation] = maps.Clone(shape)
+	}
+
+	return &SchemaDriftDetector{
+		onDrift: onDrift,
+		shapes:  cloned,
+	}
+}
+
+// Observe merges the shape of the JSON body into the recorded shape of operation and returns the drifts found.
+// Nothing is reported for the first observation of an operation, and null values never count as a type change.
+func (d *SchemaDriftDetector) Observe(operation string, body []byte) ([]SchemaDrift, error) {
+	var document any
+
+	err := json.Unmarshal(body, &document)
+	if err != nil {
+		return nil, errors.WithStack(newError(CategoryDecode, err))
+	}
+
+	observed := JSONShape{}
+	collectJSONShape(observed, "", document)
+
+	d.mu.Lock()
+
+	recorded, known := d.shapes[operation]
+	if !known {
+		recorded = JSONShape{}
+		d.shapes[operation] = recorded
+	}
+
+	var drifts []SchemaDrift
+
+	for _, path := range slices.Sorted(maps.Keys(observed)) {
+		newType := observed[path]
+		oldType, ok := recorded[path]
+
+		switch {
+		case !ok:
+			recorded[path] = newType
+			if known {
+				drifts = append(drifts, SchemaDrift{Operation: operation, Path: path, Kind: DriftAdded, NewType: newType})
+			}
+		case oldType == newType || newType == jsonTypeNull:
+		case oldType == jsonTypeNull:
+			recorded[path] = newType
+		default:
+			recorded[path] = newType
+			drifts = append(drifts, SchemaDrift{
+				Operation: operation,
+				Path:      path,
+				Kind:      DriftTypeChanged,
+				OldType:   oldType,
+				NewType:   newType,
+			})
+		}
+	}
+
+	d.mu.Unlock()
+
+	if d.onDrift != nil {
+		for _, drift := range drifts {
+			d.onDrift(drift)
+		}
+	}
+
+	return drifts, nil
+}
+
+// Shapes returns a snapshot of the recorded shapes, which can be persisted as JSON and passed to
+// NewSchemaDriftDetector in the next run.
+func (d *SchemaDriftDetector) Shapes() map[string]JSONShape {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	shapes := make(map[string]JSONShape, len(d.shapes))
+	for operation, shape := range d.shapes {
+		shapes[operation] = maps.Clone(shape)
+	}
+
+	return shapes
+}
+
+// Wrap wraps do so that the JSON bodies of successful responses are observed under the
+// method and path of their request. Drifts are reported only through the onDrift callback.
+// Bodies larger than 1 MiB are passed through without being observed.
+func (d *SchemaDriftDetector) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		httpResponse, err := do(httpRequest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(httpResponse.Header.Get("Content-Type"))
+		if httpResponse.StatusCode >= http.StatusMultipleChoices ||
+			(mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return httpResponse, nil
+		}
+
+		var consumed bytes.Buffer
+
+		body, truncated, err := readPooled(io.TeeReader(httpResponse.Body, &consumed), maxDriftBodySize)
+		httpResponse.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(&consumed, httpResponse.Body), httpResponse.Body}
+
+		if err != nil || truncated {
+			return httpResponse, nil
+		}
+
+		_, _ = d.Observe(httpRequest.Method+" "+httpRequest.URL.Path, []byte(body))
+
+		return httpResponse, nil
+	}
+}
+
+func collectJSONShape(shape JSONShape, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if path != "" {
+			shape[path] = "object"
+		}
+
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			collectJSONShape(shape, childPath, child)
+		}
+	case []any:
+		if path != "" {
+			shape[path] = "array"
+		}
+
+		for _, child := range v {
+			collectJSONShape(shape, path+"[]", child)
+		}
+	case string:
+		shape[path] = "string"
+	case float64:
+		shape[path] = "number"
+	case bool:
+		shape[path] = "boolean"
+	case nil:
+		if _, ok := shape[path]; !ok {
+			shape[path] = jsonTypeNull
+		}
+	}
+}
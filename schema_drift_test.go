@@ -0,0 +1,169 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDriftDetector_Observe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		shapes map[string]JSONShape
+		bodies []string
+		want   []SchemaDrift
+	}{
+		{
+			name:   "success: first observation reports nothing",
+			bodies: []string{`{"id":1,"name":"a"}`},
+			want:   nil,
+		},
+		{
+			name:   "success: same shape reports nothing",
+			bodies: []string{`{"id":1,"tags":["a"]}`, `{"id":2,"tags":[]}`},
+			want:   nil,
+		},
+		{
+			name:   "success: added field",
+			bodies: []string{`{"id":1}`, `{"id":2,"data":{"email":"a@example.com"}}`},
+			want: []SchemaDrift{
+				{Operation: "op", Path: "data", Kind: DriftAdded, NewType: "object"},
+				{Operation: "op", Path: "data.email", Kind: DriftAdded, NewType: "string"},
+			},
+		},
+		{
+			name:   "success: type change in array element",
+			bodies: []string{`{"items":[{"id":1}]}`, `{"items":[{"id":"1"}]}`},
+			want: []SchemaDrift{
+				{Operation: "op", Path: "items[].id", Kind: DriftTypeChanged, OldType: "number", NewType: "string"},
+			},
+		},
+		{
+			name:   "success: null is compatible",
+			bodies: []string{`{"id":1,"deleted":null}`, `{"id":null,"deleted":true}`, `{"id":2,"deleted":false}`},
+			want:   nil,
+		},
+		{
+			name:   "success: shapes from previous run",
+			shapes: map[string]JSONShape{"op": {"id": "number"}},
+			bodies: []string{`{"id":true}`},
+			want: []SchemaDrift{
+				{Operation: "op", Path: "id", Kind: DriftTypeChanged, OldType: "number", NewType: "boolean"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var reported []SchemaDrift
+			detector := NewSchemaDriftDetector(tt.shapes, func(drift SchemaDrift) {
+				reported = append(reported, drift)
+			})
+
+			var got []SchemaDrift
+			for _, body := range tt.bodies {
+				drifts, err := detector.Observe("op", []byte(body))
+				require.NoError(t, err)
+				got = append(got, drifts...)
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, reported)
+		})
+	}
+}
+
+func TestSchemaDriftDetector_Observe_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewSchemaDriftDetector(nil, nil).Observe("op", []byte("{"))
+	assert.Equal(t, CategoryDecode, ClassifyError(err))
+}
+
+func TestSchemaDriftDetector_Shapes(t *testing.T) {
+	t.Parallel()
+
+	detector := NewSchemaDriftDetector(nil, nil)
+	_, err := detector.Observe("op", []byte(`{"id":1,"items":[{"name":"a"}]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]JSONShape{
+		"op": {"id": "number", "items": "array", "items[]": "object", "items[].name": "string"},
+	}, detector.Shapes())
+}
+
+func TestSchemaDriftDetector_Wrap(t *testing.T) {
+	t.Parallel()
+
+	bodies := []string{`{"id":1}`, `{"id":"1"}`}
+	calls := 0
+	do := func(req *http.Request) (*http.Response, error) {
+		body := bodies[calls]
+		calls++
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	}
+
+	var mu sync.Mutex
+	var reported []SchemaDrift
+	detector := NewSchemaDriftDetector(nil, func(drift SchemaDrift) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		reported = append(reported, drift)
+	})
+	client := NewClient("http://example.com", WithDoFunc(detector.Wrap(do)))
+
+	for _, want := range bodies {
+		response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/users/1"}, nil)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+		assert.Equal(t, []byte(want), body)
+	}
+
+	assert.Equal(t, []SchemaDrift{
+		{Operation: "GET /users/1", Path: "id", Kind: DriftTypeChanged, OldType: "number", NewType: "string"},
+	}, reported)
+}
+
+func TestSchemaDriftDetector_Wrap_LargeBody(t *testing.T) {
+	t.Parallel()
+
+	large := `{"data":"` + strings.Repeat("x", maxDriftBodySize) + `"}`
+	do := func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(large)),
+		}, nil
+	}
+
+	detector := NewSchemaDriftDetector(nil, nil)
+	client := NewClient("http://example.com", WithDoFunc(detector.Wrap(do)))
+
+	response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/users"}, nil)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, large, string(body))
+	assert.Empty(t, detector.Shapes())
+}
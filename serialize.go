@@ -0,0 +1,95 @@
+package webapiclient
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ResourceKeyFunc returns the key of the resource targeted by httpRequest.
+// Requests with the same key are sent one at a time; an empty key disables serialization for the request.
+type ResourceKeyFunc func(httpRequest *http.Request) string
+
+// PathResourceKey is a ResourceKeyFunc keying requests by host and path, ignoring the query.
+func PathResourceKey(httpRequest *http.Request) string {
+	return httpRequest.URL.Host + httpRequest.URL.Path
+}
+
+// WithResourceSerialization makes the client send requests targeting the same resource one at a time,
+// so that concurrent writers in one process do not interleave and trip optimistic locking.
+// A request holds its resource until its response headers are received.
+func WithResourceSerialization(key ResourceKeyFunc) Option {
+	return func(c *client) {
+		locks := newResourceLocks()
+		c.middlewares = append(c.middlewares, func(next DoFunc) DoFunc {
+			return locks.wrap(next, key)
+		})
+	}
+}
+
+// resourceLocks is a set of mutexes keyed by resource, which are removed when no longer used.
+type resourceLocks struct {
+	mu    sync.Mutex
+	locks map[string]*resourceLock
+}
+
+type resourceLock struct {
+	sem  chan struct{}
+	refs int
+}
+
+func newResourceLocks() *resourceLocks {
+	return &resourceLocks{
+		locks: map[string]*resourceLock{},
+	}
+}
+
+func (l *resourceLocks) wrap(do DoFunc, key ResourceKeyFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		resource := key(httpRequest)
+		if resource == "" {
+			return do(httpRequest)
+		}
+
+		lock := l.acquire(resource)
+		defer l.release(resource, lock)
+
+		select {
+		case lock.sem <- struct{}{}:
+		case <-httpRequest.Context().Done():
+			return nil, errors.WithStack(httpRequest.Context().Err())
+		}
+
+		defer func() {
+			<-lock.sem
+		}()
+
+		return do(httpRequest)
+	}
+}
+
+func (l *resourceLocks) acquire(resource string) *resourceLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, ok := l.locks[resource]
+	if !ok {
+		lock = &resourceLock{sem: make(chan struct{}, 1)}
+		l.locks[resource] = lock
+	}
+
+	lock.refs++
+
+	return lock
+}
+
+func (l *resourceLocks) release(resource string, lock *resourceLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, resource)
+	}
+}
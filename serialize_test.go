@@ -0,0 +1,125 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResourceSerialization(t *testing.T) {
+	t.Parallel()
+
+	newDo := func(active map[string]*atomic.Int32, maxActive map[string]*atomic.Int32) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			n := active[req.URL.Path].Add(1)
+			for {
+				current := maxActive[req.URL.Path].Load()
+				if n <= current || maxActive[req.URL.Path].CompareAndSwap(current, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			active[req.URL.Path].Add(-1)
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+	}
+
+	run := func(t *testing.T, key ResourceKeyFunc) map[string]int32 {
+		t.Helper()
+
+		paths := []string{"/a", "/b"}
+		active := map[string]*atomic.Int32{}
+		maxActive := map[string]*atomic.Int32{}
+		for _, path := range paths {
+			active[path] = &atomic.Int32{}
+			maxActive[path] = &atomic.Int32{}
+		}
+
+		client := NewClient("http://example.com", WithDoFunc(newDo(active, maxActive)), WithResourceSerialization(key))
+
+		var wg sync.WaitGroup
+		for i := range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				response, err := client.Do(context.Background(), &Request{Method: http.MethodPut, Path: paths[i%2]}, nil)
+				if assert.NoError(t, err) {
+					_ = response.Body.Close()
+				}
+			}()
+		}
+		wg.Wait()
+
+		got := map[string]int32{}
+		for _, path := range paths {
+			got[path] = maxActive[path].Load()
+		}
+
+		return got
+	}
+
+	t.Run("success: same path is serialized", func(t *testing.T) {
+		t.Parallel()
+
+		got := run(t, PathResourceKey)
+		assert.Equal(t, map[string]int32{"/a": 1, "/b": 1}, got)
+	})
+
+	t.Run("success: empty key is not serialized", func(t *testing.T) {
+		t.Parallel()
+
+		got := run(t, func(req *http.Request) string {
+			if req.URL.Path == "/a" {
+				return "a"
+			}
+
+			return ""
+		})
+		assert.Equal(t, int32(1), got["/a"])
+		assert.Greater(t, got["/b"], int32(1))
+	})
+
+	t.Run("failure: context canceled while waiting", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		do := func(req *http.Request) (*http.Response, error) {
+			<-release
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+		}
+		client := NewClient("http://example.com", WithDoFunc(do), WithResourceSerialization(PathResourceKey))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+
+			response, err := client.Do(context.Background(), &Request{Method: http.MethodPut, Path: "/a"}, nil)
+			if assert.NoError(t, err) {
+				_ = response.Body.Close()
+			}
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Do(ctx, &Request{Method: http.MethodPut, Path: "/a"}, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(release)
+		<-done
+	})
+}
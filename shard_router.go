@@ -0,0 +1,126 @@
+package webapiclient
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// shardReplicas is the number of points each base URL has on the hash ring of a ShardRouter.
+const shardReplicas = 128
+
+// shardKeyContextKey is the context key of the shard key attached with WithShardKey.
+type shardKeyContextKey struct{}
+
+// WithShardKey returns a copy of ctx whose requests are routed by a ShardRouter according to key,
+// such as a tenant or object ID.
+func WithShardKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, shardKeyContextKey{}, key)
+}
+
+// ShardRouter sends requests to one of several base URLs chosen by consistent hashing of their shard key,
+// for vendors exposing sharded or regional endpoints. Adding or removing a base URL only moves the keys
+// of its share of the ring. Only the scheme and host of the base URLs are used; requests keep their path.
+// Requests without a shard key are sent unchanged.
+type ShardRouter struct {
+	hosts  []*url.URL
+	points []shardPoint
+}
+
+type shardPoint struct {
+	hash  uint64
+	index int
+}
+
+// NewShardRouter creates a new ShardRouter routing to baseURLs, which must be absolute URLs with a host.
+func NewShardRouter(baseURLs []string) (*ShardRouter, error) {
+	router := &ShardRouter{
+		hosts:  make([]*url.URL, 0, len(baseURLs)),
+		points: make([]shardPoint, 0, len(baseURLs)*shardReplicas),
+	}
+
+	var fields []FieldError
+
+	for i, baseURL := range baseURLs {
+		parsed, err := url.Parse(baseURL)
+		if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+			fields = append(fields, FieldError{Path: fmt.Sprintf("BaseURLs[%d]", i), Reason: "must be an absolute URL with a host"})
+
+			continue
+		}
+
+		for replica := range shardReplicas {
+			router.points = append(router.points, shardPoint{
+				hash:  shardHash(baseURL + "#" + strconv.Itoa(replica)),
+				index: len(router.hosts),
+			})
+		}
+
+		router.hosts = append(router.hosts, parsed)
+	}
+
+	if len(baseURLs) == 0 {
+		fields = append(fields, FieldError{Path: "BaseURLs", Reason: "must not be empty"})
+	}
+
+	if len(fields) > 0 {
+		return nil, errors.WithStack(&ValidationError{Fields: fields})
+	}
+
+	slices.SortFunc(router.points, func(a, b shardPoint) int {
+		return cmp.Or(cmp.Compare(a.hash, b.hash), cmp.Compare(a.index, b.index))
+	})
+
+	return router, nil
+}
+
+// Pick returns the base URL that requests with the shard key are sent to.
+func (r *ShardRouter) Pick(key string) string {
+	return r.pick(key).String()
+}
+
+// Wrap wraps do so that requests with a shard key are sent to the base URL picked for it.
+func (r *ShardRouter) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		key, ok := httpRequest.Context().Value(shardKeyContextKey{}).(string)
+		if !ok {
+			return do(httpRequest)
+		}
+
+		host := r.pick(key)
+
+		routed := httpRequest.Clone(httpRequest.Context())
+		routed.URL.Scheme = host.Scheme
+		routed.URL.Host = host.Host
+		routed.Host = host.Host
+
+		return do(routed)
+	}
+}
+
+func (r *ShardRouter) pick(key string) *url.URL {
+	hash := shardHash(key)
+
+	i, _ := slices.BinarySearchFunc(r.points, hash, func(point shardPoint, target uint64) int {
+		return cmp.Compare(point.hash, target)
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.hosts[r.points[i].index]
+}
+
+func shardHash(s string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(s))
+
+	return hash.Sum64()
+}
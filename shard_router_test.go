@@ -0,0 +1,127 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShardRouter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		baseURLs []string
+		want     []FieldError
+	}{
+		{
+			name:     "success: valid base URLs",
+			baseURLs: []string{"https://eu.example.com", "https://us.example.com/v1/"},
+		},
+		{
+			name:     "failure: empty",
+			baseURLs: nil,
+			want:     []FieldError{{Path: "BaseURLs", Reason: "must not be empty"}},
+		},
+		{
+			name:     "failure: relative and malformed base URLs",
+			baseURLs: []string{"https://eu.example.com", "/v1", "http://[::1"},
+			want: []FieldError{
+				{Path: "BaseURLs[1]", Reason: "must be an absolute URL with a host"},
+				{Path: "BaseURLs[2]", Reason: "must be an absolute URL with a host"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewShardRouter(tt.baseURLs)
+			if tt.want != nil {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, tt.want, validationErr.Fields)
+				assert.Nil(t, router)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, router)
+		})
+	}
+}
+
+func TestShardRouter_Pick(t *testing.T) {
+	t.Parallel()
+
+	baseURLs := []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"}
+
+	router, err := NewShardRouter(baseURLs)
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	picked := map[string]string{}
+
+	for i := range 3000 {
+		key := "tenant-" + strconv.Itoa(i)
+		picked[key] = router.Pick(key)
+		counts[picked[key]]++
+
+		assert.Equal(t, picked[key], router.Pick(key), "stable for the same key")
+	}
+
+	for _, baseURL := range baseURLs {
+		assert.Greater(t, counts[baseURL], 600, "keys are spread over %s", baseURL)
+	}
+
+	grown, err := NewShardRouter(append(baseURLs, "https://d.example.com"))
+	require.NoError(t, err)
+
+	moved := 0
+
+	for key, baseURL := range picked {
+		got := grown.Pick(key)
+		if got != baseURL {
+			assert.Equal(t, "https://d.example.com", got, "keys only move to the added base URL")
+
+			moved++
+		}
+	}
+
+	assert.Less(t, moved, 1300)
+}
+
+func TestShardRouter_Wrap(t *testing.T) {
+	t.Parallel()
+
+	router, err := NewShardRouter([]string{"https://eu.example.com", "https://us.example.com:8443"})
+	require.NoError(t, err)
+
+	var got []string
+
+	client := NewClient("https://api.example.com/v1/", WithDoFunc(router.Wrap(func(req *http.Request) (*http.Response, error) {
+		got = append(got, req.Host+" "+req.URL.String())
+
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})))
+
+	key := "tenant-42"
+	want := router.Pick(key)
+
+	_, err = client.Do(WithShardKey(context.Background(), key), &Request{Method: http.MethodGet, Path: "users?limit=1"}, nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "users"}, nil)
+	require.NoError(t, err)
+
+	wantHost := want[len("https://"):]
+	assert.Equal(t, []string{
+		wantHost + " " + want + "/v1/users?limit=1",
+		"api.example.com https://api.example.com/v1/users",
+	}, got)
+}
@@ -0,0 +1,125 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// maxExpiredBodySize is the number of bytes of a 403 response inspected for a signature expiry.
+const maxExpiredBodySize = 4096
+
+// signatureExpiredMarkers are the error codes and messages with which object stores reject expired signed URLs.
+var signatureExpiredMarkers = [][]byte{
+	[]byte("SignatureExpired"),
+	[]byte("Request has expired"),
+	[]byte("ExpiredToken"),
+	[]byte("Signed expiry time"),
+}
+
+// SignedURLRefreshFunc mints a new signed URL replacing expired.
+type SignedURLRefreshFunc func(ctx context.Context, expired *url.URL) (*url.URL, error)
+
+// SignedURLRefresher keeps requests to pre-signed URLs working past their expiry: when a request is
+// rejected with 403 because its signature expired, a new signed URL is minted and the request is sent
+// again. The new URL is used for later requests to the same resource, so that long-running download
+// loops can keep using the URL they started with.
+type SignedURLRefresher struct {
+	refresh SignedURLRefreshFunc
+
+	mu      sync.Mutex
+	current map[string]*url.URL
+}
+
+// NewSignedURLRefresher creates a new SignedURLRefresher minting new signed URLs with refresh.
+func NewSignedURLRefresher(refresh SignedURLRefreshFunc) *SignedURLRefresher {
+	return &SignedURLRefresher{
+		refresh: refresh,
+		current: map[string]*url.URL{},
+	}
+}
+
+// Wrap wraps do so that expired signed URLs are refreshed and the rejected requests sent again.
+// Requests whose body cannot be rewound are not sent again.
+func (r *SignedURLRefresher) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		key := signedURLKey(httpRequest.URL)
+
+		r.mu.Lock()
+		current := r.current[key]
+		r.mu.Unlock()
+
+		request := httpRequest
+		if current != nil {
+			request = withURL(httpRequest.Clone(httpRequest.Context()), current)
+		}
+
+		httpResponse, err := do(request)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if httpResponse.StatusCode != http.StatusForbidden || !canRewind(httpRequest) || !isSignatureExpired(httpResponse) {
+			return httpResponse, nil
+		}
+
+		refreshed, err := r.refresh(httpRequest.Context(), request.URL)
+		if err != nil {
+			_ = httpResponse.Body.Close()
+
+			return nil, errors.Wrap(err, "refresh signed URL")
+		}
+
+		retryRequest, err := rewindRequest(httpRequest)
+		if err != nil {
+			return httpResponse, nil //nolint:nilerr
+		}
+
+		_ = httpResponse.Body.Close()
+
+		r.mu.Lock()
+		r.current[key] = refreshed
+		r.mu.Unlock()
+
+		return do(withURL(retryRequest, refreshed))
+	}
+}
+
+// isSignatureExpired reports whether the body of httpResponse rejects an expired signature, and leaves the body unread.
+func isSignatureExpired(httpResponse *http.Response) bool {
+	head, err := io.ReadAll(io.LimitReader(httpResponse.Body, maxExpiredBodySize))
+	httpResponse.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), httpResponse.Body), httpResponse.Body}
+
+	if err != nil {
+		return false
+	}
+
+	for _, marker := range signatureExpiredMarkers {
+		if bytes.Contains(head, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signedURLKey identifies the resource of a signed URL regardless of its signature in the query.
+func signedURLKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + u.EscapedPath()
+}
+
+// withURL points httpRequest to u.
+func withURL(httpRequest *http.Request, u *url.URL) *http.Request {
+	httpRequest.URL = u
+	httpRequest.Host = u.Host
+
+	return httpRequest
+}
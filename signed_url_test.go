@@ -0,0 +1,108 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedURLRefresher_Wrap(t *testing.T) {
+	t.Parallel()
+
+	const expiredBody = `<Error><Code>AccessDenied</Code><Message>Request has expired</Message></Error>`
+
+	// newDo accepts only URLs signed with the given signature.
+	newDo := func(signature string, sent *[]string) DoFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			*sent = append(*sent, req.URL.String())
+
+			switch {
+			case req.URL.Query().Get("sig") == signature:
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("data"))}, nil
+			case req.URL.Query().Get("sig") == "forbidden":
+				return &http.Response{StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader("<Code>AccessDenied</Code>"))}, nil
+			default:
+				return &http.Response{StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader(expiredBody))}, nil
+			}
+		}
+	}
+
+	newRefresh := func(signature string, calls *int) SignedURLRefreshFunc {
+		return func(_ context.Context, expired *url.URL) (*url.URL, error) {
+			*calls++
+
+			refreshed := *expired
+			refreshed.RawQuery = url.Values{"sig": {signature}}.Encode()
+
+			return &refreshed, nil
+		}
+	}
+
+	send := func(t *testing.T, do DoFunc, rawURL string) (*http.Response, error) {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, rawURL, nil)
+		require.NoError(t, err)
+
+		return do(req)
+	}
+
+	t.Run("success: expired URL is refreshed and reused", func(t *testing.T) {
+		t.Parallel()
+
+		var sent []string
+		calls := 0
+		do := NewSignedURLRefresher(newRefresh("new", &calls)).Wrap(newDo("new", &sent))
+
+		for range 2 {
+			resp, err := send(t, do, "https://bucket.example.com/object?sig=old")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, []string{
+			"https://bucket.example.com/object?sig=old",
+			"https://bucket.example.com/object?sig=new",
+			"https://bucket.example.com/object?sig=new",
+		}, sent)
+	})
+
+	t.Run("success: other 403 is returned with its body", func(t *testing.T) {
+		t.Parallel()
+
+		var sent []string
+		calls := 0
+		do := NewSignedURLRefresher(newRefresh("new", &calls)).Wrap(newDo("new", &sent))
+
+		resp, err := send(t, do, "https://bucket.example.com/object?sig=forbidden")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "<Code>AccessDenied</Code>", string(body))
+		assert.Zero(t, calls)
+		assert.Len(t, sent, 1)
+	})
+
+	t.Run("failure: refresh error", func(t *testing.T) {
+		t.Parallel()
+
+		var sent []string
+		do := NewSignedURLRefresher(func(context.Context, *url.URL) (*url.URL, error) {
+			return nil, errors.New("signer unavailable")
+		}).Wrap(newDo("new", &sent))
+
+		_, err := send(t, do, "https://bucket.example.com/object?sig=old")
+		assert.ErrorContains(t, err, "signer unavailable")
+		assert.Len(t, sent, 1)
+	})
+}
@@ -0,0 +1,195 @@
+package webapiclient
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sloBuckets is the number of buckets the rolling window of an SLO is divided into.
+const sloBuckets = 10
+
+// SLO is a service level objective of an operation.
+type SLO struct {
+	// SuccessRate is the target fraction of good requests, such as 0.999.
+	SuccessRate float64
+	// Latency is the latency above which a request is not good; zero means latency is not tracked.
+	Latency time.Duration
+	// Window is the rolling window over which requests are counted.
+	Window time.Duration
+	// BurnRate is the burn rate of the error budget above which the alert is invoked, such as 14.4.
+	BurnRate float64
+	// MinRequests is the number of requests in the window below which no alert is invoked.
+	MinRequests int
+}
+
+// SLOStatus is the state of an operation over the rolling window of its SLO.
+type SLOStatus struct {
+	// Operation is the operation the status is about.
+	Operation string
+	// Requests is the number of requests in the window.
+	Requests int
+	// Failures is the number of requests that failed with a transport error, a 429 or a 5xx response.
+	Failures int
+	// Slow is the number of requests that did not fail but took longer than the latency of the SLO.
+	Slow int
+	// SuccessRate is the fraction of good requests, neither failed nor slow; it is 1 without requests.
+	SuccessRate float64
+	// BurnRate is the rate at which the error budget is consumed, 1 meaning exactly at the SLO.
+	BurnRate float64
+}
+
+// SLOAlertFunc is invoked with the status of an operation whose burn rate exceeded the threshold of its SLO.
+type SLOAlertFunc func(status SLOStatus)
+
+// SLOTracker tracks the rolling success rate and latency of operations against their SLOs and invokes
+// an alert when the burn rate of an operation exceeds its threshold. The alert is invoked once when the
+// threshold is crossed and again only after the burn rate went back below it.
+// The operation of a request is the one attached with WithCostKey, or its method and path.
+type SLOTracker struct {
+	slos  map[string]SLO
+	alert SLOAlertFunc
+	now   func() time.Time
+
+	mu         sync.Mutex
+	operations map[string]*sloWindow
+}
+
+type sloWindow struct {
+	buckets  [sloBuckets]sloBucket
+	alerting bool
+}
+
+type sloBucket struct {
+	start    time.Time
+	requests int
+	failures int
+	slow     int
+}
+
+// NewSLOTracker creates a new SLOTracker tracking the operations of slos, keyed by operation,
+// and invoking alert synchronously when an SLO burns too fast. Other operations are not tracked.
+func NewSLOTracker(slos map[string]SLO, alert SLOAlertFunc) *SLOTracker {
+	return &SLOTracker{
+		slos:       slos,
+		alert:      alert,
+		now:        time.Now,
+		operations: map[string]*sloWindow{},
+	}
+}
+
+// Wrap wraps do so that the outcome and latency of every request of a tracked operation are recorded.
+func (t *SLOTracker) Wrap(do DoFunc) DoFunc {
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		operation := costKeyOf(httpRequest).Operation
+
+		slo, ok := t.slos[operation]
+		if !ok {
+			return do(httpRequest)
+		}
+
+		start := t.now()
+		httpResponse, err := do(httpRequest)
+		latency := t.now().Sub(start)
+
+		failed := err != nil ||
+			httpResponse.StatusCode == http.StatusTooManyRequests ||
+			httpResponse.StatusCode >= http.StatusInternalServerError
+		slow := !failed && slo.Latency > 0 && latency > slo.Latency
+
+		t.record(operation, slo, failed, slow)
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return httpResponse, nil
+	}
+}
+
+// Status returns the status of operation over the rolling window of its SLO.
+func (t *SLOTracker) Status(operation string) (SLOStatus, bool) {
+	slo, ok := t.slos[operation]
+	if !ok {
+		return SLOStatus{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.status(operation, slo, t.now()), true
+}
+
+func (t *SLOTracker) record(operation string, slo SLO, failed bool, slow bool) {
+	now := t.now()
+
+	t.mu.Lock()
+
+	window, ok := t.operations[operation]
+	if !ok {
+		window = &sloWindow{}
+		t.operations[operation] = window
+	}
+
+	bucket := window.bucket(slo, now)
+	bucket.requests++
+
+	if failed {
+		bucket.failures++
+	}
+
+	if slow {
+		bucket.slow++
+	}
+
+	status := t.status(operation, slo, now)
+	burning := status.Requests >= slo.MinRequests && status.BurnRate > slo.BurnRate
+	notify := burning && !window.alerting
+	window.alerting = burning
+
+	t.mu.Unlock()
+
+	if notify && t.alert != nil {
+		t.alert(status)
+	}
+}
+
+// status sums the buckets of operation within the window of slo. t.mu must be held.
+func (t *SLOTracker) status(operation string, slo SLO, now time.Time) SLOStatus {
+	status := SLOStatus{Operation: operation, SuccessRate: 1}
+
+	if window, ok := t.operations[operation]; ok {
+		for _, bucket := range window.buckets {
+			if !bucket.start.IsZero() && now.Sub(bucket.start) < slo.Window {
+				status.Requests += bucket.requests
+				status.Failures += bucket.failures
+				status.Slow += bucket.slow
+			}
+		}
+	}
+
+	if status.Requests > 0 {
+		status.SuccessRate = 1 - float64(status.Failures+status.Slow)/float64(status.Requests)
+	}
+
+	if budget := 1 - slo.SuccessRate; budget > 0 {
+		status.BurnRate = (1 - status.SuccessRate) / budget
+	}
+
+	return status
+}
+
+// bucket returns the bucket of the window counting requests at now, resetting it when it holds older requests.
+func (w *sloWindow) bucket(slo SLO, now time.Time) *sloBucket {
+	width := max(slo.Window/sloBuckets, 1)
+	start := now.Truncate(width)
+	bucket := &w.buckets[int(start.UnixNano()/int64(width))%sloBuckets]
+
+	if !bucket.start.Equal(start) {
+		*bucket = sloBucket{start: start}
+	}
+
+	return bucket
+}
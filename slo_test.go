@@ -0,0 +1,133 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLOTracker(t *testing.T) {
+	t.Parallel()
+
+	type call struct {
+		path    string
+		status  int
+		err     error
+		latency time.Duration
+	}
+
+	newTracker := func(slos map[string]SLO, alerts *[]SLOStatus) (*SLOTracker, *time.Time) {
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		tracker := NewSLOTracker(slos, func(status SLOStatus) {
+			*alerts = append(*alerts, status)
+		})
+		tracker.now = func() time.Time {
+			return now
+		}
+
+		return tracker, &now
+	}
+
+	send := func(t *testing.T, tracker *SLOTracker, now *time.Time, calls ...call) {
+		t.Helper()
+
+		for _, c := range calls {
+			do := tracker.Wrap(func(_ *http.Request) (*http.Response, error) {
+				*now = now.Add(c.latency)
+				if c.err != nil {
+					return nil, c.err
+				}
+
+				return &http.Response{StatusCode: c.status, Body: http.NoBody}, nil
+			})
+
+			request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com"+c.path, nil)
+			require.NoError(t, err)
+
+			_, _ = do(request)
+		}
+	}
+
+	slos := map[string]SLO{
+		"GET /search": {SuccessRate: 0.9, Latency: 100 * time.Millisecond, Window: time.Minute, BurnRate: 2, MinRequests: 4},
+	}
+
+	t.Run("success: status", func(t *testing.T) {
+		t.Parallel()
+
+		var alerts []SLOStatus
+		tracker, now := newTracker(slos, &alerts)
+
+		send(t, tracker, now,
+			call{path: "/search", status: http.StatusOK, latency: 10 * time.Millisecond},
+			call{path: "/search", status: http.StatusOK, latency: 200 * time.Millisecond},
+			call{path: "/search", status: http.StatusServiceUnavailable},
+			call{path: "/search", err: syscall.ECONNRESET},
+			call{path: "/search", status: http.StatusNotFound},
+			call{path: "/other", status: http.StatusInternalServerError},
+		)
+
+		got, ok := tracker.Status("GET /search")
+		require.True(t, ok)
+		assert.Equal(t, "GET /search", got.Operation)
+		assert.Equal(t, 5, got.Requests)
+		assert.Equal(t, 2, got.Failures)
+		assert.Equal(t, 1, got.Slow)
+		assert.InDelta(t, 0.4, got.SuccessRate, 1e-9)
+		assert.InDelta(t, 6, got.BurnRate, 1e-9)
+
+		_, ok = tracker.Status("GET /other")
+		assert.False(t, ok)
+	})
+
+	t.Run("success: alert once per crossing", func(t *testing.T) {
+		t.Parallel()
+
+		var alerts []SLOStatus
+		tracker, now := newTracker(slos, &alerts)
+
+		failure := call{path: "/search", status: http.StatusBadGateway}
+		success := call{path: "/search", status: http.StatusOK}
+
+		send(t, tracker, now, failure, failure, failure)
+		assert.Empty(t, alerts, "below MinRequests")
+
+		send(t, tracker, now, failure, failure)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, 4, alerts[0].Requests)
+		assert.InDelta(t, 10, alerts[0].BurnRate, 1e-9)
+
+		*now = now.Add(2 * time.Minute)
+
+		send(t, tracker, now, success, success, success, success)
+		assert.Len(t, alerts, 1)
+
+		send(t, tracker, now, failure, failure)
+		assert.Len(t, alerts, 2)
+	})
+
+	t.Run("success: rolling window", func(t *testing.T) {
+		t.Parallel()
+
+		var alerts []SLOStatus
+		tracker, now := newTracker(slos, &alerts)
+
+		send(t, tracker, now, call{path: "/search", status: http.StatusInternalServerError})
+
+		*now = now.Add(30 * time.Second)
+		send(t, tracker, now, call{path: "/search", status: http.StatusOK})
+
+		got, _ := tracker.Status("GET /search")
+		assert.Equal(t, 2, got.Requests)
+
+		*now = now.Add(40 * time.Second)
+
+		got, _ = tracker.Status("GET /search")
+		assert.Equal(t, SLOStatus{Operation: "GET /search", Requests: 1, SuccessRate: 1}, got)
+	})
+}
@@ -0,0 +1,120 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// freshConnectionContextKey marks the context of a request to be sent on a new connection.
+type freshConnectionContextKey struct{}
+
+// staleConnectionMessages are fragments of error messages reported when a reused keep-alive connection was closed by the server.
+var staleConnectionMessages = []string{
+	"http: server closed idle connection",
+	"http2: server sent GOAWAY",
+	"use of closed network connection",
+}
+
+// isStaleConnectionError reports whether err is a typical failure of a keep-alive connection closed by the server while idle.
+func isStaleConnectionError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	message := err.Error()
+	for _, fragment := range staleConnectionMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isIdempotent reports whether httpRequest can be sent more than once without additional side effects.
+func isIdempotent(httpRequest *http.Request) bool {
+	switch httpRequest.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return httpRequest.Header.Get("Idempotency-Key") != "" || httpRequest.Header.Get("X-Idempotency-Key") != ""
+}
+
+// sendWithStaleConnectionRetry sends httpRequest and transparently sends it once more
+// when an idempotent request failed on a stale keep-alive connection.
+// The retry is marked so that the client sends it on a new connection, since another idle connection
+// of the pool may be just as stale; see withFreshConnections.
+func sendWithStaleConnectionRetry(do DoFunc, httpRequest *http.Request) (*http.Response, error) {
+	httpResponse, err := do(httpRequest)
+	if err == nil {
+		return httpResponse, nil
+	}
+
+	if !isStaleConnectionError(err) || !isIdempotent(httpRequest) || !canRewind(httpRequest) ||
+		httpRequest.Context().Err() != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	retryRequest, rewindErr := rewindRequest(httpRequest)
+	if rewindErr != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	retryRequest = retryRequest.WithContext(context.WithValue(retryRequest.Context(), freshConnectionContextKey{}, true))
+
+	httpResponse, err = do(retryRequest)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return httpResponse, nil
+}
+
+// newFreshConnectionDo returns a DoFunc sending requests through a copy of httpClient whose transport
+// opens a new connection for every request, or nil when httpClient is nil or its transport is not an *http.Transport.
+func newFreshConnectionDo(httpClient *http.Client) DoFunc {
+	if httpClient == nil {
+		return nil
+	}
+
+	roundTripper := httpClient.Transport
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+
+	transport, ok := roundTripper.(*http.Transport)
+	if !ok {
+		return nil
+	}
+
+	freshTransport := transport.Clone()
+	freshTransport.DisableKeepAlives = true
+
+	fresh := *httpClient
+	fresh.Transport = freshTransport
+
+	return fresh.Do
+}
+
+// withFreshConnections wraps do so that the retries of sendWithStaleConnectionRetry are sent with fresh.
+// They are sent with do when fresh is nil, as for clients created with WithDoFunc.
+func withFreshConnections(do DoFunc, fresh DoFunc) DoFunc {
+	if fresh == nil {
+		return do
+	}
+
+	return func(httpRequest *http.Request) (*http.Response, error) {
+		if requested, _ := httpRequest.Context().Value(freshConnectionContextKey{}).(bool); requested {
+			return fresh(httpRequest)
+		}
+
+		return do(httpRequest)
+	}
+}
@@ -0,0 +1,208 @@
+package webapiclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendWithStaleConnectionRetry(t *testing.T) {
+	t.Parallel()
+
+	connectionReset := &url.Error{Op: "Get", URL: "http://example.com/test", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}
+
+	type args struct {
+		method  string
+		body    io.Reader
+		headers http.Header
+		errs    []error
+	}
+	type want struct {
+		err   bool
+		calls int
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "success: no error",
+			args: args{method: http.MethodGet, errs: []error{nil}},
+			want: want{err: false, calls: 1},
+		},
+		{
+			name: "success: GET retried after connection reset",
+			args: args{method: http.MethodGet, errs: []error{connectionReset, nil}},
+			want: want{err: false, calls: 2},
+		},
+		{
+			name: "success: PUT retried after GOAWAY",
+			args: args{
+				method: http.MethodPut,
+				body:   strings.NewReader("payload"),
+				errs:   []error{errors.New("http2: server sent GOAWAY and closed the connection"), nil},
+			},
+			want: want{err: false, calls: 2},
+		},
+		{
+			name: "success: POST with idempotency key retried after EOF",
+			args: args{
+				method:  http.MethodPost,
+				body:    strings.NewReader("payload"),
+				headers: http.Header{"Idempotency-Key": []string{"key"}},
+				errs:    []error{io.EOF, nil},
+			},
+			want: want{err: false, calls: 2},
+		},
+		{
+			name: "failure: retried only once",
+			args: args{method: http.MethodGet, errs: []error{connectionReset, connectionReset}},
+			want: want{err: true, calls: 2},
+		},
+		{
+			name: "failure: POST is not retried",
+			args: args{method: http.MethodPost, body: strings.NewReader("payload"), errs: []error{connectionReset, nil}},
+			want: want{err: true, calls: 1},
+		},
+		{
+			name: "failure: body cannot be rewound",
+			args: args{method: http.MethodPut, body: io.MultiReader(strings.NewReader("payload")), errs: []error{connectionReset, nil}},
+			want: want{err: true, calls: 1},
+		},
+		{
+			name: "failure: other errors are not retried",
+			args: args{method: http.MethodGet, errs: []error{errors.New("connection refused"), nil}},
+			want: want{err: true, calls: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			do := func(req *http.Request) (*http.Response, error) {
+				err := tt.args.errs[calls]
+				calls++
+				if err != nil {
+					return nil, err
+				}
+
+				if req.Body != nil {
+					body, err := io.ReadAll(req.Body)
+					require.NoError(t, err)
+					assert.Equal(t, []byte("payload"), body)
+				}
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte("ok"))),
+				}, nil
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), tt.args.method, "http://example.com/test", tt.args.body)
+			require.NoError(t, err)
+			for key, values := range tt.args.headers {
+				req.Header[key] = values
+			}
+
+			got, err := sendWithStaleConnectionRetry(do, req)
+			assert.Equal(t, tt.want.calls, calls)
+			if tt.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			_ = got.Body.Close()
+			assert.Equal(t, http.StatusOK, got.StatusCode)
+		})
+	}
+}
+
+func TestClientImpl_Do_StaleConnectionRetryOnNewConnection(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu          sync.Mutex
+		connections int
+		failed      bool
+	)
+
+	waiting := make(chan struct{}, 2)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wait":
+			// Two requests in flight at once leave two idle connections in the pool.
+			waiting <- struct{}{}
+			for len(waiting) < 2 {
+				time.Sleep(time.Millisecond)
+			}
+		case "/fail":
+			mu.Lock()
+			fail := !failed
+			failed = true
+			mu.Unlock()
+
+			if fail {
+				conn, _, err := http.NewResponseController(w).Hijack()
+				if assert.NoError(t, err) {
+					_ = conn.Close()
+				}
+
+				return
+			}
+		}
+
+		_, _ = io.WriteString(w, "ok")
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			connections++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHTTPClient(server.Client()))
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			response, err := client.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/wait"}, nil)
+			if assert.NoError(t, err) {
+				_, _ = io.Copy(io.Discard, response.Body)
+				_ = response.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// PUT is not replayed by http.Transport itself, so the failure reaches the stale connection retry.
+	response, err := client.Do(context.Background(), &Request{Method: http.MethodPut, Path: "/fail", Body: strings.NewReader("payload")}, nil)
+	require.NoError(t, err)
+	_ = response.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, 3, connections, "the retry is sent on a new connection rather than the remaining idle one")
+}
@@ -0,0 +1,32 @@
+package webapiclient
+
+// Status classes can be listed in Request.ExpectedStatusCodes to expect every status code of the class,
+// such as Status2xx for 200, 201, 202 and 204.
+const (
+	// Status1xx matches the informational status codes 100 to 199.
+	Status1xx = 1
+	// Status2xx matches the successful status codes 200 to 299.
+	Status2xx = 2
+	// Status3xx matches the redirection status codes 300 to 399.
+	Status3xx = 3
+	// Status4xx matches the client error status codes 400 to 499.
+	Status4xx = 4
+	// Status5xx matches the server error status codes 500 to 599.
+	Status5xx = 5
+)
+
+// isStatusClass reports whether expected is one of the status classes.
+func isStatusClass(expected int) bool {
+	return expected >= Status1xx && expected <= Status5xx
+}
+
+// expectsStatusCode reports whether statusCode is in expected, either listed or in a listed status class.
+func expectsStatusCode(expected []int, statusCode int) bool {
+	for _, code := range expected {
+		if code == statusCode || (isStatusClass(code) && statusCode/100 == code) {
+			return true
+		}
+	}
+
+	return false
+}
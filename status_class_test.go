@@ -0,0 +1,55 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectsStatusCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		expected   []int
+		statusCode int
+		want       bool
+	}{
+		{name: "success: listed code", expected: []int{http.StatusOK}, statusCode: http.StatusOK, want: true},
+		{name: "success: class", expected: []int{Status2xx}, statusCode: http.StatusNoContent, want: true},
+		{name: "success: class and code", expected: []int{Status2xx, http.StatusNotModified}, statusCode: http.StatusNotModified, want: true},
+		{name: "failure: other class", expected: []int{Status2xx}, statusCode: http.StatusFound, want: false},
+		{name: "failure: unlisted code", expected: []int{http.StatusOK}, statusCode: http.StatusCreated, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, expectsStatusCode(tt.expected, tt.statusCode))
+		})
+	}
+}
+
+func TestClientImpl_Do_StatusClass(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusNoContent
+	client := NewClient("http://example.com", WithDoFunc(func(_ *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}))
+	request := &Request{Path: "/", ExpectedStatusCodes: []int{Status2xx}}
+
+	response, err := client.Do(context.Background(), request, nil)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+
+	status = http.StatusNotFound
+
+	_, err = client.Do(context.Background(), request, nil)
+	require.ErrorIs(t, err, ErrUnexpectedStatusCode)
+}
@@ -0,0 +1,33 @@
+package webapiclient
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout limits the time of every call whose context has no deadline, including retries and reading
+// the response body, so that a hung upstream connection cannot block a caller forever. Request.Timeout overrides it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.timeout = timeout
+	}
+}
+
+// withTimeout returns a copy of ctx with the deadline of request.Timeout, or else of the default timeout of
+// the client when ctx has no deadline, and the function releasing it; it returns ctx and nil when neither applies.
+func (c *client) withTimeout(ctx context.Context, request *Request) (context.Context, context.CancelFunc) {
+	timeout := c.timeout
+	if _, ok := ctx.Deadline(); ok {
+		timeout = 0
+	}
+
+	if request != nil && request.Timeout > 0 {
+		timeout = request.Timeout
+	}
+
+	if timeout <= 0 {
+		return ctx, nil
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
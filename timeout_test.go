@@ -0,0 +1,152 @@
+package webapiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientImpl_Do_Timeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success: deadline covers the call and the body", func(t *testing.T) {
+		t.Parallel()
+
+		var requestContext context.Context
+
+		client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			requestContext = req.Context()
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+		}))
+
+		response, err := client.Do(context.Background(), &Request{Path: "/", Timeout: time.Minute}, nil)
+		require.NoError(t, err)
+
+		deadline, ok := requestContext.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+		require.NoError(t, requestContext.Err(), "the context is alive until the body is closed")
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+
+		require.NoError(t, response.Body.Close())
+		require.ErrorIs(t, requestContext.Err(), context.Canceled)
+	})
+
+	t.Run("success: no timeout", func(t *testing.T) {
+		t.Parallel()
+
+		var requestContext context.Context
+
+		client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			requestContext = req.Context()
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}))
+
+		response, err := client.Do(context.Background(), &Request{Path: "/"}, nil)
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+
+		_, ok := requestContext.Deadline()
+		assert.False(t, ok)
+	})
+
+	t.Run("failure: deadline exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("http://example.com", WithDoFunc(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+
+			return nil, req.Context().Err()
+		}))
+
+		_, err := client.Do(context.Background(), &Request{Path: "/", Timeout: 10 * time.Millisecond}, nil)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, CategoryTimeout, ClassifyError(err))
+	})
+}
+
+func TestClientImpl_withTimeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		clientTimeout  time.Duration
+		contextTimeout time.Duration
+		request        *Request
+		want           time.Duration
+	}{
+		{
+			name:    "success: no timeout",
+			request: &Request{},
+			want:    0,
+		},
+		{
+			name:          "success: client timeout",
+			clientTimeout: time.Minute,
+			request:       &Request{},
+			want:          time.Minute,
+		},
+		{
+			name:           "success: context deadline takes precedence over the client timeout",
+			clientTimeout:  time.Minute,
+			contextTimeout: time.Hour,
+			request:        &Request{},
+			want:           time.Hour,
+		},
+		{
+			name:           "success: request timeout takes precedence",
+			clientTimeout:  time.Minute,
+			contextTimeout: time.Hour,
+			request:        &Request{Timeout: time.Second},
+			want:           time.Second,
+		},
+		{
+			name:          "success: nil request",
+			clientTimeout: time.Minute,
+			want:          time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewClient("http://example.com", WithTimeout(tt.clientTimeout)).(*client)
+
+			ctx := context.Background()
+
+			if tt.contextTimeout > 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tt.contextTimeout)
+				defer cancel()
+			}
+
+			got, cancel := c.withTimeout(ctx, tt.request)
+			if cancel != nil {
+				defer cancel()
+			}
+
+			deadline, ok := got.Deadline()
+			if tt.want == 0 {
+				assert.False(t, ok)
+				assert.Nil(t, cancel)
+
+				return
+			}
+
+			require.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(tt.want), deadline, time.Second)
+		})
+	}
+}
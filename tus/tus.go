@@ -0,0 +1,301 @@
+// Package tus implements the client side of the tus resumable upload protocol 1.0.0
+// (https://tus.io/protocols/resumable-upload) on top of webapiclient, with the creation and checksum extensions.
+package tus
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/hidori/go-webapiclient/backoff"
+	"github.com/pkg/errors"
+)
+
+const (
+	// version is the version of the protocol sent in the Tus-Resumable header.
+	version = "1.0.0"
+	// defaultChunkSize is the size of the chunks sent when Config.ChunkSize is not positive.
+	defaultChunkSize = 4 << 20
+	// statusChecksumMismatch is the status code of chunks rejected by the checksum extension.
+	statusChecksumMismatch = 460
+)
+
+// checksumAlgorithms are the algorithms supported for Config.ChecksumAlgorithm.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// Config describes how uploads are created and sent.
+type Config struct {
+	// Endpoint is the creation endpoint, resolved against the base URL of the client.
+	Endpoint string
+	// ChunkSize is the maximum number of bytes sent by a PATCH request; zero means 4 MiB.
+	// Each chunk is held in memory while it is sent.
+	ChunkSize int64
+	// ChecksumAlgorithm is the algorithm of the Upload-Checksum header of every chunk,
+	// one of "md5", "sha1" and "sha256"; empty means no checksum.
+	ChecksumAlgorithm string
+	// MaxRetries is the number of times a failed chunk is resumed from the offset reported by the server.
+	MaxRetries int
+	// Backoff is the delay between resumptions.
+	Backoff backoff.Policy
+}
+
+// StatusError is returned for responses with an unexpected status code.
+type StatusError struct {
+	Method     string
+	StatusCode int
+}
+
+// Error returns the method and status code of the response.
+func (e *StatusError) Error() string {
+	return "tus: " + e.Method + ": unexpected status code: " + strconv.Itoa(e.StatusCode)
+}
+
+// temporary reports whether the request may succeed when the upload is resumed. 409 Conflict is sent for
+// a chunk at an offset the server does not expect, which resuming from the offset of the server fixes.
+func (e *StatusError) temporary() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusConflict ||
+		e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// Uploader sends resumable uploads through a webapiclient.Client.
+type Uploader struct {
+	client webapiclient.Client
+	config Config
+}
+
+// NewUploader creates a new Uploader that sends requests through client.
+func NewUploader(client webapiclient.Client, config Config) *Uploader {
+	return &Uploader{
+		client: client,
+		config: config,
+	}
+}
+
+// Upload creates an upload of the size bytes of r with metadata, which may be nil, sends them,
+// and returns the URL of the upload, which can be passed to Resume if the upload is interrupted.
+func (u *Uploader) Upload(ctx context.Context, r io.ReaderAt, size int64, metadata map[string]string) (string, error) {
+	uploadURL, err := u.Create(ctx, size, metadata)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	err = u.Resume(ctx, uploadURL, r, size)
+	if err != nil {
+		return uploadURL, errors.WithStack(err)
+	}
+
+	return uploadURL, nil
+}
+
+// Create creates an upload of size bytes with metadata, which may be nil, and returns its URL.
+func (u *Uploader) Create(ctx context.Context, size int64, metadata map[string]string) (string, error) {
+	headers := map[string][]string{
+		"Tus-Resumable": {version},
+		"Upload-Length": {strconv.FormatInt(size, 10)},
+	}
+
+	if len(metadata) > 0 {
+		headers["Upload-Metadata"] = []string{encodeMetadata(metadata)}
+	}
+
+	response, err := u.send(ctx, &webapiclient.Request{
+		Method:  http.MethodPost,
+		Path:    u.config.Endpoint,
+		Headers: headers,
+	}, http.StatusCreated)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	location := http.Header(response.Headers).Get("Location")
+	if location == "" {
+		return "", errors.New("tus: creation response has no Location header")
+	}
+
+	// The location is relative to the creation endpoint.
+	endpoint, err := url.Parse(u.config.Endpoint)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	uploadURL, err := endpoint.Parse(location)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return uploadURL.String(), nil
+}
+
+// Offset returns the number of bytes of the upload at uploadURL received by the server.
+func (u *Uploader) Offset(ctx context.Context, uploadURL string) (int64, error) {
+	response, err := u.send(ctx, &webapiclient.Request{
+		Method:  http.MethodHead,
+		Path:    uploadURL,
+		Headers: map[string][]string{"Tus-Resumable": {version}},
+	}, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return parseOffset(response)
+}
+
+// Resume sends the bytes of r the server has not received yet to the upload at uploadURL, in chunks.
+// Failed chunks are resumed from the offset reported by the server up to Config.MaxRetries times;
+// chunks rejected with a status code that resuming cannot fix, such as a checksum mismatch, are not.
+func (u *Uploader) Resume(ctx context.Context, uploadURL string, r io.ReaderAt, size int64) error {
+	chunkSize := u.config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	delays := backoff.New(u.config.Backoff)
+	retries := 0
+
+	offset, err := u.Offset(ctx, uploadURL)
+
+	for err == nil && offset < size {
+		var next int64
+
+		next, err = u.patch(ctx, uploadURL, io.NewSectionReader(r, offset, min(chunkSize, size-offset)), offset)
+		if err == nil {
+			offset = next
+			retries = 0
+
+			delays.Reset()
+
+			continue
+		}
+
+		var statusErr *StatusError
+		if retries >= u.config.MaxRetries || ctx.Err() != nil || (errors.As(err, &statusErr) && !statusErr.temporary()) {
+			break
+		}
+
+		retries++
+
+		timer := time.NewTimer(delays.Next())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return errors.WithStack(ctx.Err())
+		case <-timer.C:
+		}
+
+		offset, err = u.Offset(ctx, uploadURL)
+	}
+
+	return errors.WithStack(err)
+}
+
+// patch sends chunk at offset and returns the offset reported by the server.
+func (u *Uploader) patch(ctx context.Context, uploadURL string, chunk io.Reader, offset int64) (int64, error) {
+	body, err := io.ReadAll(chunk)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	headers := map[string][]string{
+		"Tus-Resumable": {version},
+		"Upload-Offset": {strconv.FormatInt(offset, 10)},
+		"Content-Type":  {"application/offset+octet-stream"},
+	}
+
+	if u.config.ChecksumAlgorithm != "" {
+		newHash, ok := checksumAlgorithms[u.config.ChecksumAlgorithm]
+		if !ok {
+			return 0, errors.Errorf("tus: unsupported checksum algorithm: %s", u.config.ChecksumAlgorithm)
+		}
+
+		digest := newHash()
+		_, _ = digest.Write(body)
+		headers["Upload-Checksum"] = []string{u.config.ChecksumAlgorithm + " " + base64.StdEncoding.EncodeToString(digest.Sum(nil))}
+	}
+
+	response, err := u.send(ctx, &webapiclient.Request{
+		Method:  http.MethodPatch,
+		Path:    uploadURL,
+		Headers: headers,
+		Body:    bytes.NewReader(body),
+	}, http.StatusNoContent, http.StatusOK)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	next, err := parseOffset(response)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	if next <= offset {
+		return 0, errors.Errorf("tus: upload offset did not advance from %d", offset)
+	}
+
+	return next, nil
+}
+
+// send sends request and returns its response, with the body closed, when its status code is one of statusCodes.
+func (u *Uploader) send(ctx context.Context, request *webapiclient.Request, statusCodes ...int) (*webapiclient.Response, error) {
+	response, err := u.client.Do(ctx, request, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	_ = response.Body.Close()
+
+	if !slices.Contains(statusCodes, response.StatusCode) {
+		return nil, errors.WithStack(&StatusError{Method: request.Method, StatusCode: response.StatusCode})
+	}
+
+	return response, nil
+}
+
+// IsChecksumMismatch reports whether err is the rejection of a chunk whose checksum did not match.
+func IsChecksumMismatch(err error) bool {
+	var statusErr *StatusError
+
+	return errors.As(err, &statusErr) && statusErr.StatusCode == statusChecksumMismatch
+}
+
+func parseOffset(response *webapiclient.Response) (int64, error) {
+	offset, err := strconv.ParseInt(http.Header(response.Headers).Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, errors.New("tus: response has no valid Upload-Offset header")
+	}
+
+	return offset, nil
+}
+
+// encodeMetadata encodes metadata as the value of the Upload-Metadata header, with the keys sorted.
+func encodeMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+	}
+
+	return strings.Join(pairs, ",")
+}
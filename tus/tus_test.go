@@ -0,0 +1,231 @@
+package tus
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hidori/go-webapiclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server is an in-memory tus server storing a single upload.
+type server struct {
+	mu       sync.Mutex
+	length   int64
+	metadata string
+	data     []byte
+	patches  int
+	// failPatch makes the PATCH request with this number, counted from one, fail after storing half of its chunk.
+	failPatch int
+	// mismatch makes every PATCH request fail with 460 Checksum Mismatch.
+	mismatch bool
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Tus-Resumable") != version {
+		w.WriteHeader(http.StatusPreconditionFailed)
+
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/files/":
+		s.length, _ = strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		s.metadata = r.Header.Get("Upload-Metadata")
+		w.Header().Set("Location", "upload-1")
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodHead && r.URL.Path == "/files/upload-1":
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.Header().Set("Upload-Length", strconv.FormatInt(s.length, 10))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPatch && r.URL.Path == "/files/upload-1":
+		s.patch(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *server) patch(w http.ResponseWriter, r *http.Request) {
+	s.patches++
+
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+
+		return
+	}
+
+	if r.Header.Get("Upload-Offset") != strconv.Itoa(len(s.data)) {
+		w.WriteHeader(http.StatusConflict)
+
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	if checksum := r.Header.Get("Upload-Checksum"); checksum != "" {
+		sum := sha1.Sum(body) //nolint:gosec
+		if s.mismatch || checksum != "sha1 "+base64.StdEncoding.EncodeToString(sum[:]) {
+			w.WriteHeader(statusChecksumMismatch)
+
+			return
+		}
+	}
+
+	if s.patches == s.failPatch {
+		s.data = append(s.data, body[:len(body)/2]...)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	s.data = append(s.data, body...)
+	w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newClient(handler http.Handler) webapiclient.Client {
+	return webapiclient.NewClient("https://uploads.example.com/", webapiclient.WithDoFunc(func(req *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Result(), nil
+	}))
+}
+
+func TestUploader_Upload(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(strings.Repeat("0123456789", 10))
+
+	tests := []struct {
+		name        string
+		server      *server
+		config      Config
+		wantPatches int
+		wantErr     bool
+	}{
+		{
+			name:        "success: chunks",
+			server:      &server{},
+			config:      Config{Endpoint: "/files/", ChunkSize: 30},
+			wantPatches: 4,
+		},
+		{
+			name:        "success: checksum",
+			server:      &server{},
+			config:      Config{Endpoint: "/files/", ChunkSize: 64, ChecksumAlgorithm: "sha1"},
+			wantPatches: 2,
+		},
+		{
+			name:        "success: resumed after a failed chunk",
+			server:      &server{failPatch: 2},
+			config:      Config{Endpoint: "/files/", ChunkSize: 40, MaxRetries: 1},
+			wantPatches: 3,
+		},
+		{
+			name:        "failure: retries exhausted",
+			server:      &server{failPatch: 1},
+			config:      Config{Endpoint: "/files/", ChunkSize: 40},
+			wantPatches: 1,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uploader := NewUploader(newClient(tt.server), tt.config)
+
+			uploadURL, err := uploader.Upload(context.Background(), bytes.NewReader(content), int64(len(content)), map[string]string{
+				"filetype": "text/plain",
+				"filename": "digits.txt",
+			})
+			assert.Equal(t, "/files/upload-1", uploadURL)
+			assert.Equal(t, tt.wantPatches, tt.server.patches)
+			assert.Equal(t, int64(len(content)), tt.server.length)
+			assert.Equal(t, "filename ZGlnaXRzLnR4dA==,filetype dGV4dC9wbGFpbg==", tt.server.metadata)
+
+			if tt.wantErr {
+				var statusErr *StatusError
+				require.ErrorAs(t, err, &statusErr)
+				assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, content, tt.server.data)
+		})
+	}
+}
+
+func TestUploader_Resume(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("hello, world")
+
+	t.Run("success: sends only the missing bytes", func(t *testing.T) {
+		t.Parallel()
+
+		s := &server{length: int64(len(content)), data: []byte("hello")}
+		uploader := NewUploader(newClient(s), Config{Endpoint: "/files/"})
+
+		err := uploader.Resume(context.Background(), "/files/upload-1", bytes.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.patches)
+		assert.Equal(t, content, s.data)
+
+		offset, err := uploader.Offset(context.Background(), "/files/upload-1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), offset)
+	})
+
+	t.Run("failure: checksum mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		s := &server{length: int64(len(content)), mismatch: true}
+		uploader := NewUploader(newClient(s), Config{Endpoint: "/files/", ChecksumAlgorithm: "sha1", MaxRetries: 2})
+
+		err := uploader.Resume(context.Background(), "/files/upload-1", bytes.NewReader(content), int64(len(content)))
+		require.Error(t, err)
+		assert.True(t, IsChecksumMismatch(err))
+		assert.Equal(t, 1, s.patches)
+	})
+
+	t.Run("failure: unsupported checksum algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		s := &server{length: int64(len(content))}
+		uploader := NewUploader(newClient(s), Config{Endpoint: "/files/", ChecksumAlgorithm: "crc32"})
+
+		err := uploader.Resume(context.Background(), "/files/upload-1", bytes.NewReader(content), int64(len(content)))
+		require.ErrorContains(t, err, "unsupported checksum algorithm")
+		assert.Zero(t, s.patches)
+	})
+
+	t.Run("failure: unknown upload", func(t *testing.T) {
+		t.Parallel()
+
+		uploader := NewUploader(newClient(&server{}), Config{Endpoint: "/files/"})
+
+		err := uploader.Resume(context.Background(), "/files/missing", bytes.NewReader(content), int64(len(content)))
+
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+		assert.False(t, IsChecksumMismatch(err))
+	})
+}
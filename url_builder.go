@@ -0,0 +1,43 @@
+package webapiclient
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Compile-time check to ensure URLBuilderFunc implements URLBuilder interface.
+var _ URLBuilder = URLBuilderFunc(nil)
+
+// URLBuilder builds the URL of a request from the base URL of the client. It lets vendor-specific path
+// schemes, such as matrix parameters or double-encoded IDs, be implemented without forking the client.
+type URLBuilder interface {
+	// BuildURL returns a new URL to send request to. baseURL is shared by all requests and must not be modified.
+	BuildURL(baseURL *url.URL, request *Request) (*url.URL, error)
+}
+
+// URLBuilderFunc is an adapter to use an ordinary function as a URLBuilder.
+type URLBuilderFunc func(baseURL *url.URL, request *Request) (*url.URL, error)
+
+// BuildURL calls f(baseURL, request).
+func (f URLBuilderFunc) BuildURL(baseURL *url.URL, request *Request) (*url.URL, error) {
+	return f(baseURL, request)
+}
+
+// ResolveURL is the default URL builder, resolving the path of request as a URL reference against baseURL.
+func ResolveURL(baseURL *url.URL, request *Request) (*url.URL, error) {
+	requestURL, err := baseURL.Parse(request.Path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return requestURL, nil
+}
+
+// WithURLBuilder makes the client build request URLs with builder instead of ResolveURL.
+// Default query parameters are merged into the URL it returns.
+func WithURLBuilder(builder URLBuilder) Option {
+	return func(c *client) {
+		c.urlBuilder = builder
+	}
+}
@@ -0,0 +1,84 @@
+package webapiclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveURL(t *testing.T) {
+	t.Parallel()
+
+	baseURL, err := url.Parse("http://example.com/api/")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "success: relative path", path: "users/1", want: "http://example.com/api/users/1"},
+		{name: "success: absolute path", path: "/users/1", want: "http://example.com/users/1"},
+		{name: "success: double-encoded segment", path: "items/a%252Fb", want: "http://example.com/api/items/a%252Fb"},
+		{name: "failure: invalid path", path: "%zz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ResolveURL(baseURL, &Request{Path: tt.path})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, "http://example.com/api/", baseURL.String())
+		})
+	}
+}
+
+func TestWithURLBuilder(t *testing.T) {
+	t.Parallel()
+
+	// matrix joins the segments of the path with semicolons, as required by some vendors.
+	matrix := URLBuilderFunc(func(baseURL *url.URL, request *Request) (*url.URL, error) {
+		if request.Path == "" {
+			return nil, errors.New("empty path")
+		}
+
+		requestURL := *baseURL
+		requestURL.Path = baseURL.Path + strings.ReplaceAll(request.Path, "/", ";")
+
+		return &requestURL, nil
+	})
+
+	t.Run("success: custom builder", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("http://example.com/api/", WithURLBuilder(matrix), WithDefaultQuery(map[string][]string{"v": {"2"}}))
+
+		got, err := client.DryRun(context.Background(), &Request{Method: http.MethodGet, Path: "users/id=1"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "http://example.com/api/users;id=1?v=2", got.URL.String())
+		assert.Equal(t, "example.com", got.Host)
+	})
+
+	t.Run("failure: builder error", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewClient("http://example.com/api/", WithURLBuilder(matrix))
+
+		_, err := client.DryRun(context.Background(), &Request{Method: http.MethodGet}, nil)
+		require.Error(t, err)
+		assert.Equal(t, CategoryValidation, ClassifyError(err))
+	})
+}
@@ -0,0 +1,290 @@
+// Package webhook verifies the signatures of inbound webhook callbacks: HMAC-SHA256 over a timestamp and
+// the body with a timestamp tolerance, and the schemes of GitHub, Stripe, Slack and Standard Webhooks.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultTolerance is the tolerance used when Config.Tolerance is zero.
+	defaultTolerance = 5 * time.Minute
+	// defaultMaxBodySize is the maximum body size read by Verify when Config.MaxBodySize is not positive.
+	defaultMaxBodySize = 1 << 20
+)
+
+var (
+	// ErrMissingSignature is returned when a callback carries no signature or timestamp.
+	ErrMissingSignature = errors.New("webhook: missing signature")
+	// ErrInvalidSignature is returned when no signature of a callback matches its body.
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	// ErrTimestampOutOfTolerance is returned when the timestamp of a callback is too far from the current time,
+	// which protects against replayed callbacks.
+	ErrTimestampOutOfTolerance = errors.New("webhook: timestamp out of tolerance")
+	// ErrEmptySecret is returned by NewVerifier for an empty secret, with which anyone could sign callbacks.
+	ErrEmptySecret = errors.New("webhook: empty secret")
+	// ErrBodyTooLarge is returned when the body of a callback is larger than Config.MaxBodySize.
+	ErrBodyTooLarge = errors.New("webhook: body too large")
+)
+
+// Config describes how callbacks are verified.
+type Config struct {
+	// Secret is the signing secret shared with the sender.
+	Secret []byte
+	// Tolerance is the maximum difference between the timestamp of a callback and the current time;
+	// zero means 5 minutes and a negative value disables the check.
+	Tolerance time.Duration
+	// MaxBodySize is the maximum body size read by Verify and Handler; zero means 1 MiB.
+	MaxBodySize int64
+}
+
+// Scheme verifies the signature of a callback with its headers and raw body.
+type Scheme func(v *Verifier, header http.Header, body []byte) error
+
+// Verifier verifies the signatures of callbacks signed with a shared secret.
+type Verifier struct {
+	config Config
+	now    func() time.Time
+}
+
+// NewVerifier creates a new Verifier, and returns ErrEmptySecret when config has no secret,
+// such as when the environment variable holding it is not set.
+func NewVerifier(config Config) (*Verifier, error) {
+	if len(config.Secret) == 0 {
+		return nil, errors.WithStack(ErrEmptySecret)
+	}
+
+	return &Verifier{
+		config: config,
+		now:    time.Now,
+	}, nil
+}
+
+// HMACSHA256 returns a Scheme verifying the hex encoded HMAC-SHA256 signature, in the header signatureHeader,
+// of the timestamp in the header timestampHeader, in Unix seconds, a dot and the body. It is the base of
+// most timestamped webhook schemes.
+func HMACSHA256(timestampHeader string, signatureHeader string) Scheme {
+	return func(v *Verifier, header http.Header, body []byte) error {
+		timestamp := header.Get(timestampHeader)
+		signature := header.Get(signatureHeader)
+
+		if timestamp == "" || signature == "" {
+			return errors.WithStack(ErrMissingSignature)
+		}
+
+		err := v.checkTimestamp(timestamp)
+		if err != nil {
+			return err
+		}
+
+		return v.compareHex(signature, v.sign([]byte(timestamp), []byte("."), body))
+	}
+}
+
+// GitHub verifies the X-Hub-Signature-256 header of GitHub webhooks. GitHub callbacks carry no timestamp.
+func GitHub(v *Verifier, header http.Header, body []byte) error {
+	signature, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return errors.WithStack(ErrMissingSignature)
+	}
+
+	return v.compareHex(signature, v.sign(body))
+}
+
+// Stripe verifies the Stripe-Signature header of Stripe webhooks, accepting any of its v1 signatures
+// so that secrets can be rolled.
+func Stripe(v *Verifier, header http.Header, body []byte) error {
+	var (
+		timestamp  string
+		signatures []string
+	)
+
+	for item := range strings.SplitSeq(header.Get("Stripe-Signature"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.WithStack(ErrMissingSignature)
+	}
+
+	err := v.checkTimestamp(timestamp)
+	if err != nil {
+		return err
+	}
+
+	expected := v.sign([]byte(timestamp), []byte("."), body)
+	for _, signature := range signatures {
+		if v.compareHex(signature, expected) == nil {
+			return nil
+		}
+	}
+
+	return errors.WithStack(ErrInvalidSignature)
+}
+
+// Slack verifies the X-Slack-Signature and X-Slack-Request-Timestamp headers of Slack requests.
+func Slack(v *Verifier, header http.Header, body []byte) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+
+	signature, ok := strings.CutPrefix(header.Get("X-Slack-Signature"), "v0=")
+	if !ok || timestamp == "" {
+		return errors.WithStack(ErrMissingSignature)
+	}
+
+	err := v.checkTimestamp(timestamp)
+	if err != nil {
+		return err
+	}
+
+	return v.compareHex(signature, v.sign([]byte("v0:"), []byte(timestamp), []byte(":"), body))
+}
+
+// StandardWebhooks verifies the webhook-id, webhook-timestamp and webhook-signature headers of the
+// Standard Webhooks specification (https://www.standardwebhooks.com), used by Svix among others.
+// A secret with the "whsec_" prefix is base64 decoded first.
+func StandardWebhooks(v *Verifier, header http.Header, body []byte) error {
+	id := header.Get("Webhook-Id")
+	timestamp := header.Get("Webhook-Timestamp")
+	signatures := header.Get("Webhook-Signature")
+
+	if id == "" || timestamp == "" || signatures == "" {
+		return errors.WithStack(ErrMissingSignature)
+	}
+
+	err := v.checkTimestamp(timestamp)
+	if err != nil {
+		return err
+	}
+
+	secret := v.config.Secret
+	if encoded, ok := bytes.CutPrefix(secret, []byte("whsec_")); ok {
+		secret, err = base64.StdEncoding.DecodeString(string(encoded))
+		if err != nil {
+			return errors.Wrap(err, "webhook: decode secret")
+		}
+
+		if len(secret) == 0 {
+			return errors.WithStack(ErrEmptySecret)
+		}
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for signature := range strings.FieldsSeq(signatures) {
+		version, encoded, _ := strings.Cut(signature, ",")
+		if version != "v1" {
+			continue
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return errors.WithStack(ErrInvalidSignature)
+}
+
+// Verify reads the body of r, verifies it with scheme, and puts the body back so that it can be read again.
+func (v *Verifier) Verify(r *http.Request, scheme Scheme) error {
+	maxBodySize := v.config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if int64(len(body)) > maxBodySize {
+		return errors.Wrapf(ErrBodyTooLarge, "larger than %d bytes", maxBodySize)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return scheme(v, r.Header, body)
+}
+
+// Handler returns a handler calling next for callbacks verified with scheme, and answering 413 Content Too Large
+// to callbacks with too large bodies and 401 Unauthorized to the other ones.
+func (v *Verifier) Handler(scheme Scheme, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := v.Verify(r, scheme)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrBodyTooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+
+			http.Error(w, http.StatusText(status), status)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (v *Verifier) sign(parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, v.config.Secret)
+	for _, part := range parts {
+		mac.Write(part)
+	}
+
+	return mac.Sum(nil)
+}
+
+func (v *Verifier) compareHex(signature string, expected []byte) error {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, expected) {
+		return errors.WithStack(ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// checkTimestamp checks that timestamp, in Unix seconds, is within the tolerance of the current time.
+func (v *Verifier) checkTimestamp(timestamp string) error {
+	tolerance := v.config.Tolerance
+	if tolerance < 0 {
+		return nil
+	}
+
+	if tolerance == 0 {
+		tolerance = defaultTolerance
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.Wrap(ErrInvalidSignature, "malformed timestamp")
+	}
+
+	difference := v.now().Sub(time.Unix(seconds, 0))
+	if difference > tolerance || difference < -tolerance {
+		return errors.WithStack(ErrTimestampOutOfTolerance)
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	secret    = "whsec_test"
+	body      = `{"event":"paid"}`
+	timestamp = "1767225600" // 2026-01-01T00:00:00Z
+)
+
+func hexHMAC(key string, message string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(message))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newVerifier(t *testing.T, config Config, now time.Time) *Verifier {
+	t.Helper()
+
+	v, err := NewVerifier(config)
+	require.NoError(t, err)
+
+	v.now = func() time.Time { return now }
+
+	return v
+}
+
+func TestSchemes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1767225600, 0)
+	standardSecret := base64.StdEncoding.EncodeToString([]byte("standard secret"))
+	standardMAC := hmac.New(sha256.New, []byte("standard secret"))
+	standardMAC.Write([]byte("msg_1." + timestamp + "." + body))
+	standardSignature := base64.StdEncoding.EncodeToString(standardMAC.Sum(nil))
+
+	tests := []struct {
+		name    string
+		config  Config
+		scheme  Scheme
+		header  http.Header
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:   "success: HMAC-SHA256",
+			scheme: HMACSHA256("X-Timestamp", "X-Signature"),
+			header: http.Header{"X-Timestamp": {timestamp}, "X-Signature": {hexHMAC(secret, timestamp+"."+body)}},
+			now:    now.Add(4 * time.Minute),
+		},
+		{
+			name:    "failure: HMAC-SHA256 replayed",
+			scheme:  HMACSHA256("X-Timestamp", "X-Signature"),
+			header:  http.Header{"X-Timestamp": {timestamp}, "X-Signature": {hexHMAC(secret, timestamp+"."+body)}},
+			now:     now.Add(6 * time.Minute),
+			wantErr: ErrTimestampOutOfTolerance,
+		},
+		{
+			name:   "success: HMAC-SHA256 with the check disabled",
+			config: Config{Tolerance: -1},
+			scheme: HMACSHA256("X-Timestamp", "X-Signature"),
+			header: http.Header{"X-Timestamp": {timestamp}, "X-Signature": {hexHMAC(secret, timestamp+"."+body)}},
+			now:    now.Add(time.Hour),
+		},
+		{
+			name:    "failure: HMAC-SHA256 missing header",
+			scheme:  HMACSHA256("X-Timestamp", "X-Signature"),
+			header:  http.Header{"X-Timestamp": {timestamp}},
+			now:     now,
+			wantErr: ErrMissingSignature,
+		},
+		{
+			name:   "success: GitHub",
+			scheme: GitHub,
+			header: http.Header{"X-Hub-Signature-256": {"sha256=" + hexHMAC(secret, body)}},
+			now:    now.Add(time.Hour),
+		},
+		{
+			name:    "failure: GitHub tampered",
+			scheme:  GitHub,
+			header:  http.Header{"X-Hub-Signature-256": {"sha256=" + hexHMAC(secret, body+" ")}},
+			now:     now,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:   "success: Stripe with a rolled secret",
+			scheme: Stripe,
+			header: http.Header{"Stripe-Signature": {
+				"t=" + timestamp + ",v1=" + hexHMAC("old", timestamp+"."+body) + ",v1=" + hexHMAC(secret, timestamp+"."+body) + ",v0=x",
+			}},
+			now: now,
+		},
+		{
+			name:    "failure: Stripe wrong secret",
+			scheme:  Stripe,
+			header:  http.Header{"Stripe-Signature": {"t=" + timestamp + ",v1=" + hexHMAC("other", timestamp+"."+body)}},
+			now:     now,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:   "success: Slack",
+			scheme: Slack,
+			header: http.Header{
+				"X-Slack-Request-Timestamp": {timestamp},
+				"X-Slack-Signature":         {"v0=" + hexHMAC(secret, "v0:"+timestamp+":"+body)},
+			},
+			now: now.Add(-time.Minute),
+		},
+		{
+			name:    "failure: Slack malformed timestamp",
+			scheme:  Slack,
+			header:  http.Header{"X-Slack-Request-Timestamp": {"now"}, "X-Slack-Signature": {"v0=00"}},
+			now:     now,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:   "success: Standard Webhooks",
+			config: Config{Secret: []byte("whsec_" + standardSecret)},
+			scheme: StandardWebhooks,
+			header: http.Header{
+				"Webhook-Id":        {"msg_1"},
+				"Webhook-Timestamp": {timestamp},
+				"Webhook-Signature": {"v1a,xxx v1," + standardSignature},
+			},
+			now: now,
+		},
+		{
+			name:    "failure: Standard Webhooks other ID",
+			config:  Config{Secret: []byte("whsec_" + standardSecret)},
+			scheme:  StandardWebhooks,
+			header:  http.Header{"Webhook-Id": {"msg_2"}, "Webhook-Timestamp": {timestamp}, "Webhook-Signature": {"v1," + standardSignature}},
+			now:     now,
+			wantErr: ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := tt.config
+			if config.Secret == nil {
+				config.Secret = []byte(secret)
+			}
+
+			err := tt.scheme(newVerifier(t, config, tt.now), tt.header, []byte(body))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestVerifier_Handler(t *testing.T) {
+	t.Parallel()
+
+	v := newVerifier(t, Config{Secret: []byte(secret), MaxBodySize: 64}, time.Now())
+	handler := v.Handler(GitHub, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(got)
+	}))
+
+	tests := []struct {
+		name       string
+		body       string
+		signature  string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "success: verified",
+			body:       body,
+			signature:  "sha256=" + hexHMAC(secret, body),
+			wantStatus: http.StatusOK,
+			wantBody:   body,
+		},
+		{
+			name:       "failure: invalid signature",
+			body:       body,
+			signature:  "sha256=00",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "failure: body too large",
+			body:       strings.Repeat("x", 65),
+			signature:  "sha256=" + hexHMAC(secret, strings.Repeat("x", 65)),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(tt.body))
+			request.Header.Set("X-Hub-Signature-256", tt.signature)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewVerifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		wantErr error
+	}{
+		{
+			name:   "success: secret",
+			secret: []byte(secret),
+		},
+		{
+			name:    "failure: nil secret",
+			secret:  nil,
+			wantErr: ErrEmptySecret,
+		},
+		{
+			name:    "failure: empty secret",
+			secret:  []byte{},
+			wantErr: ErrEmptySecret,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := NewVerifier(Config{Secret: tt.secret})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, v)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, v)
+		})
+	}
+}
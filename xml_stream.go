@@ -0,0 +1,47 @@
+package webapiclient
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// EachXMLElement decodes every element of an XML response whose local name is localName into a T
+// and calls fn with it in document order, reading the body as a stream so that feeds larger than
+// memory can be processed. Matching elements nested in a matching element are decoded as part of it.
+// Charsets other than UTF-8 are decoded as with Response.DecodeXML.
+func EachXMLElement[T any](response *Response, localName string, fn func(element *T) error) error {
+	decoder, err := newXMLDecoder(response.Body, contentTypeCharset(response.Headers))
+	if err != nil {
+		return errors.WithStack(newError(CategoryDecode, err))
+	}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.WithStack(newError(CategoryDecode, err))
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != localName {
+			continue
+		}
+
+		var element T
+
+		err = decoder.DecodeElement(&element, &start)
+		if err != nil {
+			return errors.WithStack(newError(CategoryDecode, err))
+		}
+
+		err = fn(&element)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
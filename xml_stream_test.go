@@ -0,0 +1,90 @@
+package webapiclient
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEachXMLElement(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		ID    string `xml:"id,attr"`
+		Title string `xml:"title"`
+	}
+
+	feed := `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>feed</title>
+  <item id="1"><title>first</title></item>
+  <meta><item id="2"><title>second</title></item></meta>
+  <item id="3"><title>third</title></item>
+</feed>`
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []item
+		wantErr bool
+	}{
+		{
+			name: "success: matching elements at any depth",
+			body: feed,
+			want: []item{{ID: "1", Title: "first"}, {ID: "2", Title: "second"}, {ID: "3", Title: "third"}},
+		},
+		{
+			name: "success: no matching elements",
+			body: `<feed><entry/></feed>`,
+			want: nil,
+		},
+		{
+			name:    "failure: malformed XML",
+			body:    `<feed><item id="1"><title>first</item></feed>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response := &Response{Body: io.NopCloser(strings.NewReader(tt.body))}
+
+			var got []item
+			err := EachXMLElement(response, "item", func(element *item) error {
+				got = append(got, *element)
+
+				return nil
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, CategoryDecode, ClassifyError(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("failure: callback error stops decoding", func(t *testing.T) {
+		t.Parallel()
+
+		errStop := errors.New("stop")
+		response := &Response{Body: io.NopCloser(strings.NewReader(feed))}
+
+		calls := 0
+		err := EachXMLElement(response, "item", func(_ *item) error {
+			calls++
+
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+	})
+}